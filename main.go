@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"log"
@@ -9,6 +10,7 @@ import (
 	"math/rand"
 	"net/http"
 	"os"
+	"reflect"
 	"runtime"
 	"sort"
 	"strconv"
@@ -25,6 +27,7 @@ const (
 	// Match statuses
 	StatusLive      = "LIVE"
 	StatusHalftime  = "HALFTIME"
+	StatusVARCheck  = "VAR_CHECK" // Play stopped while VAR reviews a decision
 	StatusFinished  = "FINISHED"
 	StatusPostponed = "POSTPONED"
 	StatusCooldown  = "COOLDOWN"
@@ -57,17 +60,43 @@ const (
 	NatItaly   = "Italy"
 
 	// Event types
-	EventGoal         = "GOAL"
-	EventCard         = "CARD"
-	EventSubstitution = "SUBSTITUTION"
-	EventCommentary   = "COMMENTARY"
-	EventKickoff      = "KICKOFF"
-	EventFoul         = "FOUL"
-	EventCorner       = "CORNER"
-	EventOffside      = "OFFSIDE"
-	EventThrowIn      = "THROW_IN"
-	EventPenalty      = "PENALTY"
-	EventFreekick     = "FREEKICK"
+	EventGoal           = "GOAL"
+	EventCard           = "CARD"
+	EventSubstitution   = "SUBSTITUTION"
+	EventCommentary     = "COMMENTARY"
+	EventKickoff        = "KICKOFF"
+	EventFoul           = "FOUL"
+	EventCorner         = "CORNER"
+	EventOffside        = "OFFSIDE"
+	EventThrowIn        = "THROW_IN"
+	EventPenalty        = "PENALTY"
+	EventFreekick       = "FREEKICK"
+	EventHalftime       = "HALFTIME"
+	EventFulltime       = "FULL_TIME"
+	EventTacticalChange = "TACTICAL_CHANGE"
+	EventWeather        = "WEATHER"
+	EventVARCheck       = "VAR_CHECK"
+	EventVARDecision    = "VAR_DECISION"
+
+	// VAR reviews
+	VARDecisionGoal    = "GOAL"
+	VARDecisionPenalty = "PENALTY"
+	VARDecisionRedCard = "RED_CARD"
+	VARPending         = "PENDING"
+	VARStands          = "STANDS"
+	VAROverturned      = "OVERTURNED"
+
+	// Odds markets
+	MarketMatchResult      = "MATCH_RESULT"
+	MarketOverUnder        = "OVER_UNDER_2_5"
+	MarketBothTeamsToScore = "BOTH_TEAMS_TO_SCORE"
+	MarketCorrectScore     = "CORRECT_SCORE"
+	MarketNextScorer       = "NEXT_SCORER"
+
+	// Odds market statuses
+	OddsOpen      = "OPEN"
+	OddsSuspended = "SUSPENDED"
+	OddsClosed    = "CLOSED"
 
 	// Ball event states
 	BallEventPlay     = "PLAY"
@@ -92,6 +121,7 @@ const (
 	WeatherOvercast     = "Overcast"
 	WeatherSunny        = "Sunny"
 	WeatherPartlyCloudy = "Partly Cloudy"
+	WeatherHeavyRain    = "Heavy Rain"
 
 	// Offensive tactics
 	TacticTikiTaka      = "TIKI_TAKA"
@@ -118,17 +148,161 @@ const (
 	PostMatchBreakSeconds  = 60 // New: 1 minute break after match
 	CooldownSeconds        = 15
 	SeasonMatches          = 38
-	MaxSeasonHistory       = 10
+	MaxSeasonHistory       = 10 // Development and finance seasons kept per player or club
 	FieldWidth             = 100.0
 	FieldHeight            = 64.0
 	MaxSimultaneousMatches = 4    // Maximum number of matches that can run at once PER LEAGUE
 	MaxNewsEntries         = 100  // Maximum news entries to keep
 	MaxLogEntries          = 1000 // Maximum log entries to keep
+	DefaultPageLimit       = 10   // List endpoint page size
+	MaxPageLimit           = 50
+	DefaultCommentaryCap   = 1000 // Commentary entries kept per match
+	DefaultCommentaryLimit = 50
+	MaxCommentaryLimit     = 100
+	DefaultHeatmapColumns  = 10 // 10x8 grid of 10x8 pitch units
+	DefaultHeatmapRows     = 8
+	MaxHeatmapCells        = 50
+	DefaultTrailMinutes    = 10
+	RetirementAge          = 33 // Players may retire from this age
+	MaxPlayerAge           = 37 // Players always retire at this age
+
+	// Odds configuration
+	DefaultBookmakerMargin       = 0.05 // 5% overround applied to fair probabilities
+	OddsGoalSuspensionSeconds    = 6    // Markets suspended after a goal
+	OddsPenaltySuspensionSeconds = 8    // Markets suspended while a penalty is taken
+	MaxOddsHistory               = 300  // Maximum odds snapshots kept per match
+
+	// Manager AI
+	ManagerFirstChangeMinute = 20   // No tactical changes before this minute (red cards aside)
+	ManagerChangeCooldown    = 12   // Minutes between a manager's changes
+	ManagerReactionChance    = 0.35 // Chance per tick that a manager acts on the situation
+	MaxSubstitutions         = 3    // Substitutions allowed per team
+	SubstitutionFirstMinute  = 55   // No substitutions before this minute
+	SubstitutionChance       = 0.2  // Chance per tick that a manager makes a change
+	SubstitutionStamina      = 65.0 // Players below this are replaced first; after 75' anyone can be
+
+	// Weather and fatigue
+	WeatherChangeChance   = 0.03 // Chance per tick of a change in the weather or wind
+	HeatStressTemperature = 25   // °C above which players tire faster
+	MaxWindSpeed          = 50.0 // km/h
+	StaminaPerUnit        = 0.2  // Stamina spent per pitch unit covered
+	MinStamina            = 20.0
+
+	// VAR
+	VARGoalReviewChance    = 0.3 // Share of goals checked by VAR
+	VARPenaltyReviewChance = 0.5
+	VARRedCardReviewChance = 0.5
+	VARMinReviewSeconds    = 4
+	VARMaxReviewSeconds    = 8
+
+	// Crowds and home advantage
+	BaseHomeAdvantage    = 1.04 // Home strength multiplier in an empty stadium
+	CrowdHomeAdvantage   = 0.12 // Extra home multiplier at full crowd factor
+	CrowdMomentumPull    = 0.02 // Share of the gap to the crowd's momentum level closed per tick
+	CrowdCardBias        = 0.15 // Card chances shift by up to this share in the home side's favour
+	DerbyAttendanceBoost = 0.2
+
+	// Team ratings
+	RatingFloor        = 70.0 // Line rating mapped to the weakest strength (0.4)
+	RatingCeiling      = 85.0 // Line rating mapped to the strongest strength (0.7)
+	MissingLinePenalty = 0.3  // Share of a missing player's line weight lost from the rating
+
+	// Elo ratings
+	EloStartPremier      = 1550.0
+	EloStartCommunity    = 1450.0
+	EloKFactor           = 20.0
+	EloHomeAdvantage     = 60.0 // Rating points added to the home side's expectation
+	EloProbabilityWeight = 0.5  // Share of the win expectation taken from Elo rather than lineup strength
+	MaxDrawProbability   = 0.3  // Draw chance between evenly matched sides, falling to 0 for a certain winner
+	MaxRatingHistory     = 1000 // Rating changes kept per team
+
+	// Season awards
+	SeasonAwardLeaders  = 3 // Golden boot and assist leaders kept per league
+	YoungPlayerMaxAge   = 21
+	MinAwardAppearances = MatchesPerTeam / 3 // Matches needed for rating-based awards
+
+	// All-time records
+	MaxRecordEntries     = 10 // Entries kept per record list
+	MinRecordStreak      = 3  // Shortest run that counts as a streak
+	RecordNewsMinMatches = 20 // Records set before this many matches are not announced
+
+	// Fantasy league rules
+	FantasySquadSize     = 11
+	FantasyBudget        = 300 // Squad budget in millions, spent on Player.MarketValue
+	FantasyMaxPerClub    = 3
+	FantasyFreeTransfers = 1 // Free transfers per gameweek
+	FantasyTransferCost  = 4 // Points deducted per extra transfer
+	MaxFantasyTeams      = 10000
+
+	// Prediction game scoring
+	PredictionExactPoints  = 3 // Exact final score
+	PredictionResultPoints = 1 // Correct result only
+	MaxPredictionScore     = 20
+
+	// Prediction statuses
+	PredictionOpen   = "OPEN"
+	PredictionLocked = "LOCKED"
+	PredictionScored = "SCORED"
+
+	// Transfer window rules
+	MinSquadSize            = 17  // Clubs will not sell below this
+	MaxSquadSize            = 22  // Clubs will not buy above this
+	MaxSigningsPerClub      = 2   // Signings per club per window
+	MaxNegotiationRounds    = 3   // Bid and counter-offer rounds
+	MinTransferUpgrade      = 5   // Overall improvement a target must offer
+	TransferBudgetPremier   = 120 // Base window budget in millions
+	TransferBudgetCommunity = 50
+	MajorTransferFee        = 50 // Fees from this amount make the news
+	MaxTransferHistory      = 500
+
+	// Transfer statuses
+	TransferCompleted = "completed"
+	TransferRejected  = "rejected"
+
+	// Log levels
+	LevelDebug = "DEBUG"
+	LevelInfo  = "INFO"
+	LevelWarn  = "WARN"
+	LevelError = "ERROR"
+
+	// News types
+	NewsHalftime     = "halftime"
+	NewsFulltime     = "fulltime"
+	NewsRedCard      = "red_card"
+	NewsHatTrick     = "hat_trick"
+	NewsLeaderChange = "table_leader"
+	NewsSeasonEnd    = "season_end"
+	NewsTransfer     = "transfer"
+	NewsRecord       = "record"
+
+	// Club finances, amounts in millions
+	StartingBalancePremier   = 150.0
+	StartingBalanceCommunity = 60.0
+	TicketPricePremier       = 55.0 // Average ticket price in pounds
+	TicketPriceCommunity     = 30.0
+	BroadcastFeePremier      = 4.5 // Paid to both clubs per match
+	BroadcastFeeCommunity    = 1.5
+	PrizePoolPremier         = 400.0 // Shared by final league position
+	PrizePoolCommunity       = 120.0
+	WageRatioPremier         = 0.22 // Annual wages as a share of squad market value
+	WageRatioCommunity       = 0.08
+	MaxFinanceLedger         = 500 // Ledger entries kept per club
+
+	// Finance transaction types
+	FinanceMatchday    = "matchday"
+	FinanceBroadcast   = "broadcast"
+	FinancePrizeMoney  = "prize_money"
+	FinanceWages       = "wages"
+	FinanceTransferIn  = "transfer_in"
+	FinanceTransferOut = "transfer_out"
 )
 
 var (
 	formations        = []string{Formation442, Formation433, Formation352, Formation4231, Formation532}
-	weatherConditions = []string{WeatherClear, WeatherCloudy, WeatherLightRain, WeatherOvercast, WeatherSunny, WeatherPartlyCloudy}
+	weatherConditions = []string{WeatherClear, WeatherCloudy, WeatherLightRain, WeatherOvercast, WeatherSunny, WeatherPartlyCloudy, WeatherHeavyRain}
+
+	// Weather changes during a match move one step along this dry-to-wet scale
+	weatherProgression = []string{WeatherSunny, WeatherClear, WeatherPartlyCloudy, WeatherCloudy, WeatherOvercast, WeatherLightRain, WeatherHeavyRain}
 
 	// Player availability statuses
 	PlayerAvailable   = "available"
@@ -172,41 +346,93 @@ type DynamicMatchProbabilities struct {
 	Factors             map[string]float64 `json:"factors"` // What's influencing the probabilities
 }
 
+// Odds price in the three common display formats
+type OddsPrice struct {
+	Decimal    float64 `json:"decimal"`
+	Fractional string  `json:"fractional"`
+	American   string  `json:"american"`
+}
+
+type OddsSelection struct {
+	Name            string    `json:"name"`
+	PlayerID        int       `json:"player_id,omitempty"`
+	Probability     float64   `json:"probability"` // Fair probability before margin
+	Odds            OddsPrice `json:"odds"`
+	PreviousDecimal float64   `json:"previous_decimal"`
+	Movement        string    `json:"movement"` // UP, DOWN, STEADY
+}
+
+type OddsMarket struct {
+	Type       string           `json:"type"`
+	Name       string           `json:"name"`
+	Status     string           `json:"status"` // OPEN, SUSPENDED, CLOSED
+	Selections []*OddsSelection `json:"selections"`
+}
+
+type MatchOdds struct {
+	MatchID          int           `json:"match_id"`
+	Margin           float64       `json:"margin"`
+	Status           string        `json:"status"`
+	SuspensionReason string        `json:"suspension_reason,omitempty"`
+	SuspendedUntil   *time.Time    `json:"suspended_until,omitempty"`
+	Markets          []*OddsMarket `json:"markets"`
+	LastUpdate       time.Time     `json:"last_update"`
+}
+
+// Point-in-time copy of the headline prices for the odds timeline
+type OddsSnapshot struct {
+	Minute    int                `json:"minute"`
+	Status    string             `json:"status"`
+	Prices    map[string]float64 `json:"prices"` // Decimal prices keyed by market.selection
+	Timestamp time.Time          `json:"timestamp"`
+}
+
 // New structures for enhanced features
 type NewsEntry struct {
 	ID        int       `json:"id"`
 	Title     string    `json:"title"`
 	Content   string    `json:"content"`
 	MatchID   int       `json:"match_id,omitempty"`
-	Type      string    `json:"type"` // "halftime", "fulltime", "general"
+	TeamIDs   []int     `json:"team_ids,omitempty"`
+	Type      string    `json:"type"` // "halftime", "fulltime", "red_card", "hat_trick", "table_leader", "season_end", "transfer", "record"
 	Timestamp time.Time `json:"timestamp"`
 	Generated bool      `json:"generated"` // Whether this was AI generated
 }
 
 type LogEntry struct {
 	ID        int       `json:"id"`
-	Level     string    `json:"level"`
+	Level     string    `json:"level"` // DEBUG, INFO, WARN or ERROR
 	Message   string    `json:"message"`
+	MatchID   int       `json:"match_id,omitempty"`
+	League    string    `json:"league,omitempty"`
+	Component string    `json:"component,omitempty"`
 	Timestamp time.Time `json:"timestamp"`
 }
 
 // Enhanced data structures maintaining backward compatibility
 type Match struct {
-	ID            int       `json:"id"`
-	HomeTeam      TeamInfo  `json:"home_team"`
-	AwayTeam      TeamInfo  `json:"away_team"`
-	HomeScore     int       `json:"home_score"`
-	AwayScore     int       `json:"away_score"`
-	Minute        int       `json:"minute"`
-	Status        string    `json:"status"`
-	Competition   string    `json:"competition"`
-	LastUpdate    time.Time `json:"last_update"`
-	Venue         string    `json:"venue"`
-	Attendance    int       `json:"attendance"`
-	Weather       string    `json:"weather"`
-	Temperature   int       `json:"temperature"`
-	HomeFormation string    `json:"home_formation"`
-	AwayFormation string    `json:"away_formation"`
+	ID            int         `json:"id"`
+	HomeTeam      TeamInfo    `json:"home_team"`
+	AwayTeam      TeamInfo    `json:"away_team"`
+	HomeScore     int         `json:"home_score"`
+	AwayScore     int         `json:"away_score"`
+	Minute        int         `json:"minute"`
+	Status        string      `json:"status"`
+	Competition   string      `json:"competition"`
+	LastUpdate    time.Time   `json:"last_update"`
+	Venue         string      `json:"venue"`
+	Attendance    int         `json:"attendance"`
+	Capacity      int         `json:"capacity"`
+	Occupancy     float64     `json:"occupancy"`    // Percentage of capacity filled
+	CrowdFactor   float64     `json:"crowd_factor"` // 0.0 (empty) to 1.0 (full, large, hostile)
+	Derby         string      `json:"derby,omitempty"`
+	Weather       string      `json:"weather"`
+	Temperature   int         `json:"temperature"`
+	WindSpeed     int         `json:"wind_speed"` // km/h
+	Referee       RefereeInfo `json:"referee"`
+	VARReview     *VARReview  `json:"var_review,omitempty"` // Pending review while status is VAR_CHECK
+	HomeFormation string      `json:"home_formation"`
+	AwayFormation string      `json:"away_formation"`
 	// New fields for extended simulation
 	Season        int             `json:"season"`
 	MatchweekNum  int             `json:"matchweek"`
@@ -230,6 +456,184 @@ type TeamInfo struct {
 	League     string   `json:"league"`
 	Form       []string `json:"form"`        // Last 5 results (W/D/L)
 	FormPoints int      `json:"form_points"` // Points from last 5 matches
+	SquadSize  int      `json:"squad_size"`
+	SquadValue int      `json:"squad_value"` // Sum of player market values in millions
+	// Stadium and home crowds this season
+	Capacity          int     `json:"capacity"`
+	AverageAttendance int     `json:"average_attendance"`
+	AverageOccupancy  float64 `json:"average_occupancy"` // Percentage of capacity filled
+	homeGates         int
+	totalAttendance   int
+}
+
+// TeamRatings rates a side's lines (0-100) from the characteristics of its XI
+type TeamRatings struct {
+	TeamID         int                 `json:"team_id"`
+	TeamName       string              `json:"team_name"`
+	MatchID        int                 `json:"match_id,omitempty"` // Set when rated from a match lineup
+	Source         string              `json:"source"`             // "match" or "squad" (strongest XI)
+	Formation      string              `json:"formation"`
+	Attack         float64             `json:"attack"`
+	Midfield       float64             `json:"midfield"`
+	Defence        float64             `json:"defence"`
+	Overall        float64             `json:"overall"`
+	Strength       float64             `json:"strength"`        // 0.4-0.7, used for win probabilities
+	AttackStrength float64             `json:"attack_strength"` // 0.4-0.7, used for goal chances
+	RedCards       int                 `json:"red_cards"`
+	Injuries       int                 `json:"injuries"`
+	AverageStamina float64             `json:"average_stamina"`
+	Players        []*PlayerLineRating `json:"players"`
+}
+
+type PlayerLineRating struct {
+	PlayerID  int     `json:"player_id"`
+	Name      string  `json:"name"`
+	Position  string  `json:"position"`
+	Overall   int     `json:"overall"`
+	Stamina   float64 `json:"stamina"`
+	Available bool    `json:"available"`
+	Status    string  `json:"status"`
+}
+
+// TeamSeasonStats aggregates a team's finished matches in the current season
+type TeamSeasonStats struct {
+	TeamID             int             `json:"team_id"`
+	TeamName           string          `json:"team_name"`
+	League             string          `json:"league"`
+	Played             int             `json:"played"`
+	HomePlayed         int             `json:"home_played"`
+	AwayPlayed         int             `json:"away_played"`
+	Points             int             `json:"points"`
+	PointsPerGame      float64         `json:"points_per_game"`
+	GoalsFor           int             `json:"goals_for"`
+	GoalsAgainst       int             `json:"goals_against"`
+	HomeGoalsFor       int             `json:"home_goals_for"`
+	HomeGoalsAgainst   int             `json:"home_goals_against"`
+	AwayGoalsFor       int             `json:"away_goals_for"`
+	AwayGoalsAgainst   int             `json:"away_goals_against"`
+	CleanSheets        int             `json:"clean_sheets"`
+	FailedToScore      int             `json:"failed_to_score"`
+	AveragePossession  float64         `json:"average_possession"`
+	Shots              int             `json:"shots"`
+	ShotsOnTarget      int             `json:"shots_on_target"`
+	ShotsPerGame       float64         `json:"shots_per_game"`
+	ShotsConceded      int             `json:"shots_conceded"`
+	ShotAccuracy       float64         `json:"shot_accuracy"` // Percentage of shots on target
+	Corners            int             `json:"corners"`
+	CornersPerGame     float64         `json:"corners_per_game"`
+	Fouls              int             `json:"fouls"`
+	YellowCards        int             `json:"yellow_cards"`
+	RedCards           int             `json:"red_cards"`
+	CardsPerGame       float64         `json:"cards_per_game"`
+	GoalsByInterval    []GoalInterval  `json:"goals_by_interval"`
+	PointsPerGameTrend []PointsPerGame `json:"points_per_game_trend"`
+}
+
+// GoalInterval counts goals in a 15-minute window; goals after the 90th minute count in 76-90
+type GoalInterval struct {
+	Interval string `json:"interval"`
+	Scored   int    `json:"scored"`
+	Conceded int    `json:"conceded"`
+}
+
+// PointsPerGame is the running points-per-game after a match
+type PointsPerGame struct {
+	MatchID       int     `json:"match_id"`
+	Matchweek     int     `json:"matchweek"`
+	Opponent      string  `json:"opponent"`
+	Home          bool    `json:"home"`
+	Score         string  `json:"score"`
+	Result        string  `json:"result"`
+	Points        int     `json:"points"`
+	PointsPerGame float64 `json:"points_per_game"`
+}
+
+// EloRating is a team's long-running strength rating, carried across seasons
+type EloRating struct {
+	TeamID     int     `json:"team_id"`
+	Rating     float64 `json:"rating"`
+	Initial    float64 `json:"initial"`
+	Peak       float64 `json:"peak"`
+	Lowest     float64 `json:"lowest"`
+	Played     int     `json:"played"`
+	LastChange float64 `json:"last_change"`
+}
+
+// RatingChange is one match's Elo update for a team
+type RatingChange struct {
+	MatchID      int       `json:"match_id"`
+	Season       int       `json:"season"`
+	Matchweek    int       `json:"matchweek"`
+	OpponentID   int       `json:"opponent_id"`
+	OpponentName string    `json:"opponent_name"`
+	Home         bool      `json:"home"`
+	Result       string    `json:"result"` // W, D or L
+	Score        string    `json:"score"`
+	Expected     float64   `json:"expected"` // Expected score before the match (0-1)
+	Before       float64   `json:"rating_before"`
+	After        float64   `json:"rating_after"`
+	Change       float64   `json:"change"`
+	Timestamp    time.Time `json:"timestamp"`
+}
+
+type ClubFinances struct {
+	TeamID  int                   `json:"team_id"`
+	Balance float64               `json:"balance"`
+	Ledger  []*FinanceTransaction `json:"ledger"`
+	Seasons []*SeasonFinance      `json:"seasons"`
+}
+
+type FinanceTransaction struct {
+	ID          int       `json:"id"`
+	Season      int       `json:"season"`
+	Matchweek   int       `json:"matchweek"`
+	Type        string    `json:"type"`
+	Description string    `json:"description"`
+	Amount      float64   `json:"amount"`  // Positive income, negative expense
+	Balance     float64   `json:"balance"` // Balance after this transaction
+	MatchID     int       `json:"match_id,omitempty"`
+	Timestamp   time.Time `json:"timestamp"`
+}
+
+type SeasonFinance struct {
+	Season           int                `json:"season"`
+	OpeningBalance   float64            `json:"opening_balance"`
+	ClosingBalance   float64            `json:"closing_balance"`
+	Revenue          float64            `json:"revenue"`
+	Expenses         float64            `json:"expenses"`
+	Profit           float64            `json:"profit"`
+	MatchdayRevenue  float64            `json:"matchday_revenue"`
+	BroadcastRevenue float64            `json:"broadcast_revenue"`
+	PrizeMoney       float64            `json:"prize_money"`
+	Wages            float64            `json:"wages"`
+	TransferSpend    float64            `json:"transfer_spend"`
+	TransferIncome   float64            `json:"transfer_income"`
+	Matchweeks       []MatchweekFinance `json:"matchweeks"`
+}
+
+type MatchweekFinance struct {
+	Matchweek int     `json:"matchweek"`
+	Revenue   float64 `json:"revenue"`
+	Expenses  float64 `json:"expenses"`
+	Balance   float64 `json:"balance"`
+}
+
+type Transfer struct {
+	ID          int       `json:"id"`
+	Season      int       `json:"season"` // Season the player joins for
+	PlayerID    int       `json:"player_id"`
+	PlayerName  string    `json:"player_name"`
+	Position    string    `json:"position"`
+	FromTeamID  int       `json:"from_team_id"`
+	FromTeam    string    `json:"from_team"`
+	ToTeamID    int       `json:"to_team_id"`
+	ToTeam      string    `json:"to_team"`
+	Fee         int       `json:"fee"` // Agreed fee, or the final offer when rejected
+	MarketValue int       `json:"market_value"`
+	Status      string    `json:"status"`
+	Rounds      int       `json:"rounds"`
+	Reason      string    `json:"reason"`
+	Timestamp   time.Time `json:"timestamp"`
 }
 
 type Player struct {
@@ -251,6 +655,16 @@ type Player struct {
 	Characteristics PlayerCharacteristics `json:"characteristics"`
 	SeasonStats     PlayerSeasonStats     `json:"season_stats"`
 	CurrentRating   float64               `json:"current_rating"`
+	Development     []PlayerDevelopment   `json:"development,omitempty"`    // Season-end progression
+	RetiredSeason   int                   `json:"retired_season,omitempty"` // Set once the player retires
+}
+
+type PlayerDevelopment struct {
+	Season      int `json:"season"`
+	Age         int `json:"age"`
+	Overall     int `json:"overall"`
+	Change      int `json:"change"`
+	MarketValue int `json:"market_value"`
 }
 
 type PlayerCharacteristics struct {
@@ -291,11 +705,21 @@ type BallPosition struct {
 	Timestamp    time.Time `json:"timestamp"`
 	EventType    string    `json:"event_type"`    // Current ball event (PLAY, FREEKICK, CORNER, etc.)
 	EventStarted time.Time `json:"event_started"` // When current event started
+
+	passerID      int // Player whose pass is in flight
+	passTargetID  int // Intended receiver of that pass
+	touchPlayerID int // Last possessor credited with a touch
 }
 
 var (
-	ballPositions = make(map[int]*BallPosition) // matchID -> ball position
-	matchTactics  = make(map[int]*MatchTactics) // matchID -> tactics
+	ballPositions  = make(map[int]*BallPosition)     // matchID -> ball position
+	matchTactics   = make(map[int]*MatchTactics)     // matchID -> tactics
+	tacticsHistory = make(map[int][]*TacticalChange) // matchID -> manager decisions, oldest first
+	tacticsCounter = 0
+
+	referees         = make(map[int]*Referee)
+	matchVARReviews  = make(map[int][]*VARReview) // matchID -> reviews, oldest first
+	varReviewCounter = 0
 )
 
 type MatchTactics struct {
@@ -303,6 +727,66 @@ type MatchTactics struct {
 	HomeDefensive string `json:"home_defensive"`
 	AwayOffensive string `json:"away_offensive"`
 	AwayDefensive string `json:"away_defensive"`
+
+	lastChange   map[int]int // teamID -> minute of the manager's last change
+	redCardsSeen map[int]int // teamID -> red cards the manager has already reacted to
+}
+
+// Referee officiates matches; strictness drives foul and card rates, consistency how predictable decisions are
+type Referee struct {
+	ID               int    `json:"id"`
+	Name             string `json:"name"`
+	Nationality      string `json:"nationality"`
+	Strictness       int    `json:"strictness"`  // 0-100
+	Consistency      int    `json:"consistency"` // 0-100
+	Matches          int    `json:"matches"`
+	YellowCards      int    `json:"yellow_cards"`
+	RedCards         int    `json:"red_cards"`
+	PenaltiesAwarded int    `json:"penalties_awarded"`
+	VARReviews       int    `json:"var_reviews"`
+	Overturned       int    `json:"overturned"`
+}
+
+// RefereeInfo is the referee summary carried on a match
+type RefereeInfo struct {
+	ID          int    `json:"id"`
+	Name        string `json:"name"`
+	Strictness  int    `json:"strictness"`
+	Consistency int    `json:"consistency"`
+}
+
+// VARReview is a check of a goal, penalty or red card that pauses the match
+type VARReview struct {
+	ID         int        `json:"id"`
+	MatchID    int        `json:"match_id"`
+	Minute     int        `json:"minute"`
+	Decision   string     `json:"decision"` // GOAL, PENALTY, RED_CARD
+	TeamID     int        `json:"team_id"`  // Side the original decision favoured (red cards: the player's side)
+	PlayerID   int        `json:"player_id,omitempty"`
+	PlayerName string     `json:"player_name,omitempty"`
+	Reason     string     `json:"reason"`
+	Outcome    string     `json:"outcome"`  // PENDING, STANDS, OVERTURNED
+	EventID    int        `json:"event_id"` // Timeline event under review
+	StartedAt  time.Time  `json:"started_at"`
+	ResolvesAt time.Time  `json:"resolves_at"`
+	ResolvedAt *time.Time `json:"resolved_at,omitempty"`
+}
+
+// TacticalChange is one manager decision, including the kickoff setup
+type TacticalChange struct {
+	ID                int       `json:"id"`
+	MatchID           int       `json:"match_id"`
+	Minute            int       `json:"minute"`
+	TeamID            int       `json:"team_id"`
+	TeamName          string    `json:"team_name"`
+	Reason            string    `json:"reason"`
+	Formation         string    `json:"formation"`
+	Offensive         string    `json:"offensive"`
+	Defensive         string    `json:"defensive"`
+	PreviousFormation string    `json:"previous_formation,omitempty"`
+	PreviousOffensive string    `json:"previous_offensive,omitempty"`
+	PreviousDefensive string    `json:"previous_defensive,omitempty"`
+	Timestamp         time.Time `json:"timestamp"`
 }
 
 type FormationPosition struct {
@@ -360,6 +844,99 @@ type LiveCommentary struct {
 	AudioSpeed float64   `json:"audio_speed,omitempty"`
 }
 
+// TimelineEvent is a structured match event; IDs are unique across matches and never reused
+type TimelineEvent struct {
+	ID         int            `json:"id"`
+	MatchID    int            `json:"match_id"`
+	Type       string         `json:"type"`
+	Minute     int            `json:"minute"`
+	TeamID     int            `json:"team_id,omitempty"`
+	PlayerID   int            `json:"player_id,omitempty"`
+	PlayerName string         `json:"player_name,omitempty"`
+	AssistID   int            `json:"assist_player_id,omitempty"`
+	AssistName string         `json:"assist_player_name,omitempty"`
+	Card       string         `json:"card,omitempty"` // yellow or red
+	Reason     string         `json:"reason,omitempty"`
+	Detail     string         `json:"detail,omitempty"`
+	RelatedID  int            `json:"related_id,omitempty"` // Event a VAR check refers to
+	Overturned bool           `json:"overturned,omitempty"` // Set when VAR cancels this decision
+	Position   *PitchPosition `json:"position,omitempty"`
+	HomeScore  int            `json:"home_score"`
+	AwayScore  int            `json:"away_score"`
+	Timestamp  time.Time      `json:"timestamp"`
+
+	// Substitutions: player_id comes on for player_off_id
+	PlayerOffID   int    `json:"player_off_id,omitempty"`
+	PlayerOffName string `json:"player_off_name,omitempty"`
+
+	newsID int // Article this event triggered, retracted if VAR overturns the event
+}
+
+// MatchPlayerStats is one player's line for a single match, updated every tick
+type MatchPlayerStats struct {
+	MatchID         int       `json:"match_id"`
+	PlayerID        int       `json:"player_id"`
+	PlayerName      string    `json:"player_name"`
+	TeamID          int       `json:"team_id"`
+	Position        string    `json:"position"`
+	Minutes         int       `json:"minutes"`
+	Touches         int       `json:"touches"`
+	Passes          int       `json:"passes"`
+	PassesCompleted int       `json:"passes_completed"`
+	PassAccuracy    float64   `json:"pass_accuracy"` // Percentage
+	Shots           int       `json:"shots"`
+	ShotsOnTarget   int       `json:"shots_on_target"`
+	Goals           int       `json:"goals"`
+	Assists         int       `json:"assists"`
+	Fouls           int       `json:"fouls"`
+	YellowCards     int       `json:"yellow_cards"`
+	RedCards        int       `json:"red_cards"`
+	Distance        float64   `json:"distance"` // Pitch units moved while tracked
+	Stamina         float64   `json:"stamina"`  // 100 at kickoff, drained by running and heat
+	Rating          float64   `json:"rating"`
+	SentOffMinute   int       `json:"sent_off_minute,omitempty"`
+	SubbedOnMinute  int       `json:"subbed_on_minute,omitempty"`
+	SubbedOffMinute int       `json:"subbed_off_minute,omitempty"`
+	LastUpdate      time.Time `json:"last_update"`
+}
+
+type PitchPosition struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+}
+
+type AveragePosition struct {
+	PlayerID   int     `json:"player_id"`
+	PlayerName string  `json:"player_name"`
+	TeamID     int     `json:"team_id"`
+	Position   string  `json:"position"`
+	X          float64 `json:"x"`
+	Y          float64 `json:"y"`
+	Samples    int     `json:"samples"`
+}
+
+// PassEdge counts passes from one player to a teammate
+type PassEdge struct {
+	FromPlayerID   int     `json:"from_player_id"`
+	ToPlayerID     int     `json:"to_player_id"`
+	TeamID         int     `json:"team_id"`
+	Passes         int     `json:"passes"`
+	Completed      int     `json:"completed"`
+	CompletionRate float64 `json:"completion_rate"` // Percentage
+}
+
+type passKey struct {
+	from, to int
+}
+
+// TrackingFrame is one engine tick of positional data
+type TrackingFrame struct {
+	Minute    int
+	Ball      PitchPosition
+	Players   map[int]PitchPosition // PlayerID -> position
+	Timestamp time.Time
+}
+
 type GlobalStats struct {
 	TotalMatches     int       `json:"total_matches"`
 	TotalGoals       int       `json:"total_goals"`
@@ -380,19 +957,156 @@ type SeasonWinners struct {
 }
 
 type SeasonHistory struct {
-	Season         int           `json:"season"`
-	Winners        SeasonWinners `json:"winners"`
-	TopScorer      Player        `json:"top_scorer"`
-	TopAssists     Player        `json:"top_assists"`
-	MostFouls      Player        `json:"most_fouls"`
-	PlayerOfSeason Player        `json:"player_of_season"`
-	Champion       TeamInfo      `json:"champion"`
-	TotalGoals     int           `json:"total_goals"`
-	TotalMatches   int           `json:"total_matches"`
-	EndDate        time.Time     `json:"end_date"`
+	Season          int                  `json:"season"`
+	Winners         SeasonWinners        `json:"winners"`
+	TopScorer       Player               `json:"top_scorer"`
+	TopAssists      Player               `json:"top_assists"`
+	MostFouls       Player               `json:"most_fouls"`
+	PlayerOfSeason  Player               `json:"player_of_season"`
+	Champion        TeamInfo             `json:"champion"`
+	TotalGoals      int                  `json:"total_goals"`
+	TotalMatches    int                  `json:"total_matches"`
+	EndDate         time.Time            `json:"end_date"`
+	Leagues         []SeasonLeagueRecord `json:"leagues"`
+	TeamOfSeason    []SeasonAward        `json:"team_of_season"` // Best 4-3-3 by average rating
+	BestYoungPlayer *SeasonAward         `json:"best_young_player,omitempty"`
+	BestGoalkeeper  *SeasonAward         `json:"best_goalkeeper,omitempty"` // Most clean sheets
+	ManagerOfSeason *ManagerAward        `json:"manager_of_season,omitempty"`
+}
+
+// SeasonLeagueRecord is one league's outcome in an archived season
+type SeasonLeagueRecord struct {
+	League       string        `json:"league"`
+	Champion     TeamInfo      `json:"champion"`
+	GoldenBoot   []SeasonAward `json:"golden_boot"` // Top scorers, best first
+	TopAssists   []SeasonAward `json:"top_assists"`
+	TotalGoals   int           `json:"total_goals"`
+	TotalMatches int           `json:"total_matches"`
+}
+
+// SeasonAward credits a player with the figure that earned an award
+type SeasonAward struct {
+	PlayerID   int     `json:"player_id"`
+	PlayerName string  `json:"player_name"`
+	Position   string  `json:"position"`
+	Age        int     `json:"age"`
+	TeamID     int     `json:"team_id"`
+	TeamName   string  `json:"team_name"`
+	League     string  `json:"league"`
+	Value      float64 `json:"value"` // Goals, assists, clean sheets or average rating
+	Matches    int     `json:"matches"`
+}
+
+// ManagerAward goes to the manager whose side gained the most Elo rating over the season
+type ManagerAward struct {
+	Manager    string  `json:"manager"`
+	TeamID     int     `json:"team_id"`
+	TeamName   string  `json:"team_name"`
+	League     string  `json:"league"`
+	Position   int     `json:"position"`
+	Points     int     `json:"points"`
+	RatingGain float64 `json:"rating_gain"`
+}
+
+// SeasonResult is a finished fixture kept in the season archive
+type SeasonResult struct {
+	MatchID    int       `json:"match_id"`
+	League     string    `json:"league"`
+	Matchweek  int       `json:"matchweek"`
+	HomeTeamID int       `json:"home_team_id"`
+	HomeTeam   string    `json:"home_team"`
+	AwayTeamID int       `json:"away_team_id"`
+	AwayTeam   string    `json:"away_team"`
+	HomeScore  int       `json:"home_score"`
+	AwayScore  int       `json:"away_score"`
+	Attendance int       `json:"attendance"`
+	Date       time.Time `json:"date"`
+}
+
+// MatchRecord is a result that ranks among the all-time marks
+type MatchRecord struct {
+	MatchID    int       `json:"match_id"`
+	Season     int       `json:"season"`
+	Matchweek  int       `json:"matchweek"`
+	League     string    `json:"league"`
+	HomeTeamID int       `json:"home_team_id"`
+	HomeTeam   string    `json:"home_team"`
+	AwayTeamID int       `json:"away_team_id"`
+	AwayTeam   string    `json:"away_team"`
+	HomeScore  int       `json:"home_score"`
+	AwayScore  int       `json:"away_score"`
+	Value      int       `json:"value"` // Winning margin or total goals
+	Date       time.Time `json:"date"`
+}
+
+// GoalRecord is one of the fastest goals on record
+type GoalRecord struct {
+	MatchID    int       `json:"match_id"`
+	Season     int       `json:"season"`
+	Minute     int       `json:"minute"`
+	PlayerID   int       `json:"player_id"`
+	PlayerName string    `json:"player_name"`
+	TeamID     int       `json:"team_id"`
+	TeamName   string    `json:"team_name"`
+	Opponent   string    `json:"opponent"`
+	Date       time.Time `json:"date"`
+}
+
+// StreakRecord is a run of results by one team; active while the run continues
+type StreakRecord struct {
+	TeamID       int       `json:"team_id"`
+	TeamName     string    `json:"team_name"`
+	League       string    `json:"league"`
+	Length       int       `json:"length"`
+	Active       bool      `json:"active"`
+	StartMatchID int       `json:"start_match_id"`
+	StartSeason  int       `json:"start_season"`
+	LastMatchID  int       `json:"last_match_id"`
+	LastUpdate   time.Time `json:"last_update"`
+}
+
+// AllTimeRecords holds the best marks across every season, best first
+type AllTimeRecords struct {
+	BiggestWins     []MatchRecord  `json:"biggest_wins"`
+	HighestScoring  []MatchRecord  `json:"highest_scoring"`
+	FastestGoals    []GoalRecord   `json:"fastest_goals"`
+	WinningStreaks  []StreakRecord `json:"winning_streaks"`
+	UnbeatenStreaks []StreakRecord `json:"unbeaten_streaks"`
+	MatchesRecorded int            `json:"matches_recorded"`
+}
+
+// teamRun tracks a team's current winning and unbeaten runs
+type teamRun struct {
+	wins, unbeaten            int
+	winStart, unbeatenStart   int // Match IDs the runs began with
+	winSeason, unbeatenSeason int
+}
+
+// PlayerSeasonRecord is a player's line for one season
+type PlayerSeasonRecord struct {
+	Season        int     `json:"season"`
+	TeamID        int     `json:"team_id"`
+	TeamName      string  `json:"team_name"`
+	League        string  `json:"league"`
+	Age           int     `json:"age"`
+	Matches       int     `json:"matches"`
+	Minutes       int     `json:"minutes"`
+	Goals         int     `json:"goals"`
+	Assists       int     `json:"assists"`
+	YellowCards   int     `json:"yellow_cards"`
+	RedCards      int     `json:"red_cards"`
+	AverageRating float64 `json:"average_rating"`
+	InProgress    bool    `json:"in_progress,omitempty"` // The current season
+}
+
+// SeasonArchive keeps the final standings and results of a finished season
+type SeasonArchive struct {
+	Standings map[string][]LeagueTable `json:"standings"`
+	Results   []SeasonResult           `json:"results"`
 }
 
 type SeasonSchedule struct {
+	ID          int       `json:"id"`
 	Matchday    int       `json:"matchday"`
 	League      string    `json:"league"`
 	HomeTeam    *TeamInfo `json:"home_team"`
@@ -411,6 +1125,63 @@ type LeagueConfig struct {
 	EndTeamID   int    `json:"end_team_id"`
 }
 
+// Fantasy league structures
+type FantasyTeam struct {
+	ID             int                `json:"id"`
+	Name           string             `json:"name"`
+	Manager        string             `json:"manager"`
+	PlayerIDs      []int              `json:"player_ids"`
+	CaptainID      int                `json:"captain_id"`
+	SquadValue     int                `json:"squad_value"`
+	Bank           int                `json:"bank"` // Remaining budget
+	TotalPoints    int                `json:"total_points"`
+	FreeTransfers  int                `json:"free_transfers"`
+	TransferWindow string             `json:"-"` // Season/gameweek the free transfers belong to
+	Transfers      []*FantasyTransfer `json:"transfers"`
+	Gameweeks      []*FantasyGameweek `json:"-"`
+	CreatedAt      time.Time          `json:"created_at"`
+	LastUpdate     time.Time          `json:"last_update"`
+}
+
+type FantasyTransfer struct {
+	PlayerOut int       `json:"player_out"`
+	PlayerIn  int       `json:"player_in"`
+	Season    int       `json:"season"`
+	Gameweek  int       `json:"gameweek"`
+	Cost      int       `json:"cost"` // Points deducted
+	Timestamp time.Time `json:"timestamp"`
+}
+
+type FantasyGameweek struct {
+	Season       int         `json:"season"`
+	Gameweek     int         `json:"gameweek"`
+	Points       int         `json:"points"`
+	TransferCost int         `json:"transfer_cost"`
+	CaptainID    int         `json:"captain_id"`
+	PlayerPoints map[int]int `json:"player_points"` // PlayerID -> points (captain already doubled)
+}
+
+// Score prediction for a scheduled fixture
+type Prediction struct {
+	ID          int        `json:"id"`
+	User        string     `json:"user"`
+	FixtureID   int        `json:"fixture_id"`
+	MatchID     int        `json:"match_id,omitempty"`
+	Season      int        `json:"season"`
+	Matchday    int        `json:"matchday"`
+	League      string     `json:"league"`
+	HomeTeam    string     `json:"home_team"`
+	AwayTeam    string     `json:"away_team"`
+	HomeScore   int        `json:"home_score"`
+	AwayScore   int        `json:"away_score"`
+	Status      string     `json:"status"`            // OPEN, LOCKED, SCORED
+	Outcome     string     `json:"outcome,omitempty"` // EXACT, RESULT, MISS
+	Points      int        `json:"points"`
+	SubmittedAt time.Time  `json:"submitted_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	ScoredAt    *time.Time `json:"scored_at,omitempty"`
+}
+
 // Global league configurations
 var leagueConfigs = map[string]LeagueConfig{
 	LeaguePremier: {
@@ -432,31 +1203,48 @@ var leagueConfigs = map[string]LeagueConfig{
 // In-memory database - add season schedules
 var (
 	// Original storage
-	matches         = make(map[int]*Match)
-	finishedMatches = make(map[int]*Match) // New map for finished matches
-	matchStats      = make(map[int]*MatchStats)
-	players         = make(map[int]*Player)
-	teams           = make(map[int]*TeamInfo)
-	leagueTables    = make(map[string][]*LeagueTable)
-	liveCommentary  = make(map[int][]*LiveCommentary)
-	globalStats     = &GlobalStats{}
+	matches          = make(map[int]*Match)
+	finishedMatches  = make(map[int]*Match) // New map for finished matches
+	matchStats       = make(map[int]*MatchStats)
+	players          = make(map[int]*Player)
+	teams            = make(map[int]*TeamInfo)
+	leagueTables     = make(map[string][]*LeagueTable)
+	liveCommentary   = make(map[int][]*LiveCommentary) // matchID -> commentary, oldest first
+	commentaryCap    = DefaultCommentaryCap
+	matchTimelines   = make(map[int][]*TimelineEvent)          // matchID -> events, oldest first
+	matchLineups     = make(map[int]map[int][]int)             // matchID -> teamID -> starting XI in formation order
+	matchPlayerStats = make(map[int]map[int]*MatchPlayerStats) // matchID -> playerID -> stat line
+	matchTracking    = make(map[int][]*TrackingFrame)          // matchID -> positional history, oldest first
+	matchPasses      = make(map[int]map[passKey]*PassEdge)     // matchID -> pass edges
+	timelineCounter  = 0
+	globalStats      = &GlobalStats{}
 
 	// Extended storage
 	playerLocations  = make(map[int]map[int]*PlayerLocation) // matchID -> playerID -> location
+	retiredPlayers   = make(map[int]*Player)                 // playerID -> retired player
 	seasonHistory    = make([]SeasonHistory, 0, MaxSeasonHistory)
+	seasonArchives   = make(map[int]*SeasonArchive) // season -> final standings and results
+	allTimeRecords   = &AllTimeRecords{}
+	teamRuns         = make(map[int]*teamRun)             // teamID -> current streaks
+	playerCareers    = make(map[int][]PlayerSeasonRecord) // playerID -> finished seasons, oldest first
 	seasonSchedules  = make(map[string][]*SeasonSchedule) // league -> schedules
 	currentSeason    = 1
 	currentMatchweek = 1
 
 	// New storage for enhanced features
-	newsEntries = make([]*NewsEntry, 0, MaxNewsEntries)
-	logEntries  = make([]*LogEntry, 0, MaxLogEntries)
-	newsCounter = 0
-	logCounter  = 0
+	newsEntries  = make([]*NewsEntry, 0, MaxNewsEntries)
+	logEntries   = make([]*LogEntry, 0, MaxLogEntries)
+	newsCounter  = 0
+	logCounter   = 0
+	logLevel     = LevelInfo     // Set once by loadLogLevel before the engine starts, so read without a lock
+	logMutex     = &sync.Mutex{} // Loggers run with and without the main mutex held
+	logLevelRank = map[string]int{LevelDebug: 0, LevelInfo: 1, LevelWarn: 2, LevelError: 3}
+	matchScorers = make(map[int]map[int]int) // matchID -> playerID -> goals
 
 	// Counters and synchronization
 	commentaryCounter = 0
 	matchCounter      = 0
+	playerCounter     = 0
 	mutex             = &sync.RWMutex{}
 	version           = "1.2.0"
 
@@ -465,6 +1253,34 @@ var (
 	playerAvailability   = make(map[int]map[int]*PlayerAvailability) // MatchID -> PlayerID -> Availability
 	dynamicProbabilities = make(map[int]*DynamicMatchProbabilities)  // MatchID -> Probabilities
 
+	// Odds service
+	matchOdds       = make(map[int]*MatchOdds)      // MatchID -> current markets
+	oddsHistory     = make(map[int][]*OddsSnapshot) // MatchID -> price timeline
+	bookmakerMargin = DefaultBookmakerMargin
+
+	// Fantasy league
+	fantasyTeams       = make(map[int]*FantasyTeam)
+	fantasyMatchPoints = make(map[int]map[int]int) // MatchID -> PlayerID -> points scored so far
+	fantasyTeamCounter = 0
+
+	// Prediction game
+	predictions       = make(map[int]map[string]*Prediction) // FixtureID -> user -> prediction
+	predictionCounter = 0
+	fixtureCounter    = 0
+
+	// Transfer market
+	transfers       = make([]*Transfer, 0, MaxTransferHistory)
+	transferCounter = 0
+	transferSeed    = time.Now().UnixNano()
+
+	// Club finances
+	clubFinances   = make(map[int]*ClubFinances) // TeamID -> finances
+	financeCounter = 0
+
+	// Elo ratings, carried across seasons
+	eloRatings    = make(map[int]*EloRating)      // TeamID -> rating
+	ratingHistory = make(map[int][]*RatingChange) // TeamID -> rating changes, oldest first
+
 	// Add this at the top of the file with other global variables
 	startTime = time.Now()
 
@@ -481,30 +1297,68 @@ var teamData = []struct {
 	League    string
 	Manager   string
 	Founded   int
+	Capacity  int
 }{
 	// Premier League - 10 Teams
-	{1, "Capricon FC", "CAP", "Stellar Stadium", LeaguePremier, "Viktor Cosmos", 2180},
-	{2, "The Galacticons", "GAL", "Nebula Arena", LeaguePremier, "Zara Starfield", 2175},
-	{3, "Axton Brothers", "AXT", "Quantum Park", LeaguePremier, "Rex Axiom", 2182},
-	{4, "Deuteron United", "DEU", "Fusion Field", LeaguePremier, "Nova Nucleus", 2178},
-	{5, "Saturn Rovers", "SAT", "Ring Stadium", LeaguePremier, "Luna Orbit", 2179},
-	{6, "Meteor City", "MET", "Impact Zone", LeaguePremier, "Comet Trail", 2181},
-	{7, "Cosmic Wanderers", "COS", "Infinity Ground", LeaguePremier, "Astro Nova", 2177},
-	{8, "Pulsar Athletic", "PUL", "Photon Arena", LeaguePremier, "Ray Beacon", 2183},
-	{9, "Nebula FC", "NEB", "Star Dust Stadium", LeaguePremier, "Cloud Walker", 2176},
-	{10, "Eclipse United", "ECL", "Shadow Grounds", LeaguePremier, "Dark Matter", 2184},
+	{1, "Capricon FC", "CAP", "Stellar Stadium", LeaguePremier, "Viktor Cosmos", 2180, 62000},
+	{2, "The Galacticons", "GAL", "Nebula Arena", LeaguePremier, "Zara Starfield", 2175, 74500},
+	{3, "Axton Brothers", "AXT", "Quantum Park", LeaguePremier, "Rex Axiom", 2182, 41000},
+	{4, "Deuteron United", "DEU", "Fusion Field", LeaguePremier, "Nova Nucleus", 2178, 38500},
+	{5, "Saturn Rovers", "SAT", "Ring Stadium", LeaguePremier, "Luna Orbit", 2179, 52000},
+	{6, "Meteor City", "MET", "Impact Zone", LeaguePremier, "Comet Trail", 2181, 33000},
+	{7, "Cosmic Wanderers", "COS", "Infinity Ground", LeaguePremier, "Astro Nova", 2177, 47500},
+	{8, "Pulsar Athletic", "PUL", "Photon Arena", LeaguePremier, "Ray Beacon", 2183, 29500},
+	{9, "Nebula FC", "NEB", "Star Dust Stadium", LeaguePremier, "Cloud Walker", 2176, 56000},
+	{10, "Eclipse United", "ECL", "Shadow Grounds", LeaguePremier, "Dark Matter", 2184, 36000},
 
 	// Community League - 10 Teams
-	{11, "Nova Dynamics", "NOV", "Quantum Field", LeagueCommunityLeague, "Atlas Prime", 2178},
-	{12, "Starlight FC", "SFC", "Celestial Arena", LeagueCommunityLeague, "Vega Solaris", 2181},
-	{13, "Orion Warriors", "ORI", "Constellation Park", LeagueCommunityLeague, "Leo Sterling", 2176},
-	{14, "Zenith United", "ZEN", "Horizon Stadium", LeagueCommunityLeague, "Aurora Borealis", 2183},
-	{15, "Quasar City", "QUA", "Plasma Ground", LeagueCommunityLeague, "Sirius Flux", 2179},
-	{16, "Astral Rovers", "AST", "Galaxy Dome", LeagueCommunityLeague, "Helios Star", 2182},
-	{17, "Eclipse Knights", "EKN", "Shadow Field", LeagueCommunityLeague, "Umbra Knight", 2177},
-	{18, "Neutron FC", "NEU", "Energy Arena", LeagueCommunityLeague, "Proton Wave", 2180},
-	{19, "Vortex Athletic", "VOR", "Cyclone Stadium", LeagueCommunityLeague, "Tempest Storm", 2175},
-	{20, "Cosmic Rangers", "COS", "Meteor Ground", LeagueCommunityLeague, "Comet Chase", 2184},
+	{11, "Nova Dynamics", "NOV", "Quantum Field", LeagueCommunityLeague, "Atlas Prime", 2178, 24000},
+	{12, "Starlight FC", "SFC", "Celestial Arena", LeagueCommunityLeague, "Vega Solaris", 2181, 18500},
+	{13, "Orion Warriors", "ORI", "Constellation Park", LeagueCommunityLeague, "Leo Sterling", 2176, 21000},
+	{14, "Zenith United", "ZEN", "Horizon Stadium", LeagueCommunityLeague, "Aurora Borealis", 2183, 16000},
+	{15, "Quasar City", "QUA", "Plasma Ground", LeagueCommunityLeague, "Sirius Flux", 2179, 27500},
+	{16, "Astral Rovers", "AST", "Galaxy Dome", LeagueCommunityLeague, "Helios Star", 2182, 19000},
+	{17, "Eclipse Knights", "EKN", "Shadow Field", LeagueCommunityLeague, "Umbra Knight", 2177, 14500},
+	{18, "Neutron FC", "NEU", "Energy Arena", LeagueCommunityLeague, "Proton Wave", 2180, 22000},
+	{19, "Vortex Athletic", "VOR", "Cyclone Stadium", LeagueCommunityLeague, "Tempest Storm", 2175, 12500},
+	{20, "Cosmic Rangers", "COS", "Meteor Ground", LeagueCommunityLeague, "Comet Chase", 2184, 17000},
+}
+
+// Rivalries within a league; derbies draw bigger, louder crowds
+var derbies = []struct {
+	TeamA int
+	TeamB int
+	Name  string
+}{
+	{2, 9, "Galactic Derby"},
+	{3, 4, "Particle Derby"},
+	{5, 10, "Orbit Derby"},
+	{6, 7, "Cosmic Clash"},
+	{11, 12, "Stellar Derby"},
+	{13, 14, "Horizon Derby"},
+	{15, 18, "Quantum Derby"},
+	{16, 20, "Star Derby"},
+}
+
+var refereeData = []struct {
+	ID          int
+	Name        string
+	Nationality string
+	Strictness  int
+	Consistency int
+}{
+	{1, "Orion Blake", NatEngland, 72, 85},
+	{2, "Selene Marsh", NatEngland, 45, 90},
+	{3, "Castor Vidal", NatSpain, 80, 60},
+	{4, "Lyra Moretti", NatItaly, 35, 75},
+	{5, "Atlas Ferreira", NatSpain, 60, 50},
+	{6, "Nova Kessler", NatEngland, 55, 80},
+	{7, "Draco Bianchi", NatItaly, 88, 70},
+	{8, "Vesta Ortega", NatSpain, 25, 65},
+	{9, "Titan Hughes", NatEngland, 65, 40},
+	{10, "Juno Ricci", NatItaly, 50, 95},
+	{11, "Rigel Santos", NatSpain, 40, 55},
+	{12, "Carina Webb", NatEngland, 75, 78},
 }
 
 var playerNames = []struct {
@@ -573,6 +1427,10 @@ func corsMiddleware(next http.Handler) http.Handler {
 func init() {
 	rand.Seed(time.Now().UnixNano())
 	loadVersion()
+	loadLogLevel()
+	loadBookmakerMargin()
+	loadTransferSeed()
+	loadCommentaryCap()
 	initializeSimulation()
 	startSimulationEngine()
 }
@@ -583,8 +1441,32 @@ func loadVersion() {
 	}
 }
 
+func loadBookmakerMargin() {
+	if value := os.Getenv("BOOKMAKER_MARGIN"); value != "" {
+		if margin, err := strconv.ParseFloat(value, 64); err == nil && margin >= 0 && margin < 0.5 {
+			bookmakerMargin = margin
+		}
+	}
+}
+
+func loadTransferSeed() {
+	if value := os.Getenv("TRANSFER_SEED"); value != "" {
+		if seed, err := strconv.ParseInt(value, 10, 64); err == nil {
+			transferSeed = seed
+		}
+	}
+}
+
+func loadCommentaryCap() {
+	if value := os.Getenv("COMMENTARY_CAP"); value != "" {
+		if limit, err := strconv.Atoi(value); err == nil && limit > 0 {
+			commentaryCap = limit
+		}
+	}
+}
+
 func initializeSimulation() {
-	mutex.Lock()
+	lockMutex("initialize")
 	defer mutex.Unlock()
 
 	// Initialize teams with form tracking
@@ -598,11 +1480,14 @@ func initializeSimulation() {
 			Founded:    teamInfo.Founded,
 			Manager:    teamInfo.Manager,
 			League:     teamInfo.League,
+			Capacity:   teamInfo.Capacity,
 			Form:       []string{},
 			FormPoints: 0,
 		}
 	}
 
+	initializeReferees()
+
 	playerID := 1
 	for _, team := range teams {
 		// Realistic squad composition: 18-20 players per team
@@ -623,13 +1508,14 @@ func initializeSimulation() {
 			name := fmt.Sprintf("%s %d", playerTemplate.Name, i+1)
 
 			characteristics := generatePlayerCharacteristics(position)
+			age := 18 + rand.Intn(20)
 
 			players[playerID] = &Player{
 				ID:              playerID,
 				Name:            name,
 				Position:        position, // Use actual position, not template
 				Number:          i + 1,
-				Age:             18 + rand.Intn(20),
+				Age:             age,
 				Nationality:     playerTemplate.Nationality,
 				AvatarURL:       fmt.Sprintf("https://i.pravatar.cc/150?img=%d", (playerID%70)+1),
 				TeamID:          team.ID,
@@ -638,7 +1524,7 @@ func initializeSimulation() {
 				YellowCards:     0,
 				RedCards:        0,
 				Appearances:     0,
-				MarketValue:     calculateMarketValue(characteristics),
+				MarketValue:     calculateMarketValue(characteristics, age),
 				LastUpdate:      time.Now(),
 				Characteristics: characteristics,
 				SeasonStats:     PlayerSeasonStats{},
@@ -647,6 +1533,10 @@ func initializeSimulation() {
 			playerID++
 		}
 	}
+	playerCounter = playerID - 1
+	refreshSquadSummaries()
+	initializeClubFinances()
+	initializeEloRatings()
 
 	initializeLeagueTables()
 
@@ -662,7 +1552,7 @@ func initializeSimulation() {
 
 	// Create initial matches if none exists - fill up to MaxSimultaneousMatches per league
 	if len(matches) == 0 {
-		log.Printf("🏁 Creating initial matches up to maximum (%d per league)...", MaxSimultaneousMatches)
+		logWithFields(LevelInfo, LogFields{Component: "scheduler"}, "🏁 Creating initial matches up to maximum (%d per league)...", MaxSimultaneousMatches)
 		matchesCreated := 0
 
 		// Create matches for each league
@@ -671,20 +1561,20 @@ func initializeSimulation() {
 				// Find next match for this specific league
 				nextMatch := getNextUnplayedMatchForLeague(league)
 				if nextMatch != nil {
-					log.Printf("📊 Creating initial match %d/%d for %s: %s vs %s",
+					logWithFields(LevelInfo, LogFields{League: league, Component: "scheduler"}, "📊 Creating initial match %d/%d for %s: %s vs %s",
 						i+1, MaxSimultaneousMatches, league, nextMatch.HomeTeam.ShortName, nextMatch.AwayTeam.ShortName)
 					createNextMatch()
 					matchesCreated++
 				} else {
-					log.Printf("⚠️  No more matches available for %s after %d matches", league, i)
+					logWithFields(LevelWarn, LogFields{League: league, Component: "scheduler"}, "⚠️  No more matches available for %s after %d matches", league, i)
 					break
 				}
 			}
 		}
-		log.Printf("✅ Created %d initial matches", matchesCreated)
+		logWithFields(LevelInfo, LogFields{Component: "scheduler"}, "✅ Created %d initial matches", matchesCreated)
 	}
 
-	log.Printf("🏆 Simulation initialized: %d leagues, %d teams (%d players each), %d active matches",
+	logInfo("🏆 Simulation initialized: %d leagues, %d teams (%d players each), %d active matches",
 		len(leagueConfigs), len(teams), 25, len(matches))
 }
 
@@ -771,9 +1661,22 @@ func generatePlayerCharacteristics(position string) PlayerCharacteristics {
 	}
 }
 
-func calculateMarketValue(characteristics PlayerCharacteristics) int {
+func calculateMarketValue(characteristics PlayerCharacteristics, age int) int {
 	// Market value based on overall rating (5-200 million)
 	baseValue := characteristics.Overall / 2
+
+	// Young players carry a premium, veterans a discount
+	switch {
+	case age <= 21:
+		baseValue = baseValue * 13 / 10
+	case age <= 24:
+		baseValue = baseValue * 115 / 100
+	case age >= 32:
+		baseValue = baseValue * 6 / 10
+	case age >= 29:
+		baseValue = baseValue * 85 / 100
+	}
+
 	variation := rand.Intn(30) - 15 // +/- 15%
 	return max(5, min(200, baseValue+variation))
 }
@@ -802,7 +1705,8 @@ func matchEngine(ctx context.Context) {
 	for {
 		select {
 		case <-ticker.C:
-			mutex.Lock()
+			lockMutex("match_engine")
+			tickStart := time.Now()
 			activeMatches := 0
 			liveMatches := 0
 			halftimeMatches := 0
@@ -812,7 +1716,7 @@ func matchEngine(ctx context.Context) {
 			// Count matches by status first
 			for _, match := range matches {
 				switch match.Status {
-				case StatusLive:
+				case StatusLive, StatusVARCheck:
 					liveMatches++
 					activeMatches++
 				case StatusHalftime:
@@ -826,15 +1730,16 @@ func matchEngine(ctx context.Context) {
 				}
 			}
 
-			logInfo("📊 Match status: Active: %d (Live: %d, Halftime: %d, Break: %d), Finished: %d, Total: %d",
+			logDebug("📊 Match status: Active: %d (Live: %d, Halftime: %d, Break: %d), Finished: %d, Total: %d",
 				activeMatches, liveMatches, halftimeMatches, breakMatches, finishedMatches, len(matches))
 
 			// Update live matches using comprehensive logic
 			matchesUpdated := 0
 			for matchID, match := range matches {
-				if match.Status == StatusLive || match.Status == StatusHalftime || match.Status == StatusBreak {
+				if match.Status == StatusLive || match.Status == StatusHalftime || match.Status == StatusBreak || match.Status == StatusVARCheck {
 					elapsed := time.Since(match.StartTime).Seconds()
-					logInfo("⚽ Updating match %d: %s vs %s (Minute %d→%.0f, Status: %s, Elapsed: %.1fs)",
+					logWithFields(LevelDebug, matchLogFields(match, "engine"),
+						"⚽ Updating match %d: %s vs %s (Minute %d→%.0f, Status: %s, Elapsed: %.1fs)",
 						matchID, match.HomeTeam.ShortName, match.AwayTeam.ShortName,
 						match.Minute, elapsed, match.Status, elapsed)
 
@@ -843,7 +1748,7 @@ func matchEngine(ctx context.Context) {
 
 					// Check if match just finished to update league table
 					if match.Status == StatusFinished {
-						logInfo("🏁 Match %d finished: %s %d-%d %s",
+						logWithFields(LevelInfo, matchLogFields(match, "engine"), "🏁 Match %d finished: %s %d-%d %s",
 							matchID, match.HomeTeam.ShortName, match.HomeScore,
 							match.AwayScore, match.AwayTeam.ShortName)
 						updateLeagueTable(match)
@@ -855,7 +1760,7 @@ func matchEngine(ctx context.Context) {
 			}
 
 			if activeMatches == 0 {
-				logInfo("⚠️  No active matches found - total matches: %d", len(matches))
+				logWarn("⚠️  No active matches found - total matches: %d", len(matches))
 				logInfo("🆕 Attempting to create new matches...")
 
 				// Try to create new matches if we have none active
@@ -868,15 +1773,16 @@ func matchEngine(ctx context.Context) {
 						createNextMatch()
 						newMatchesCreated++
 					} else {
-						logInfo("⚠️  No more scheduled matches available")
+						logWarn("⚠️  No more scheduled matches available")
 						break
 					}
 				}
 				logInfo("✅ Created %d new matches", newMatchesCreated)
 			} else {
-				logInfo("✅ Updated %d active matches", matchesUpdated)
+				logDebug("✅ Updated %d active matches", matchesUpdated)
 			}
 
+			recordTickMetric(time.Since(tickStart))
 			mutex.Unlock()
 
 		case <-ctx.Done():
@@ -898,43 +1804,61 @@ func updateLiveMatchWithBreaks(matchID int, match *Match) {
 		match.Minute = int(elapsed)
 
 		if match.Minute != oldMinute {
-			logInfo("⏱️  Match %d: Minute %d → %d (Elapsed: %.1fs)", matchID, oldMinute, match.Minute, elapsed)
+			logWithFields(LevelDebug, matchLogFields(match, "engine"), "⏱️  Match %d: Minute %d → %d (Elapsed: %.1fs)", matchID, oldMinute, match.Minute, elapsed)
 		}
 
-		// Check for halftime (45 minutes + injury time)
-		if match.Minute >= 45 && match.Minute < 46 {
-			logInfo("🏃‍♂️ Match %d: HALFTIME! Teams head to the tunnel", matchID)
+		// Halftime is called once, on the first tick at minute 45 or later; HalftimeEndTime
+		// stays set afterwards. The break moves StartTime on, so the clock stops for halftime
+		// and the second half resumes from the minute the first half ended on
+		if match.Minute >= 45 && match.HalftimeEndTime.IsZero() {
+			logWithFields(LevelInfo, matchLogFields(match, "engine"), "🏃‍♂️ Match %d: HALFTIME! Teams head to the tunnel", matchID)
 			match.Status = StatusHalftime
 			match.HalftimeEndTime = now.Add(HalftimeBreakSeconds * time.Second)
 			match.IsInBreak = true
 			addLiveCommentary(matchID, match.Minute, "Halftime! Teams head to the tunnel", EventCommentary, nil)
+			recordTimelineEvent(match, &TimelineEvent{Type: EventHalftime})
+			generateHalftimeNews(matchID, match)
 			return
 		}
 
 		// Check for full time (90 minutes + injury time)
 		totalMatchTime := MatchDurationSeconds + match.InjuryTime
 		if elapsed >= float64(totalMatchTime) {
-			logInfo("⏰ Match %d: Time's up! Finishing match...", matchID)
+			logWithFields(LevelDebug, matchLogFields(match, "engine"), "⏰ Match %d: Time's up! Finishing match...", matchID)
 			finishMatch(matchID, match)
 			return
 		}
 
 		// Generate events during live play
 		if rand.Float32() < 0.15 {
-			logInfo("🎲 Match %d: Event triggered! Generating match event...", matchID)
+			logWithFields(LevelDebug, matchLogFields(match, "engine"), "🎲 Match %d: Event triggered! Generating match event...", matchID)
 			generateMatchEvent(match)
 		}
 
+		// Managers react to how the game is going
+		updateManagerTactics(matchID, match)
+		updateSubstitutions(matchID, match)
+		updateMatchWeather(matchID, match)
+		applyCrowdInfluence(matchID, match)
+
 	case StatusHalftime:
 		// Check if halftime break is over
 		if now.After(match.HalftimeEndTime) {
-			logInfo("🏃‍♂️ Match %d: Second half underway!", matchID)
+			logWithFields(LevelInfo, matchLogFields(match, "engine"), "🏃‍♂️ Match %d: Second half underway!", matchID)
 			match.Status = StatusLive
 			match.IsInBreak = false
 			// Reset start time to account for break
 			breakDuration := now.Sub(match.HalftimeEndTime.Add(-HalftimeBreakSeconds * time.Second))
 			match.StartTime = match.StartTime.Add(breakDuration)
 			addLiveCommentary(matchID, match.Minute, "Second half underway!", EventCommentary, nil)
+			recordTimelineEvent(match, &TimelineEvent{Type: EventKickoff, Detail: "second half", Position: &PitchPosition{X: FieldWidth / 2, Y: FieldHeight / 2}})
+		}
+		return
+
+	case StatusVARCheck:
+		// Play stays frozen until the review is over
+		if match.VARReview == nil || now.After(match.VARReview.ResolvesAt) {
+			resolveVARReview(match, now)
 		}
 		return
 
@@ -950,15 +1874,19 @@ func updateLiveMatchWithBreaks(matchID int, match *Match) {
 
 	// Update match statistics
 	updateMatchStatistics(matchID, match)
+	refreshMatchPlayerStats(matchID, match)
+
+	// Reprice betting markets
+	updateMatchOdds(matchID, match)
 	match.LastUpdate = time.Now()
 }
 
 func generateMatchEvent(match *Match) {
-	logInfo("🎯 Generating event for match %d at minute %d", match.ID, match.Minute)
+	logWithFields(LevelDebug, matchLogFields(match, "engine"), "🎯 Generating event for match %d at minute %d", match.ID, match.Minute)
 
 	// Calculate team strengths
-	homeStrength := calculateTeamStrength(&match.HomeTeam)
-	awayStrength := calculateTeamStrength(&match.AwayTeam)
+	homeStrength := calculateTeamStrength(match.ID, &match.HomeTeam)
+	awayStrength := calculateTeamStrength(match.ID, &match.AwayTeam)
 
 	// Base event probabilities
 	baseProbabilities := map[string]float32{
@@ -988,6 +1916,11 @@ func generateMatchEvent(match *Match) {
 		baseProbabilities[EventFoul] *= float32(1.0 - strengthDiff*0.3)
 	}
 
+	// Strict referees blow for more fouls and show more cards
+	strictness := float32(refereeStrictness(match))
+	baseProbabilities[EventFoul] *= 0.8 + 0.4*strictness
+	baseProbabilities[EventCard] *= 0.6 + 0.8*strictness
+
 	// Normalize probabilities
 	totalProb := float32(0)
 	for _, prob := range baseProbabilities {
@@ -1007,7 +1940,15 @@ func generateMatchEvent(match *Match) {
 			// Handle the selected event
 			switch eventType {
 			case EventGoal:
+				goalsBefore := match.HomeScore + match.AwayScore
 				handleGoalEvent(match.ID, match)
+				if match.HomeScore+match.AwayScore > goalsBefore {
+					reviewGoal(match)
+					// A goal under review is counted once it stands
+					if match.Status != StatusVARCheck {
+						confirmGoal(match.ID, match)
+					}
+				}
 			case EventCard:
 				handleCardEvent(match.ID, match)
 			case EventCorner:
@@ -1033,65 +1974,117 @@ func handleGoalEvent(matchID int, match *Match) {
 		return
 	}
 
+	scorer, shooter, onTarget := resolveGoalChance(matchID, match, ball)
+	if scorer != nil {
+		scoreGoal(matchID, match, scorer, ball)
+	} else if shooter != nil {
+		recordShot(matchID, match, shooter, onTarget)
+	}
+}
+
+// resolveGoalChance decides whether a goal chance is taken. It returns the scorer, or the
+// shooter of a missed chance and whether the miss was on target; both are nil when nobody
+// is in a position to shoot. Wind and fatigue spoil chances through shotConditionsFactor
+func resolveGoalChance(matchID int, match *Match, ball *BallPosition) (scorer, shooter *Player, onTarget bool) {
 	// O(1) scoring: whoever has the ball scores (realistic!)
-	var scorer *Player
 	if ball.PossessorID > 0 {
 		if player, exists := players[ball.PossessorID]; exists {
 			// Only attackers and midfielders can score in attacking third
-			if ball.X > 70 || ball.X < 30 { // In attacking third
-				if player.Position != PosGK && rand.Float64() < getGoalProbability(player, ball) {
-					scorer = player
+			if (ball.X > 70 || ball.X < 30) && player.Position != PosGK { // In attacking third
+				conditions := shotConditionsFactor(matchID, match, player)
+				if rand.Float64() >= getGoalProbability(player, ball)*conditions {
+					// The chance is gone; nobody else gets to score from it
+					return nil, player, rand.Float64() < 0.4*conditions
 				}
+				return player, nil, true
 			}
 		}
 	}
 
 	// If no possessor or GK has ball, find nearest attacking player
-	if scorer == nil {
-		scorer = findNearestAttackingPlayer(matchID, ball)
+	player := findNearestAttackingPlayer(matchID, ball)
+	if player == nil {
+		return nil, nil, false
+	}
+	if rand.Float64() >= shotConditionsFactor(matchID, match, player) {
+		return nil, player, false
 	}
+	return player, nil, true
+}
 
-	if scorer != nil {
-		isHomeGoal := scorer.TeamID == match.HomeTeam.ID
-		if isHomeGoal {
-			match.HomeScore++
-		} else {
-			match.AwayScore++
+// scoreGoal credits a goal to the scorer and records everything that follows from it,
+// returning the timeline event so callers can describe the goal further
+func scoreGoal(matchID int, match *Match, scorer *Player, ball *BallPosition) *TimelineEvent {
+	isHomeGoal := scorer.TeamID == match.HomeTeam.ID
+	if isHomeGoal {
+		match.HomeScore++
+	} else {
+		match.AwayScore++
+	}
+
+	// Update scorer stats
+	scorer.Goals++
+	scorer.SeasonStats.GoalsThisSeason++
+	recordShot(matchID, match, scorer, true)
+	if line := matchPlayerLine(matchID, scorer); line != nil {
+		line.Goals++
+	}
+
+	// O(1) assist: previous ball possessor gets assist
+	var assister *Player
+	if ball.LastTouchID != 0 && ball.LastTouchID != scorer.ID {
+		if player, exists := players[ball.LastTouchID]; exists &&
+			player.TeamID == scorer.TeamID { // Same team
+			assister = player
+			assister.Assists++
+			assister.SeasonStats.AssistsThisSeason++
+			if line := matchPlayerLine(matchID, assister); line != nil {
+				line.Assists++
+			}
 		}
+	}
 
-		// Update scorer stats
-		scorer.Goals++
-		scorer.SeasonStats.GoalsThisSeason++
-		scorer.CurrentRating += 2.0
+	goalEvent := &TimelineEvent{
+		Type:       EventGoal,
+		TeamID:     scorer.TeamID,
+		PlayerID:   scorer.ID,
+		PlayerName: scorer.Name,
+		Position:   ballPitchPosition(ball),
+	}
+	if assister != nil {
+		goalEvent.AssistID = assister.ID
+		goalEvent.AssistName = assister.Name
+	}
+	recordTimelineEvent(match, goalEvent)
 
-		// O(1) assist: previous ball possessor gets assist
-		var assister *Player
-		if ball.LastTouchID != 0 && ball.LastTouchID != scorer.ID {
-			if player, exists := players[ball.LastTouchID]; exists &&
-				player.TeamID == scorer.TeamID { // Same team
-				assister = player
-				assister.Assists++
-				assister.SeasonStats.AssistsThisSeason++
-				assister.CurrentRating += 1.0
-			}
-		}
+	// Per-match tally for scorer lines and hat-trick news
+	if news := recordMatchGoal(matchID, match, scorer); news != nil {
+		goalEvent.newsID = news.ID
+	}
 
-		// Update momentum - goals significantly impact the game
-		updateMatchMomentum(matchID, match, "goal", scorer.TeamID)
+	// Fantasy points for goal and assist
+	recordFantasyEvent(matchID, scorer, "goal")
+	recordFantasyEvent(matchID, assister, "assist")
 
-		// Recalculate match probabilities after goal
-		recalculateMatchProbabilities(matchID, match)
+	// Update momentum - goals significantly impact the game
+	updateMatchMomentum(matchID, match, "goal", scorer.TeamID)
 
-		// Reset ball for kickoff
-		setBallEvent(matchID, BallEventKickoff, FieldWidth/2, FieldHeight/2, 0)
+	// Recalculate match probabilities after goal
+	recalculateMatchProbabilities(matchID, match)
 
-		// Commentary with assist info
-		commentary := fmt.Sprintf("GOAL! %s scores!", scorer.Name)
-		if assister != nil {
-			commentary += fmt.Sprintf(" Assisted by %s.", assister.Name)
-		}
-		addLiveCommentary(matchID, match.Minute, commentary, EventGoal, scorer)
+	// Suspend betting markets while the goal is confirmed
+	suspendMatchOdds(matchID, "GOAL", OddsGoalSuspensionSeconds)
+
+	// Reset ball for kickoff
+	setBallEvent(matchID, BallEventKickoff, FieldWidth/2, FieldHeight/2, 0)
+
+	// Commentary with assist info
+	commentary := fmt.Sprintf("GOAL! %s scores!", scorer.Name)
+	if assister != nil {
+		commentary += fmt.Sprintf(" Assisted by %s.", assister.Name)
 	}
+	addLiveCommentary(matchID, match.Minute, commentary, EventGoal, scorer)
+	return goalEvent
 }
 
 func getGoalProbability(player *Player, ball *BallPosition) float64 {
@@ -1212,8 +2205,8 @@ func findNearestAttackingPlayer(matchID int, ball *BallPosition) *Player {
 }
 
 func handleCardEvent(matchID int, match *Match) {
-	// Only select from available players
-	availablePlayers := getAvailablePlayersForMatch(matchID)
+	// Only select from players still on the pitch
+	availablePlayers := append(getMatchLineup(matchID, match.HomeTeam.ID), getMatchLineup(matchID, match.AwayTeam.ID)...)
 	if len(availablePlayers) == 0 {
 		return
 	}
@@ -1221,15 +2214,24 @@ func handleCardEvent(matchID int, match *Match) {
 	player := availablePlayers[rand.Intn(len(availablePlayers))]
 	isHomePlayer := player.TeamID == match.HomeTeam.ID
 
+	// Cards away from a foul are for conduct rather than the challenge itself
 	cardType := "yellow"
-	if rand.Float32() < 0.1 { // 10% chance for red card
+	reasons := []string{"Unsporting behaviour", "Dissent", "Persistent infringement", "Delaying the restart", "Time wasting"}
+	if rand.Float64() < refereeJudgement(match, 0.1*(0.6+0.8*refereeStrictness(match))*crowdCardBias(match, player.TeamID)) { // ~10% chance for red card
 		cardType = "red"
+		reasons = []string{"Violent conduct", "Offensive language", "Spitting"}
+	}
+	reason := reasons[rand.Intn(len(reasons))]
+
+	var news *NewsEntry
+	if cardType == "red" {
 		player.RedCards++
 		player.SeasonStats.RedCardsThisSeason++
-		player.CurrentRating -= 2.0
+		recordCard(matchID, match, player, cardType)
+		recordFantasyEvent(matchID, player, "red")
 
 		// Mark player as unavailable due to red card
-		setPlayerUnavailable(matchID, player.ID, PlayerRedCard, match.Minute, "Direct red card")
+		setPlayerUnavailable(matchID, player.ID, PlayerRedCard, match.Minute, reason)
 
 		// Update momentum - red card affects team morale
 		updateMatchMomentum(matchID, match, "red_card", player.TeamID)
@@ -1237,24 +2239,41 @@ func handleCardEvent(matchID int, match *Match) {
 		// Recalculate match probabilities
 		recalculateMatchProbabilities(matchID, match)
 
-		logInfo("🟥 RED CARD! %s receives a red card and is sent off!", player.Name)
+		logWithFields(LevelInfo, matchLogFields(match, "events"), "🟥 RED CARD! %s receives a red card and is sent off!", player.Name)
 
 		addLiveCommentary(matchID, match.Minute,
-			fmt.Sprintf("RED CARD! %s is sent off! %s down to 10 men!",
+			fmt.Sprintf("RED CARD! %s is sent off for %s! %s down to %s men!",
 				player.Name,
-				getTeamName(player.TeamID)),
+				strings.ToLower(reason),
+				getTeamName(player.TeamID),
+				playersLeft(11-sentOffCount(matchID, player.TeamID))),
 			EventCard, player)
+		news = generateRedCardNews(matchID, match, player)
 	} else {
 		player.YellowCards++
 		player.SeasonStats.YellowCardsThisSeason++
-		player.CurrentRating -= 0.5
-		logInfo("🟨 YELLOW CARD! %s receives a yellow card", player.Name)
+		recordCard(matchID, match, player, cardType)
+		recordFantasyEvent(matchID, player, "yellow")
+		logWithFields(LevelInfo, matchLogFields(match, "events"), "🟨 YELLOW CARD! %s receives a yellow card", player.Name)
 
 		addLiveCommentary(matchID, match.Minute,
-			fmt.Sprintf("Yellow card shown to %s", player.Name),
+			fmt.Sprintf("Yellow card shown to %s for %s", player.Name, strings.ToLower(reason)),
 			EventCard, player)
 	}
 
+	cardEvent := &TimelineEvent{
+		Type:       EventCard,
+		TeamID:     player.TeamID,
+		PlayerID:   player.ID,
+		PlayerName: player.Name,
+		Card:       cardType,
+		Reason:     reason,
+	}
+	if news != nil {
+		cardEvent.newsID = news.ID
+	}
+	recordTimelineEvent(match, cardEvent)
+
 	// Update match stats
 	if isHomePlayer {
 		if cardType == "red" {
@@ -1269,12 +2288,16 @@ func handleCardEvent(matchID int, match *Match) {
 			matchStats[matchID].AwayYellowCards++
 		}
 	}
+
+	if cardType == "red" {
+		reviewRedCard(match, cardEvent)
+	}
 }
 
 // Player availability management
 func getAvailablePlayersForMatch(matchID int) []*Player {
 	var available []*Player
-	match := matches[matchID]
+	match := findMatch(matchID)
 	if match == nil {
 		return available
 	}
@@ -1321,6 +2344,14 @@ func getTeamName(teamID int) string {
 	return "Unknown"
 }
 
+// getTeamFullName is empty for players without a club
+func getTeamFullName(teamID int) string {
+	if team := teams[teamID]; team != nil {
+		return team.Name
+	}
+	return ""
+}
+
 // Match momentum management
 func updateMatchMomentum(matchID int, match *Match, eventType string, affectedTeamID int) {
 	if matchMomentum[matchID] == nil {
@@ -1394,7 +2425,7 @@ func recalculateMatchProbabilities(matchID int, match *Match) {
 	momentum := matchMomentum[matchID]
 
 	// Base probabilities from pre-match calculation
-	baseHomeWin, _, baseAwayWin := calculateMatchProbabilities(&match.HomeTeam, &match.AwayTeam)
+	baseHomeWin, _, baseAwayWin := calculateMatchProbabilities(match.ID, &match.HomeTeam, &match.AwayTeam, match.CrowdFactor)
 
 	// Adjust for current score
 	scoreDiff := match.HomeScore - match.AwayScore
@@ -1464,6 +2495,14 @@ func getPlayerByID(playerID int) *Player {
 	return players[playerID]
 }
 
+// findMatch resolves live and finished matches of the current season. Caller must hold mutex
+func findMatch(matchID int) *Match {
+	if match := matches[matchID]; match != nil {
+		return match
+	}
+	return finishedMatches[matchID]
+}
+
 func calculateNextGoalProbability(match *Match, isHome bool, momentum *MatchMomentum) float64 {
 	baseProb := 0.02 // 2% base chance per minute
 
@@ -1475,7 +2514,7 @@ func calculateNextGoalProbability(match *Match, isHome bool, momentum *MatchMome
 		team = &match.AwayTeam
 	}
 
-	attackStrength := calculateAttackStrength(team)
+	attackStrength := calculateAttackStrength(match.ID, team)
 	baseProb *= attackStrength * 2.0
 
 	// Adjust for momentum
@@ -1496,159 +2535,553 @@ func calculateNextGoalProbability(match *Match, isHome bool, momentum *MatchMome
 	return math.Max(0.001, math.Min(0.1, baseProb))
 }
 
-// Cooldown and next match creation
-func startCooldownAndCreateNext(finishedMatchID int) {
-	logInfo("⏳ Starting %d-second post-match break for match %d...", PostMatchBreakSeconds, finishedMatchID)
-	time.Sleep(PostMatchBreakSeconds * time.Second)
-
-	mutex.Lock()
-	// Move finished match to finishedMatches map instead of deleting
-	if match, exists := matches[finishedMatchID]; exists {
-		finishedMatches[finishedMatchID] = match
-		delete(matches, finishedMatchID)
-		logInfo("📦 Moved finished match %d to finished matches storage", finishedMatchID)
+// Odds service - prices derived from the dynamic match probabilities
+func initializeMatchOdds(matchID int, match *Match) {
+	matchOdds[matchID] = &MatchOdds{
+		MatchID:    matchID,
+		Margin:     bookmakerMargin,
+		Status:     OddsOpen,
+		Markets:    []*OddsMarket{},
+		LastUpdate: time.Now(),
 	}
-
-	// Create next match
-	logInfo("🆕 Creating next match after post-match break...")
-	createNextMatch()
-	mutex.Unlock()
+	oddsHistory[matchID] = []*OddsSnapshot{}
+	updateMatchOdds(matchID, match)
 }
 
-func createNextMatch() {
-	// Use separate mutex for scheduling to prevent race conditions
-	schedulingMutex.Lock()
-	defer schedulingMutex.Unlock()
-
-	// Get next scheduled match
-	scheduledMatch := getNextUnplayedMatch()
-	if scheduledMatch == nil {
-		log.Printf("⚠️  No more scheduled matches available")
+func updateMatchOdds(matchID int, match *Match) {
+	odds := matchOdds[matchID]
+	probs := dynamicProbabilities[matchID]
+	if odds == nil || probs == nil || odds.Status == OddsClosed {
 		return
 	}
 
-	// Double-check the match isn't already being played (race condition fix)
-	if scheduledMatch.IsPlayed {
-		log.Printf("⚠️  Match already marked as played: %s vs %s",
-			scheduledMatch.HomeTeam.ShortName, scheduledMatch.AwayTeam.ShortName)
-		return
+	// Lift an expired suspension
+	wasSuspended := odds.Status == OddsSuspended
+	if wasSuspended && odds.SuspendedUntil != nil && time.Now().After(*odds.SuspendedUntil) {
+		odds.Status = OddsOpen
+		odds.SuspensionReason = ""
+		odds.SuspendedUntil = nil
 	}
 
-	// Check if we've reached the maximum number of simultaneous matches for this league
-	leagueActiveCount := 0
-	for _, match := range matches {
-		if (match.Status == StatusLive || match.Status == StatusHalftime || match.Status == StatusBreak) &&
-			match.Competition == scheduledMatch.League {
-			leagueActiveCount++
+	// Expected remaining goals from the per-minute next goal probabilities
+	remaining := math.Max(0, float64(MatchDurationSeconds+match.InjuryTime-match.Minute))
+	homeLambda := probs.HomeNextGoalProb * remaining
+	awayLambda := probs.AwayNextGoalProb * remaining
+
+	previous := make(map[string]*OddsSelection)
+	for _, market := range odds.Markets {
+		for _, selection := range market.Selections {
+			previous[market.Type+"."+selection.Name] = selection
 		}
 	}
 
-	if leagueActiveCount >= MaxSimultaneousMatches {
-		log.Printf("⚠️  Maximum simultaneous matches reached for %s (%d), waiting for a match to finish",
-			scheduledMatch.League, MaxSimultaneousMatches)
-		return
+	markets := []*OddsMarket{
+		buildMatchResultMarket(match, probs),
+		buildOverUnderMarket(match, homeLambda, awayLambda),
+		buildBothTeamsToScoreMarket(match, homeLambda, awayLambda),
+		buildCorrectScoreMarket(match, homeLambda, awayLambda),
+		buildNextScorerMarket(matchID, match, homeLambda, awayLambda),
 	}
 
-	// Mark schedule as being played IMMEDIATELY to prevent race conditions
-	scheduledMatch.IsPlayed = true
-	matchCounter++
+	changed := wasSuspended != (odds.Status == OddsSuspended)
+	for _, market := range markets {
+		if odds.Status == OddsSuspended && market.Status == OddsOpen {
+			market.Status = OddsSuspended
+		}
+		for _, selection := range market.Selections {
+			selection.Odds = calculateOddsPrice(selection.Probability, odds.Margin)
+			selection.Movement = "STEADY"
+			selection.PreviousDecimal = selection.Odds.Decimal
+			if old := previous[market.Type+"."+selection.Name]; old != nil {
+				selection.PreviousDecimal = old.Odds.Decimal
+				if selection.Odds.Decimal > old.Odds.Decimal {
+					selection.Movement = "UP"
+				} else if selection.Odds.Decimal < old.Odds.Decimal {
+					selection.Movement = "DOWN"
+				}
+			}
+			if selection.Movement != "STEADY" && market.Type != MarketNextScorer && market.Type != MarketCorrectScore {
+				changed = true
+			}
+		}
+	}
 
-	// Calculate match probabilities based on team form
-	homeWin, draw, awayWin := calculateMatchProbabilities(scheduledMatch.HomeTeam, scheduledMatch.AwayTeam)
+	odds.Markets = markets
+	odds.LastUpdate = time.Now()
 
-	// Calculate attack strengths
-	homeAttackStrength := calculateAttackStrength(scheduledMatch.HomeTeam)
-	awayAttackStrength := calculateAttackStrength(scheduledMatch.AwayTeam)
+	if changed || len(oddsHistory[matchID]) == 0 {
+		recordOddsSnapshot(matchID, match, odds)
+	}
+}
 
-	// Generate random injury time (0-6 minutes)
-	injuryTime := rand.Intn(7) // 0-6 additional seconds (representing minutes)
+func buildMatchResultMarket(match *Match, probs *DynamicMatchProbabilities) *OddsMarket {
+	return &OddsMarket{
+		Type:   MarketMatchResult,
+		Name:   "Match Result",
+		Status: OddsOpen,
+		Selections: []*OddsSelection{
+			{Name: match.HomeTeam.Name, Probability: probs.HomeWinProbability},
+			{Name: "Draw", Probability: probs.DrawProbability},
+			{Name: match.AwayTeam.Name, Probability: probs.AwayWinProbability},
+		},
+	}
+}
 
-	// Create match from schedule
-	match := &Match{
-		ID:            matchCounter,
-		HomeTeam:      *scheduledMatch.HomeTeam,
-		AwayTeam:      *scheduledMatch.AwayTeam,
-		HomeScore:     0,
-		AwayScore:     0,
-		Minute:        0,
-		Status:        StatusLive,
-		Competition:   scheduledMatch.League,
-		Venue:         scheduledMatch.HomeTeam.Stadium,
-		Attendance:    rand.Intn(80000) + 20000,
-		Weather:       weatherConditions[rand.Intn(len(weatherConditions))],
-		Temperature:   rand.Intn(25) + 5,
-		HomeFormation: formations[rand.Intn(len(formations))],
-		AwayFormation: formations[rand.Intn(len(formations))],
-		Season:        currentSeason,
-		MatchweekNum:  scheduledMatch.Matchday,
-		StartTime:     time.Now(),
-		LastUpdate:    time.Now(),
-		PlayerRatings: make(map[int]float64),
-		// New fields for breaks and injury time
-		InjuryTime: injuryTime,
-		IsInBreak:  false,
+func buildOverUnderMarket(match *Match, homeLambda, awayLambda float64) *OddsMarket {
+	goalsNeeded := 3 - (match.HomeScore + match.AwayScore)
+	underProb := 0.0
+	for k := 0; k < goalsNeeded; k++ {
+		underProb += poissonProbability(k, homeLambda+awayLambda)
 	}
 
-	// Update schedule with match ID
-	scheduledMatch.MatchID = matchCounter
+	market := &OddsMarket{
+		Type:   MarketOverUnder,
+		Name:   "Over/Under 2.5 Goals",
+		Status: OddsOpen,
+		Selections: []*OddsSelection{
+			{Name: "Over 2.5", Probability: 1 - underProb},
+			{Name: "Under 2.5", Probability: underProb},
+		},
+	}
+	if goalsNeeded <= 0 {
+		market.Status = OddsClosed
+	}
+	return market
+}
 
-	matches[matchCounter] = match
-	matchStats[matchCounter] = generateInitialMatchStats(matchCounter)
-	liveCommentary[matchCounter] = []*LiveCommentary{}
-	playerLocations[matchCounter] = make(map[int]*PlayerLocation)
+func buildBothTeamsToScoreMarket(match *Match, homeLambda, awayLambda float64) *OddsMarket {
+	homeScores := 1.0
+	if match.HomeScore == 0 {
+		homeScores = 1 - math.Exp(-homeLambda)
+	}
+	awayScores := 1.0
+	if match.AwayScore == 0 {
+		awayScores = 1 - math.Exp(-awayLambda)
+	}
 
-	// Initialize enhanced simulation data
-	matchMomentum[matchCounter] = &MatchMomentum{
-		HomeTeamMomentum: 0.0,
-		AwayTeamMomentum: 0.0,
-		LastUpdateTime:   time.Now(),
+	market := &OddsMarket{
+		Type:   MarketBothTeamsToScore,
+		Name:   "Both Teams To Score",
+		Status: OddsOpen,
+		Selections: []*OddsSelection{
+			{Name: "Yes", Probability: homeScores * awayScores},
+			{Name: "No", Probability: 1 - homeScores*awayScores},
+		},
 	}
+	if match.HomeScore > 0 && match.AwayScore > 0 {
+		market.Status = OddsClosed
+	}
+	return market
+}
 
-	dynamicProbabilities[matchCounter] = &DynamicMatchProbabilities{
-		MatchID:             matchCounter,
-		HomeWinProbability:  homeWin,
-		DrawProbability:     draw,
-		AwayWinProbability:  awayWin,
-		NextGoalProbability: 0.02,
-		HomeNextGoalProb:    0.01,
-		AwayNextGoalProb:    0.01,
-		LastUpdate:          time.Now(),
-		Factors:             make(map[string]float64),
+func buildCorrectScoreMarket(match *Match, homeLambda, awayLambda float64) *OddsMarket {
+	market := &OddsMarket{
+		Type:       MarketCorrectScore,
+		Name:       "Correct Score",
+		Status:     OddsOpen,
+		Selections: []*OddsSelection{},
 	}
 
-	playerAvailability[matchCounter] = make(map[int]*PlayerAvailability)
+	// Scorelines reachable with up to 3 more goals per team
+	covered := 0.0
+	for home := 0; home <= 3; home++ {
+		for away := 0; away <= 3; away++ {
+			prob := poissonProbability(home, homeLambda) * poissonProbability(away, awayLambda)
+			covered += prob
+			market.Selections = append(market.Selections, &OddsSelection{
+				Name:        fmt.Sprintf("%d-%d", match.HomeScore+home, match.AwayScore+away),
+				Probability: prob,
+			})
+		}
+	}
+	market.Selections = append(market.Selections, &OddsSelection{
+		Name:        "Any Other Score",
+		Probability: math.Max(0, 1-covered),
+	})
 
-	// Add kickoff commentary with form and probability information
-	homeForm := fmt.Sprintf("Form: %v", scheduledMatch.HomeTeam.Form)
-	awayForm := fmt.Sprintf("Form: %v", scheduledMatch.AwayTeam.Form)
-	probabilityInfo := fmt.Sprintf("Match odds: Home %.1f%% Draw %.1f%% Away %.1f%%",
-		homeWin*100, draw*100, awayWin*100)
+	return market
+}
 
-	addLiveCommentary(matchCounter, 0,
-		fmt.Sprintf("⚽ Kickoff! %s (%s) vs %s (%s) at %s\n%s",
-			scheduledMatch.HomeTeam.Name, homeForm,
-			scheduledMatch.AwayTeam.Name, awayForm,
-			scheduledMatch.HomeTeam.Stadium,
-			probabilityInfo),
-		EventKickoff, nil)
+func buildNextScorerMarket(matchID int, match *Match, homeLambda, awayLambda float64) *OddsMarket {
+	market := &OddsMarket{
+		Type:       MarketNextScorer,
+		Name:       "Next Goalscorer",
+		Status:     OddsOpen,
+		Selections: []*OddsSelection{},
+	}
+
+	noGoalProb := math.Exp(-(homeLambda + awayLambda))
+	totalLambda := homeLambda + awayLambda
+	if totalLambda <= 0 {
+		market.Status = OddsClosed
+		market.Selections = append(market.Selections, &OddsSelection{Name: "No Goalscorer", Probability: 1})
+		return market
+	}
+
+	// Split each team's next goal share among players on the pitch
+	for _, teamID := range []int{match.HomeTeam.ID, match.AwayTeam.ID} {
+		teamShare := (1 - noGoalProb) * awayLambda / totalLambda
+		if teamID == match.HomeTeam.ID {
+			teamShare = (1 - noGoalProb) * homeLambda / totalLambda
+		}
+
+		var candidates []*Player
+		totalWeight := 0.0
+		for playerID := range playerLocations[matchID] {
+			player := players[playerID]
+			if player == nil || player.TeamID != teamID || !isPlayerAvailable(matchID, playerID) {
+				continue
+			}
+			candidates = append(candidates, player)
+			totalWeight += scoringWeight(player)
+		}
+
+		for _, player := range candidates {
+			market.Selections = append(market.Selections, &OddsSelection{
+				Name:        player.Name,
+				PlayerID:    player.ID,
+				Probability: teamShare * scoringWeight(player) / totalWeight,
+			})
+		}
+	}
+
+	sort.Slice(market.Selections, func(i, j int) bool {
+		return market.Selections[i].Probability > market.Selections[j].Probability
+	})
+	market.Selections = append(market.Selections, &OddsSelection{Name: "No Goalscorer", Probability: noGoalProb})
+
+	return market
+}
+
+// Relative likelihood of a player scoring, by position and shooting ability
+func scoringWeight(player *Player) float64 {
+	positionWeight := 1.0
+	switch player.Position {
+	case PosST:
+		positionWeight = 5.0
+	case PosCAM, PosLW, PosRW:
+		positionWeight = 3.0
+	case PosCM:
+		positionWeight = 1.5
+	case PosCDM, PosLB, PosRB:
+		positionWeight = 0.7
+	case PosCB:
+		positionWeight = 0.5
+	case PosGK:
+		positionWeight = 0.01
+	}
+	return positionWeight * float64(player.Characteristics.Shooting) / 100.0
+}
+
+func poissonProbability(k int, lambda float64) float64 {
+	if lambda <= 0 {
+		if k == 0 {
+			return 1
+		}
+		return 0
+	}
+	logProb := float64(k)*math.Log(lambda) - lambda
+	for i := 2; i <= k; i++ {
+		logProb -= math.Log(float64(i))
+	}
+	return math.Exp(logProb)
+}
+
+// Convert a fair probability into a price including the bookmaker margin
+func calculateOddsPrice(probability, margin float64) OddsPrice {
+	implied := math.Max(0.001, math.Min(0.99, probability*(1+margin)))
+	decimal := math.Max(1.01, math.Round(100/implied)/100)
+
+	return OddsPrice{
+		Decimal:    decimal,
+		Fractional: decimalToFractional(decimal),
+		American:   decimalToAmerican(decimal),
+	}
+}
+
+func decimalToFractional(decimal float64) string {
+	profit := decimal - 1
+
+	// Prefer the simplest bookmaker-style fraction within 3% of the true price
+	bestNum, bestDen := 1, 1
+	bestErr := math.Inf(1)
+	for _, den := range []int{1, 2, 3, 4, 5, 6, 8, 10, 11, 20, 25, 40, 50, 100} {
+		num := int(math.Round(profit * float64(den)))
+		if num < 1 {
+			continue
+		}
+		err := math.Abs(float64(num)/float64(den)-profit) / profit
+		if err < bestErr {
+			bestNum, bestDen, bestErr = num, den, err
+		}
+		if err <= 0.03 {
+			break
+		}
+	}
+
+	divisor := gcd(bestNum, bestDen)
+	return fmt.Sprintf("%d/%d", bestNum/divisor, bestDen/divisor)
+}
+
+func decimalToAmerican(decimal float64) string {
+	if decimal >= 2.0 {
+		return fmt.Sprintf("+%d", int(math.Round((decimal-1)*100)))
+	}
+	return fmt.Sprintf("-%d", int(math.Round(100/(decimal-1))))
+}
+
+func gcd(a, b int) int {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
+
+func suspendMatchOdds(matchID int, reason string, seconds int) {
+	odds := matchOdds[matchID]
+	if odds == nil || odds.Status == OddsClosed {
+		return
+	}
+
+	until := time.Now().Add(time.Duration(seconds) * time.Second)
+	if odds.SuspendedUntil != nil && odds.SuspendedUntil.After(until) {
+		until = *odds.SuspendedUntil
+	}
+
+	odds.Status = OddsSuspended
+	odds.SuspensionReason = reason
+	odds.SuspendedUntil = &until
+	for _, market := range odds.Markets {
+		if market.Status == OddsOpen {
+			market.Status = OddsSuspended
+		}
+	}
+
+	if match := matches[matchID]; match != nil {
+		recordOddsSnapshot(matchID, match, odds)
+	}
+	logWithFields(LevelDebug, LogFields{MatchID: matchID, Component: "odds"}, "⛔ Match %d: odds suspended (%s)", matchID, reason)
+}
+
+func closeMatchOdds(matchID int, match *Match) {
+	odds := matchOdds[matchID]
+	if odds == nil {
+		return
+	}
+
+	odds.Status = OddsClosed
+	odds.SuspensionReason = ""
+	odds.SuspendedUntil = nil
+	for _, market := range odds.Markets {
+		market.Status = OddsClosed
+	}
+	odds.LastUpdate = time.Now()
+	recordOddsSnapshot(matchID, match, odds)
+}
+
+func recordOddsSnapshot(matchID int, match *Match, odds *MatchOdds) {
+	prices := make(map[string]float64)
+	for _, market := range odds.Markets {
+		if market.Type == MarketNextScorer || market.Type == MarketCorrectScore {
+			continue
+		}
+		for i, selection := range market.Selections {
+			key := strings.ToLower(strings.NewReplacer(" ", "_", ".", "_").Replace(selection.Name))
+			if market.Type == MarketMatchResult {
+				key = []string{"home", "draw", "away"}[i]
+			}
+			prices[strings.ToLower(market.Type)+"."+key] = selection.Odds.Decimal
+		}
+	}
+
+	oddsHistory[matchID] = append(oddsHistory[matchID], &OddsSnapshot{
+		Minute:    match.Minute,
+		Status:    odds.Status,
+		Prices:    prices,
+		Timestamp: time.Now(),
+	})
+	if len(oddsHistory[matchID]) > MaxOddsHistory {
+		oddsHistory[matchID] = oddsHistory[matchID][1:]
+	}
+}
+
+// Cooldown and next match creation
+func startCooldownAndCreateNext(finishedMatchID int) {
+	logInfo("⏳ Starting %d-second post-match break for match %d...", PostMatchBreakSeconds, finishedMatchID)
+	time.Sleep(PostMatchBreakSeconds * time.Second)
+
+	lockMutex("post_match")
+	// Move finished match to finishedMatches map instead of deleting
+	if match, exists := matches[finishedMatchID]; exists {
+		finishedMatches[finishedMatchID] = match
+		delete(matches, finishedMatchID)
+		logInfo("📦 Moved finished match %d to finished matches storage", finishedMatchID)
+	}
+
+	// Create next match
+	logInfo("🆕 Creating next match after post-match break...")
+	createNextMatch()
+	mutex.Unlock()
+}
+
+func createNextMatch() {
+	// Use separate mutex for scheduling to prevent race conditions
+	schedulingMutex.Lock()
+	defer schedulingMutex.Unlock()
+
+	// Get next scheduled match
+	scheduledMatch := getNextUnplayedMatch()
+	if scheduledMatch == nil {
+		logWarn("⚠️  No more scheduled matches available")
+		return
+	}
+
+	// Double-check the match isn't already being played (race condition fix)
+	if scheduledMatch.IsPlayed {
+		logWarn("⚠️  Match already marked as played: %s vs %s",
+			scheduledMatch.HomeTeam.ShortName, scheduledMatch.AwayTeam.ShortName)
+		return
+	}
+
+	// Check if we've reached the maximum number of simultaneous matches for this league
+	leagueActiveCount := 0
+	for _, match := range matches {
+		if (match.Status == StatusLive || match.Status == StatusHalftime || match.Status == StatusBreak || match.Status == StatusVARCheck) &&
+			match.Competition == scheduledMatch.League {
+			leagueActiveCount++
+		}
+	}
+
+	if leagueActiveCount >= MaxSimultaneousMatches {
+		logDebug("⚠️  Maximum simultaneous matches reached for %s (%d), waiting for a match to finish",
+			scheduledMatch.League, MaxSimultaneousMatches)
+		return
+	}
+
+	// Mark schedule as being played IMMEDIATELY to prevent race conditions
+	scheduledMatch.IsPlayed = true
+	matchCounter++
+
+	// Crowd size depends on form, table position and rivalry
+	derby := derbyName(scheduledMatch.HomeTeam.ID, scheduledMatch.AwayTeam.ID)
+	attendance, occupancy := calculateAttendance(scheduledMatch.HomeTeam, scheduledMatch.AwayTeam, derby)
+	crowd := crowdFactor(scheduledMatch.HomeTeam.Capacity, occupancy, derby)
+	recordHomeAttendance(scheduledMatch.HomeTeam, attendance)
+
+	// Generate random injury time (0-6 minutes)
+	injuryTime := rand.Intn(7) // 0-6 additional seconds (representing minutes)
+
+	// Create match from schedule
+	match := &Match{
+		ID:            matchCounter,
+		HomeTeam:      *scheduledMatch.HomeTeam,
+		AwayTeam:      *scheduledMatch.AwayTeam,
+		HomeScore:     0,
+		AwayScore:     0,
+		Minute:        0,
+		Status:        StatusLive,
+		Competition:   scheduledMatch.League,
+		Venue:         scheduledMatch.HomeTeam.Stadium,
+		Attendance:    attendance,
+		Capacity:      scheduledMatch.HomeTeam.Capacity,
+		Occupancy:     math.Round(occupancy*1000) / 10,
+		CrowdFactor:   crowd,
+		Derby:         derby,
+		Weather:       weatherConditions[rand.Intn(len(weatherConditions))],
+		Temperature:   rand.Intn(30) + 3,
+		WindSpeed:     rand.Intn(30),
+		HomeFormation: formations[rand.Intn(len(formations))],
+		AwayFormation: formations[rand.Intn(len(formations))],
+		Season:        currentSeason,
+		MatchweekNum:  scheduledMatch.Matchday,
+		StartTime:     time.Now(),
+		LastUpdate:    time.Now(),
+		PlayerRatings: make(map[int]float64),
+		// New fields for breaks and injury time
+		InjuryTime: injuryTime,
+		IsInBreak:  false,
+	}
+
+	if referee := assignReferee(); referee != nil {
+		match.Referee = RefereeInfo{ID: referee.ID, Name: referee.Name, Strictness: referee.Strictness, Consistency: referee.Consistency}
+	}
+
+	// Update schedule with match ID
+	scheduledMatch.MatchID = matchCounter
+
+	// Kickoff closes predictions for this fixture
+	lockPredictions(scheduledMatch, matchCounter)
+
+	matches[matchCounter] = match
+	matchStats[matchCounter] = generateInitialMatchStats(matchCounter)
+	liveCommentary[matchCounter] = []*LiveCommentary{}
+	playerLocations[matchCounter] = make(map[int]*PlayerLocation)
+	startMatchLineups(match)
+	startMatchTactics(match)
+
+	// Calculate match probabilities from the starting lineups, form and the crowd
+	homeWin, draw, awayWin := calculateMatchProbabilities(matchCounter, &match.HomeTeam, &match.AwayTeam, crowd)
+
+	// Calculate attack strengths
+	homeAttackStrength := calculateAttackStrength(matchCounter, &match.HomeTeam)
+	awayAttackStrength := calculateAttackStrength(matchCounter, &match.AwayTeam)
+
+	// Initialize enhanced simulation data
+	matchMomentum[matchCounter] = &MatchMomentum{
+		HomeTeamMomentum: 0.0,
+		AwayTeamMomentum: 0.0,
+		LastUpdateTime:   time.Now(),
+	}
+
+	dynamicProbabilities[matchCounter] = &DynamicMatchProbabilities{
+		MatchID:             matchCounter,
+		HomeWinProbability:  homeWin,
+		DrawProbability:     draw,
+		AwayWinProbability:  awayWin,
+		NextGoalProbability: 0.02,
+		HomeNextGoalProb:    0.01,
+		AwayNextGoalProb:    0.01,
+		LastUpdate:          time.Now(),
+		Factors:             make(map[string]float64),
+	}
+
+	playerAvailability[matchCounter] = make(map[int]*PlayerAvailability)
+
+	// Open betting markets from the pre-match probabilities
+	initializeMatchOdds(matchCounter, match)
+
+	// Add kickoff commentary with form and probability information
+	homeForm := fmt.Sprintf("Form: %v", scheduledMatch.HomeTeam.Form)
+	awayForm := fmt.Sprintf("Form: %v", scheduledMatch.AwayTeam.Form)
+	probabilityInfo := fmt.Sprintf("Match odds: Home %.1f%% Draw %.1f%% Away %.1f%%",
+		homeWin*100, draw*100, awayWin*100)
+
+	addLiveCommentary(matchCounter, 0,
+		fmt.Sprintf("⚽ Kickoff! %s (%s) vs %s (%s) at %s\n%s",
+			scheduledMatch.HomeTeam.Name, homeForm,
+			scheduledMatch.AwayTeam.Name, awayForm,
+			scheduledMatch.HomeTeam.Stadium,
+			probabilityInfo),
+		EventKickoff, nil)
+	recordTimelineEvent(match, &TimelineEvent{Type: EventKickoff, Detail: "first half", Position: &PitchPosition{X: FieldWidth / 2, Y: FieldHeight / 2}})
 
-	logInfo("🆕 New match created: %s vs %s (ID: %d, League: %s, Matchday: %d, Injury Time: +%d min) - %s active matches: %d/%d",
+	logWithFields(LevelInfo, matchLogFields(match, "scheduler"), "🆕 New match created: %s vs %s (ID: %d, League: %s, Matchday: %d, Injury Time: +%d min) - %s active matches: %d/%d",
 		scheduledMatch.HomeTeam.ShortName, scheduledMatch.AwayTeam.ShortName,
 		matchCounter, scheduledMatch.League, scheduledMatch.Matchday, injuryTime,
 		scheduledMatch.League, leagueActiveCount+1, MaxSimultaneousMatches)
-	logInfo("🏟️  Venue: %s, Weather: %s, Temperature: %d°C",
+	logWithFields(LevelDebug, matchLogFields(match, "scheduler"), "🏟️  Venue: %s, Weather: %s, Temperature: %d°C",
 		scheduledMatch.HomeTeam.Stadium, match.Weather, match.Temperature)
-	logInfo("📋 Formations: %s (%s) vs %s (%s)",
+	logWithFields(LevelDebug, matchLogFields(match, "scheduler"), "📋 Formations: %s (%s) vs %s (%s)",
 		match.HomeFormation, scheduledMatch.HomeTeam.ShortName,
 		match.AwayFormation, scheduledMatch.AwayTeam.ShortName)
-	logInfo("📊 Form - Home: %v (Points: %d) | Away: %v (Points: %d)",
+	logWithFields(LevelDebug, matchLogFields(match, "scheduler"), "📊 Form - Home: %v (Points: %d) | Away: %v (Points: %d)",
 		scheduledMatch.HomeTeam.Form, scheduledMatch.HomeTeam.FormPoints,
 		scheduledMatch.AwayTeam.Form, scheduledMatch.AwayTeam.FormPoints)
-	logInfo("🎲 Match probabilities: Home %.1f%% Draw %.1f%% Away %.1f%%",
+	logWithFields(LevelDebug, matchLogFields(match, "scheduler"), "🎲 Match probabilities: Home %.1f%% Draw %.1f%% Away %.1f%%",
 		homeWin*100, draw*100, awayWin*100)
-	logInfo("⚔️  Attack strengths: Home %.2f | Away %.2f",
+	logWithFields(LevelDebug, matchLogFields(match, "scheduler"), "⚔️  Attack strengths: Home %.2f | Away %.2f",
 		homeAttackStrength, awayAttackStrength)
-	logInfo("🕐 Match %d started at %s (Expected duration: %d + %d minutes)",
+	logWithFields(LevelDebug, matchLogFields(match, "scheduler"), "🕐 Match %d started at %s (Expected duration: %d + %d minutes)",
 		matchCounter, match.StartTime.Format("15:04:05"), MatchDurationSeconds, injuryTime)
 }
 
@@ -1656,10 +3089,10 @@ func updateGlobalStatsLoop() {
 	logInfo("📊 Global stats updater started - updating every 5 seconds")
 	for {
 		time.Sleep(5 * time.Second)
-		mutex.Lock()
+		lockMutex("global_stats")
 		updateGlobalStats()
 		mutex.Unlock()
-		logInfo("📈 Global stats updated: %d matches, %d goals, %.1f avg goals, %d viewers",
+		logDebug("📈 Global stats updated: %d matches, %d goals, %.1f avg goals, %d viewers",
 			globalStats.TotalMatches, globalStats.TotalGoals, globalStats.AverageGoals, globalStats.ActiveViewers)
 	}
 }
@@ -1674,11 +3107,16 @@ func seasonManager(ctx context.Context) {
 		select {
 		case <-seasonTicker.C:
 			logInfo("🗓️  Season check: Season %d, Week %d", currentSeason, currentMatchweek)
+			// The season rollover (awards, development, retirements, transfers, new
+			// fixtures) runs as one step under the write lock so no match or request
+			// sees a half-finished season
+			lockMutex("season_manager")
 			if shouldEndSeason() {
 				logInfo("🏁 Ending season %d...", currentSeason)
 				endSeason()
 				startNewSeason()
 			}
+			mutex.Unlock()
 
 		case <-ctx.Done():
 			logInfo("🛑 Season manager stopped")
@@ -1709,9 +3147,33 @@ func statisticsProcessor(ctx context.Context) {
 
 // Implementation of actual functions
 func updateLeagueTable(match *Match) {
-	logInfo("📊 Updating league table after match %d", match.ID)
+	logWithFields(LevelDebug, matchLogFields(match, "season"), "📊 Updating league table after match %d", match.ID)
+
+	leaders := currentLeagueLeaders()
+
+	// Update team stats
+	if match.HomeScore > match.AwayScore {
+		// Home win
+		updateTeamStats(match.HomeTeam.ID, 3, 1, 0, 0, match) // 3 points, 1 win
+		updateTeamStats(match.AwayTeam.ID, 0, 0, 0, 1, match) // 0 points, 1 loss
+		logInfo("🏆 %s wins against %s", match.HomeTeam.ShortName, match.AwayTeam.ShortName)
+	} else if match.AwayScore > match.HomeScore {
+		// Away win
+		updateTeamStats(match.AwayTeam.ID, 3, 1, 0, 0, match)
+		updateTeamStats(match.HomeTeam.ID, 0, 0, 0, 1, match)
+		logInfo("🏆 %s wins against %s", match.AwayTeam.ShortName, match.HomeTeam.ShortName)
+	} else {
+		// Draw
+		updateTeamStats(match.HomeTeam.ID, 1, 0, 1, 0, match) // 1 point, 1 draw
+		updateTeamStats(match.AwayTeam.ID, 1, 0, 1, 0, match)
+		logInfo("🤝 Draw between %s and %s", match.HomeTeam.ShortName, match.AwayTeam.ShortName)
+	}
 
-	// Update season progress when match finishes
+	generateLeaderChangeNews(leaders, match)
+
+	// Update season progress once this result is in the table. The points must be
+	// added first: the last match of a season ends it from here, and the awards,
+	// archive and prize money read the final table
 	if match.Status == StatusFinished {
 		// Update matchweek only when all matches for current matchweek are finished
 		allMatchesFinished := true
@@ -1728,7 +3190,7 @@ func updateLeagueTable(match *Match) {
 
 		if allMatchesFinished {
 			currentMatchweek++
-			logInfo("📅 Advancing to matchweek %d", currentMatchweek)
+			logWithFields(LevelInfo, LogFields{League: match.Competition, Component: "season"}, "📅 Advancing to matchweek %d", currentMatchweek)
 
 			// Check if season should end after advancing matchweek
 			if shouldEndSeason() {
@@ -1738,24 +3200,6 @@ func updateLeagueTable(match *Match) {
 			}
 		}
 	}
-
-	// Update team stats
-	if match.HomeScore > match.AwayScore {
-		// Home win
-		updateTeamStats(match.HomeTeam.ID, 3, 1, 0, 0, match) // 3 points, 1 win
-		updateTeamStats(match.AwayTeam.ID, 0, 0, 0, 1, match) // 0 points, 1 loss
-		logInfo("🏆 %s wins against %s", match.HomeTeam.ShortName, match.AwayTeam.ShortName)
-	} else if match.AwayScore > match.HomeScore {
-		// Away win
-		updateTeamStats(match.AwayTeam.ID, 3, 1, 0, 0, match)
-		updateTeamStats(match.HomeTeam.ID, 0, 0, 0, 1, match)
-		logInfo("🏆 %s wins against %s", match.AwayTeam.ShortName, match.HomeTeam.ShortName)
-	} else {
-		// Draw
-		updateTeamStats(match.HomeTeam.ID, 1, 0, 1, 0, match) // 1 point, 1 draw
-		updateTeamStats(match.AwayTeam.ID, 1, 0, 1, 0, match)
-		logInfo("🤝 Draw between %s and %s", match.HomeTeam.ShortName, match.AwayTeam.ShortName)
-	}
 }
 
 func updateGlobalStats() {
@@ -1765,7 +3209,7 @@ func updateGlobalStats() {
 	topScorer := findTopScorer()
 
 	for id, match := range matches {
-		if match.Status == StatusLive {
+		if match.Status == StatusLive || match.Status == StatusVARCheck {
 			goals := match.HomeScore + match.AwayScore
 			totalGoals += goals
 			if goals > maxGoals {
@@ -1799,6 +3243,12 @@ func getAllMatches(w http.ResponseWriter, r *http.Request) {
 	league := r.URL.Query().Get("league")
 	teamIDStr := r.URL.Query().Get("team_id")
 
+	options, err := parseListOptions(r, "id")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	mutex.RLock()
 	matchList := make([]*Match, 0, len(matches)+len(finishedMatches))
 
@@ -1811,7 +3261,7 @@ func getAllMatches(w http.ResponseWriter, r *http.Request) {
 					return false
 				}
 			} else if status == "live" {
-				if match.Status != StatusLive {
+				if match.Status != StatusLive && match.Status != StatusVARCheck {
 					return false
 				}
 			} else if match.Status != status {
@@ -1843,177 +3293,480 @@ func getAllMatches(w http.ResponseWriter, r *http.Request) {
 			matchList = append(matchList, match)
 		}
 	}
+	writeListResponse(w, r, matchList, options, "id", nil)
 	mutex.RUnlock()
+}
 
-	// Sort matches by ID for consistent ordering
-	sort.Slice(matchList, func(i, j int) bool {
-		return matchList[i].ID < matchList[j].ID
-	})
-
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"matches":   matchList,
-		"count":     len(matchList),
-		"timestamp": time.Now(),
-	})
+// Shared pagination, sorting and sparse fieldsets for list endpoints
+type listOptions struct {
+	page   int
+	limit  int
+	cursor string
+	sort   []string // Field paths, "-" prefix for descending
+	fields []string // Field paths to keep, empty keeps everything
 }
 
-func getMatch(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	id, err := strconv.Atoi(vars["id"])
-	if err != nil {
-		http.Error(w, "Invalid match ID", http.StatusBadRequest)
-		return
+func parseListOptions(r *http.Request, defaultSort string) (*listOptions, error) {
+	query := r.URL.Query()
+	options := &listOptions{page: 1, limit: DefaultPageLimit, cursor: query.Get("cursor")}
+
+	if value := query.Get("page"); value != "" {
+		page, err := strconv.Atoi(value)
+		if err != nil || page < 1 {
+			return nil, fmt.Errorf("invalid page %q", value)
+		}
+		options.page = page
 	}
 
-	mutex.RLock()
-	match, exists := matches[id]
-	mutex.RUnlock()
+	if value := query.Get("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 1 {
+			return nil, fmt.Errorf("invalid limit %q", value)
+		}
+		options.limit = min(limit, MaxPageLimit)
+	}
 
-	if !exists {
-		http.Error(w, "Match not found", http.StatusNotFound)
-		return
+	sortValue := query.Get("sort")
+	if sortValue == "" {
+		sortValue = defaultSort
 	}
+	options.sort = splitListParam(sortValue)
+	options.fields = splitListParam(query.Get("fields"))
+	return options, nil
+}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(match)
+func splitListParam(value string) []string {
+	var parts []string
+	for _, part := range strings.Split(value, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return parts
 }
 
-func getMatchStats(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	id, err := strconv.Atoi(vars["id"])
-	if err != nil {
-		http.Error(w, "Invalid match ID", http.StatusBadRequest)
-		return
+// eachListField visits a struct's fields under their JSON names, following the rules of
+// encoding/json: "-" and unexported fields are skipped, omitempty fields are skipped when
+// empty, and fields of embedded structs are promoted unless a direct field shadows them.
+// Returning false from visit stops the walk
+func eachListField(value reflect.Value, visit func(name string, field reflect.Value) bool) bool {
+	seen := make(map[string]bool)
+	var promoted []reflect.Value
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		name, options, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		fieldValue := value.Field(i)
+		if field.Anonymous && name == "" {
+			if fieldValue.Kind() == reflect.Pointer {
+				if fieldValue.IsNil() {
+					continue
+				}
+				fieldValue = fieldValue.Elem()
+			}
+			if fieldValue.Kind() == reflect.Struct {
+				promoted = append(promoted, fieldValue)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		seen[name] = true
+		if strings.Contains(options, "omitempty") && isEmptyListValue(fieldValue) {
+			continue
+		}
+		if !visit(name, fieldValue) {
+			return false
+		}
 	}
 
-	mutex.RLock()
-	stats, exists := matchStats[id]
-	mutex.RUnlock()
+	for _, embedded := range promoted {
+		complete := eachListField(embedded, func(name string, field reflect.Value) bool {
+			return seen[name] || visit(name, field)
+		})
+		if !complete {
+			return false
+		}
+	}
+	return true
+}
 
-	if !exists {
-		http.Error(w, "Match stats not found", http.StatusNotFound)
-		return
+func isEmptyListValue(value reflect.Value) bool {
+	switch value.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return value.Len() == 0
+	case reflect.Bool:
+		return !value.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return value.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return value.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return value.Float() == 0
+	case reflect.Interface, reflect.Pointer:
+		return value.IsNil()
+	}
+	return false
+}
+
+// listItem copies a value into the form encoding/json would decode it to (objects,
+// arrays, float64, string, bool and nil), so fields can be selected from the copy
+// without touching the live structs. Call with the mutex held
+func listItem(value reflect.Value) interface{} {
+	switch value.Kind() {
+	case reflect.Invalid:
+		return nil
+	case reflect.Pointer, reflect.Interface:
+		if value.IsNil() {
+			return nil
+		}
+		return listItem(value.Elem())
+	case reflect.Struct:
+		if timestamp, ok := value.Interface().(time.Time); ok {
+			return timestamp.Format(time.RFC3339Nano)
+		}
+		object := make(map[string]interface{})
+		eachListField(value, func(name string, field reflect.Value) bool {
+			object[name] = listItem(field)
+			return true
+		})
+		return object
+	case reflect.Map:
+		if value.IsNil() {
+			return nil
+		}
+		object := make(map[string]interface{}, value.Len())
+		entries := value.MapRange()
+		for entries.Next() {
+			object[fmt.Sprint(entries.Key().Interface())] = listItem(entries.Value())
+		}
+		return object
+	case reflect.Slice, reflect.Array:
+		if value.Kind() == reflect.Slice && value.IsNil() {
+			return nil
+		}
+		array := make([]interface{}, value.Len())
+		for i := range array {
+			array[i] = listItem(value.Index(i))
+		}
+		return array
+	case reflect.Bool:
+		return value.Bool()
+	case reflect.String:
+		return value.String()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(value.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(value.Uint())
+	case reflect.Float32, reflect.Float64:
+		return value.Float()
 	}
+	return nil
+}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(stats)
+// Look up a dotted JSON path such as "characteristics.overall" on a list item
+func listValue(item reflect.Value, path string) (interface{}, bool) {
+	current := item
+	for _, key := range strings.Split(path, ".") {
+		for current.Kind() == reflect.Pointer || current.Kind() == reflect.Interface {
+			if current.IsNil() {
+				return nil, false
+			}
+			current = current.Elem()
+		}
+
+		switch current.Kind() {
+		case reflect.Struct:
+			found := false
+			eachListField(current, func(name string, field reflect.Value) bool {
+				if name == key {
+					current, found = field, true
+				}
+				return !found
+			})
+			if !found {
+				return nil, false
+			}
+		case reflect.Map:
+			if current.Type().Key().Kind() != reflect.String {
+				return nil, false
+			}
+			field := current.MapIndex(reflect.ValueOf(key).Convert(current.Type().Key()))
+			if !field.IsValid() {
+				return nil, false
+			}
+			current = field
+		default:
+			return nil, false
+		}
+	}
+	return listItem(current), true
 }
 
-func getMatchCommentary(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	id, err := strconv.Atoi(vars["id"])
-	if err != nil {
-		http.Error(w, "Invalid match ID", http.StatusBadRequest)
-		return
+// Order two values; missing values sort last in either direction
+func compareListValues(a, b interface{}) int {
+	switch x := a.(type) {
+	case float64:
+		if y, ok := b.(float64); ok {
+			switch {
+			case x < y:
+				return -1
+			case x > y:
+				return 1
+			}
+			return 0
+		}
+	case string:
+		if y, ok := b.(string); ok {
+			return strings.Compare(strings.ToLower(x), strings.ToLower(y))
+		}
+	case bool:
+		if y, ok := b.(bool); ok && x != y {
+			if !x {
+				return -1
+			}
+			return 1
+		}
+		return 0
 	}
+	return 0
+}
 
-	mutex.RLock()
-	commentary, exists := liveCommentary[id]
-	if !exists {
-		commentary = []*LiveCommentary{}
+// sortListItems returns the item indexes in sort order
+func sortListItems(items reflect.Value, fields []string) ([]int, error) {
+	type sortKey struct {
+		value interface{}
+		ok    bool
+	}
+	keys := make([][]sortKey, items.Len())
+	for i := range keys {
+		keys[i] = make([]sortKey, len(fields))
 	}
-	mutex.RUnlock()
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"commentary": commentary,
-		"match_id":   id,
-		"count":      len(commentary),
-		"timestamp":  time.Now(),
+	for f, field := range fields {
+		path := strings.TrimPrefix(field, "-")
+		found := items.Len() == 0
+		for i := range keys {
+			value, ok := listValue(items.Index(i), path)
+			keys[i][f] = sortKey{value, ok}
+			found = found || ok
+		}
+		if !found {
+			return nil, fmt.Errorf("unknown sort field %q", path)
+		}
+	}
+
+	order := make([]int, items.Len())
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		for f, field := range fields {
+			a, b := keys[order[i]][f], keys[order[j]][f]
+			if a.ok != b.ok || (a.value == nil) != (b.value == nil) {
+				return a.ok && a.value != nil
+			}
+			if result := compareListValues(a.value, b.value); result != 0 {
+				if strings.HasPrefix(field, "-") {
+					return result > 0
+				}
+				return result < 0
+			}
+		}
+		return false
 	})
+	return order, nil
 }
 
-func getMatchLocations(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	id, err := strconv.Atoi(vars["id"])
-	if err != nil {
-		http.Error(w, "Invalid match ID", http.StatusBadRequest)
-		return
+// Keep only the requested paths, rebuilding nested objects for dotted fields
+func selectListFields(item map[string]interface{}, fields []string) map[string]interface{} {
+	selected := make(map[string]interface{})
+	for _, field := range fields {
+		value, ok := listValue(reflect.ValueOf(item), field)
+		if !ok {
+			continue
+		}
+		keys := strings.Split(field, ".")
+		target := selected
+		for _, key := range keys[:len(keys)-1] {
+			next, exists := target[key].(map[string]interface{})
+			if !exists {
+				next = make(map[string]interface{})
+				target[key] = next
+			}
+			target = next
+		}
+		target[keys[len(keys)-1]] = value
 	}
+	return selected
+}
 
-	mutex.RLock()
-	match := matches[id]
-	if match == nil {
-		mutex.RUnlock()
-		http.Error(w, "Match not found", http.StatusNotFound)
-		return
+// Opaque cursor holding the identity of the last item on the previous page
+func encodeListCursor(item reflect.Value, idPath string) string {
+	value, ok := listValue(item, idPath)
+	if !ok {
+		return ""
 	}
+	return base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprint(value)))
+}
 
-	locations, exists := playerLocations[id]
-	if !exists {
-		locations = make(map[int]*PlayerLocation)
+func decodeListCursor(items reflect.Value, order []int, cursor, idPath string) (int, error) {
+	decoded, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, fmt.Errorf("invalid cursor")
+	}
+	for i, index := range order {
+		if value, ok := listValue(items.Index(index), idPath); ok && fmt.Sprint(value) == string(decoded) {
+			return i + 1, nil
+		}
+	}
+	return 0, fmt.Errorf("cursor no longer matches an item")
+}
+
+func listURL(r *http.Request, changes map[string]string) string {
+	query := r.URL.Query()
+	for key, value := range changes {
+		if value == "" {
+			query.Del(key)
+		} else {
+			query.Set(key, value)
+		}
 	}
+	return r.URL.EscapedPath() + "?" + query.Encode()
+}
 
-	// Get only the first 11 players from each team
-	homeTeamID := match.HomeTeam.ID
-	awayTeamID := match.AwayTeam.ID
+// Sort, paginate and trim a slice of items, then write the data/meta/links envelope and
+// Link header. Only the returned page is copied. Callers hold the mutex for the whole call,
+// encoding included
+func writeListResponse(w http.ResponseWriter, r *http.Request, list interface{}, options *listOptions,
+	idPath string, extraMeta map[string]interface{}) {
+	items := reflect.ValueOf(list)
+	order, err := sortListItems(items, options.sort)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 
-	homeCount := 0
-	awayCount := 0
-	locationList := make([]*PlayerLocation, 0, 22) // Pre-allocate for 22 players
+	total := items.Len()
+	pages := (total + options.limit - 1) / options.limit
+	start := min((options.page-1)*options.limit, total)
+	if options.cursor != "" {
+		position, err := decodeListCursor(items, order, options.cursor, idPath)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		start = position
+	}
+	end := min(start+options.limit, total)
 
-	for _, location := range locations {
-		if player, exists := players[location.PlayerID]; exists {
-			if player.TeamID == homeTeamID && homeCount < 11 {
-				locationList = append(locationList, location)
-				homeCount++
-			} else if player.TeamID == awayTeamID && awayCount < 11 {
-				locationList = append(locationList, location)
-				awayCount++
-			}
+	data := make([]map[string]interface{}, 0, end-start)
+	for _, index := range order[start:end] {
+		item, _ := listItem(items.Index(index)).(map[string]interface{})
+		if len(options.fields) > 0 {
+			item = selectListFields(item, options.fields)
 		}
+		data = append(data, item)
 	}
 
-	// Get ball position
-	ball := ballPositions[id]
-	mutex.RUnlock()
+	links := map[string]string{"self": listURL(r, nil)}
+	if options.cursor == "" {
+		links["first"] = listURL(r, map[string]string{"page": "1"})
+		links["last"] = listURL(r, map[string]string{"page": strconv.Itoa(max(pages, 1))})
+		if options.page > 1 && start > 0 {
+			links["prev"] = listURL(r, map[string]string{"page": strconv.Itoa(options.page - 1)})
+		}
+	}
+	nextCursor := ""
+	if end < total {
+		nextCursor = encodeListCursor(items.Index(order[end-1]), idPath)
+		if options.cursor != "" {
+			links["next"] = listURL(r, map[string]string{"cursor": nextCursor})
+		} else {
+			links["next"] = listURL(r, map[string]string{"page": strconv.Itoa(options.page + 1)})
+		}
+	}
 
-	response := map[string]interface{}{
-		"locations": locationList,
-		"match_id":  id,
-		"count":     len(locationList),
-		"timestamp": time.Now(),
+	var linkHeader []string
+	for _, rel := range []string{"first", "prev", "next", "last"} {
+		if link, exists := links[rel]; exists {
+			linkHeader = append(linkHeader, fmt.Sprintf("<%s>; rel=%q", link, rel))
+		}
 	}
+	if len(linkHeader) > 0 {
+		w.Header().Set("Link", strings.Join(linkHeader, ", "))
+	}
+	w.Header().Set("X-Total-Count", strconv.Itoa(total))
 
-	// Add ball position if it exists
-	if ball != nil {
-		response["ball"] = ball
+	meta := map[string]interface{}{
+		"total":       total,
+		"count":       len(data),
+		"page":        options.page,
+		"limit":       options.limit,
+		"pages":       pages,
+		"sort":        strings.Join(options.sort, ","),
+		"next_cursor": nextCursor,
+		"timestamp":   time.Now(),
+	}
+	if options.cursor != "" {
+		delete(meta, "page")
+	}
+	if len(options.fields) > 0 {
+		meta["fields"] = strings.Join(options.fields, ",")
+	}
+	for key, value := range extraMeta {
+		meta[key] = value
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(response)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"data":  data,
+		"meta":  meta,
+		"links": links,
+	})
 }
 
-func getMatchMomentum(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	id, err := strconv.Atoi(vars["id"])
-	if err != nil {
-		http.Error(w, "Invalid match ID", http.StatusBadRequest)
-		return
-	}
+// recordTimelineEvent stamps an event with its ID, minute and running score
+// and appends it to the match timeline. Caller must hold mutex
+func recordTimelineEvent(match *Match, event *TimelineEvent) {
+	timelineCounter++
+	event.ID = timelineCounter
+	event.MatchID = match.ID
+	event.Minute = match.Minute
+	event.HomeScore = match.HomeScore
+	event.AwayScore = match.AwayScore
+	event.Timestamp = time.Now()
 
-	mutex.RLock()
-	momentum := matchMomentum[id]
-	mutex.RUnlock()
+	matchTimelines[match.ID] = append(matchTimelines[match.ID], event)
+}
 
-	response := map[string]interface{}{
-		"match_id":  id,
-		"timestamp": time.Now(),
+func ballPitchPosition(ball *BallPosition) *PitchPosition {
+	if ball == nil {
+		return nil
 	}
+	return &PitchPosition{X: math.Round(ball.X*10) / 10, Y: math.Round(ball.Y*10) / 10}
+}
 
-	if momentum != nil {
-		response["momentum"] = momentum
-	} else {
-		response["momentum"] = nil
-		response["message"] = "No momentum data available for this match"
+func foulReason(context FoulContext) string {
+	switch {
+	case context.IsDangerousPlay:
+		return "Dangerous play"
+	case context.IsInPenaltyArea:
+		return "Foul in the penalty area"
+	case context.IsNearGoal:
+		return "Foul near goal"
+	default:
+		return "Foul"
 	}
-
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(response)
 }
 
-func getMatchProbabilities(w http.ResponseWriter, r *http.Request) {
+func getMatchTimeline(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id, err := strconv.Atoi(vars["id"])
 	if err != nil {
@@ -2021,437 +3774,2860 @@ func getMatchProbabilities(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	mutex.RLock()
-	probs := dynamicProbabilities[id]
-	match := matches[id]
-	mutex.RUnlock()
+	query := r.URL.Query()
+
+	sinceID := 0
+	if value := query.Get("since"); value != "" {
+		if sinceID, err = strconv.Atoi(value); err != nil || sinceID < 0 {
+			http.Error(w, "Invalid since, expected event ID", http.StatusBadRequest)
+			return
+		}
+	}
+
+	eventTypes := make(map[string]bool)
+	for _, eventType := range splitListParam(query.Get("type")) {
+		eventTypes[strings.ToUpper(eventType)] = true
+	}
 
+	mutex.RLock()
+	match := findMatch(id)
 	if match == nil {
+		mutex.RUnlock()
 		http.Error(w, "Match not found", http.StatusNotFound)
 		return
 	}
 
-	response := map[string]interface{}{
-		"match_id":  id,
-		"timestamp": time.Now(),
+	timeline := matchTimelines[id]
+	latestID := 0
+	if len(timeline) > 0 {
+		latestID = timeline[len(timeline)-1].ID
 	}
 
-	if probs != nil {
-		response["probabilities"] = probs
-	} else {
-		// Calculate initial probabilities if none exist
-		homeWin, draw, awayWin := calculateMatchProbabilities(&match.HomeTeam, &match.AwayTeam)
-
-		response["probabilities"] = map[string]interface{}{
-			"home_win_prob": homeWin,
-			"draw_prob":     draw,
-			"away_win_prob": awayWin,
-			"calculated_at": time.Now(),
-			"note":          "Initial pre-match probabilities",
+	events := make([]TimelineEvent, 0)
+	for _, event := range timeline {
+		if event.ID <= sinceID {
+			continue
 		}
+		if len(eventTypes) > 0 && !eventTypes[event.Type] {
+			continue
+		}
+		events = append(events, *event)
+	}
+
+	response := map[string]interface{}{
+		"match_id":   id,
+		"status":     match.Status,
+		"minute":     match.Minute,
+		"home_score": match.HomeScore,
+		"away_score": match.AwayScore,
+		"events":     events,
+		"count":      len(events),
+		"latest_id":  latestID,
+		"timestamp":  time.Now(),
 	}
+	mutex.RUnlock()
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(response)
 }
 
-func getMatchAvailability(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	id, err := strconv.Atoi(vars["id"])
-	if err != nil {
-		http.Error(w, "Invalid match ID", http.StatusBadRequest)
-		return
-	}
+// Formation slots in the order getFormationPositions lays them out
+var formationSlots = map[string][]string{
+	Formation442:  {PosGK, PosCB, PosCB, PosLB, PosRB, PosCM, PosCM, PosCM, PosCM, PosST, PosST},
+	Formation433:  {PosGK, PosCB, PosCB, PosCB, PosLB, PosRB, PosCM, PosCM, PosCM, PosLW, PosST},
+	Formation352:  {PosGK, PosCB, PosCB, PosCB, PosLB, PosCM, PosCM, PosCM, PosRB, PosST, PosST},
+	Formation4231: {PosGK, PosCB, PosCB, PosLB, PosRB, PosCDM, PosCDM, PosLW, PosCAM, PosRW, PosST},
+	Formation532:  {PosGK, PosCB, PosCB, PosCB, PosCB, PosCB, PosCM, PosCM, PosCM, PosST, PosST},
+}
 
-	mutex.RLock()
-	availability := playerAvailability[id]
-	match := matches[id]
-	mutex.RUnlock()
+// selectLineup picks the strongest XI for a formation: the best player in each slot's
+// position, then the best from the same line, then the best remaining outfielder
+func selectLineup(teamID int, formation string) []int {
+	squad := getPlayersFromTeam(teamID)
+	sort.Slice(squad, func(i, j int) bool {
+		if squad[i].Characteristics.Overall != squad[j].Characteristics.Overall {
+			return squad[i].Characteristics.Overall > squad[j].Characteristics.Overall
+		}
+		return squad[i].ID < squad[j].ID
+	})
 
-	if match == nil {
-		http.Error(w, "Match not found", http.StatusNotFound)
-		return
-	}
+	return fillLineup(squad, formation)
+}
 
-	// Get available and unavailable players
-	availablePlayers := getAvailablePlayersForMatch(id)
-	var unavailablePlayers []map[string]interface{}
+// fillLineup assigns players, strongest first, to a formation's slots in order.
+// With fewer players than slots the last (most attacking) slots stay empty
+func fillLineup(pool []*Player, formation string) []int {
+	slots, exists := formationSlots[formation]
+	if !exists {
+		slots = formationSlots[Formation442]
+	}
 
-	for _, avail := range availability {
-		if avail.Status != PlayerAvailable {
-			player := getPlayerByID(avail.PlayerID)
-			if player != nil {
-				unavailablePlayers = append(unavailablePlayers, map[string]interface{}{
-					"player":           player,
-					"status":           avail.Status,
-					"unavailable_from": avail.UnavailableFrom,
-					"reason":           avail.Reason,
-				})
+	used := make(map[int]bool)
+	pick := func(matches func(*Player) bool) int {
+		for _, player := range pool {
+			if !used[player.ID] && matches(player) {
+				used[player.ID] = true
+				return player.ID
 			}
 		}
+		return 0
 	}
 
-	response := map[string]interface{}{
-		"match_id":            id,
-		"available_count":     len(availablePlayers),
-		"unavailable_count":   len(unavailablePlayers),
-		"unavailable_players": unavailablePlayers,
-		"home_player_count":   11 - getRedCardCount(id, match.HomeTeam.ID),
-		"away_player_count":   11 - getRedCardCount(id, match.AwayTeam.ID),
-		"timestamp":           time.Now(),
+	lineup := make([]int, 0, len(slots))
+	for _, slot := range slots {
+		playerID := pick(func(p *Player) bool { return p.Position == slot })
+		if playerID == 0 {
+			playerID = pick(func(p *Player) bool { return fantasyPositionGroup(p.Position) == fantasyPositionGroup(slot) })
+		}
+		if playerID == 0 {
+			playerID = pick(func(p *Player) bool { return slot == PosGK || p.Position != PosGK })
+		}
+		if playerID != 0 {
+			lineup = append(lineup, playerID)
+		}
 	}
-
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(response)
+	return lineup
 }
 
-func getSeasonHistory(w http.ResponseWriter, r *http.Request) {
-	mutex.RLock()
-	history := make([]SeasonHistory, len(seasonHistory))
-	copy(history, seasonHistory)
-	mutex.RUnlock()
+// startMatchLineups names both XIs at kickoff and opens their stat lines. Caller must hold mutex
+func startMatchLineups(match *Match) {
+	matchLineups[match.ID] = map[int][]int{
+		match.HomeTeam.ID: selectLineup(match.HomeTeam.ID, match.HomeFormation),
+		match.AwayTeam.ID: selectLineup(match.AwayTeam.ID, match.AwayFormation),
+	}
+	matchPlayerStats[match.ID] = make(map[int]*MatchPlayerStats)
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"history":   history,
-		"count":     len(history),
-		"timestamp": time.Now(),
-	})
+	for teamID, lineup := range matchLineups[match.ID] {
+		for _, playerID := range lineup {
+			player := players[playerID]
+			matchPlayerStats[match.ID][playerID] = &MatchPlayerStats{
+				MatchID:    match.ID,
+				PlayerID:   playerID,
+				PlayerName: player.Name,
+				TeamID:     teamID,
+				Position:   player.Position,
+				Rating:     6.0,
+				Stamina:    100,
+				LastUpdate: time.Now(),
+			}
+		}
+	}
 }
 
-func getSeasonStats(w http.ResponseWriter, r *http.Request) {
-	mutex.RLock()
-	stats := map[string]interface{}{
-		"current_season":    currentSeason,
-		"current_matchweek": currentMatchweek,
-		"season_progress":   float64(currentMatchweek) / float64(SeasonMatches) * 100,
-		"total_seasons":     len(seasonHistory),
-		"timestamp":         time.Now(),
+// arrangeLineup refits the players still on the pitch to a new formation, keeping
+// sent-off players at the end so their stat lines stay with the team. Caller must hold mutex
+func arrangeLineup(matchID, teamID int, formation string) {
+	onPitch := getMatchLineup(matchID, teamID)
+	sort.SliceStable(onPitch, func(i, j int) bool {
+		return onPitch[i].Characteristics.Overall > onPitch[j].Characteristics.Overall
+	})
+
+	arranged := fillLineup(onPitch, formation)
+	for _, playerID := range matchLineups[matchID][teamID] {
+		if !isPlayerAvailable(matchID, playerID) {
+			arranged = append(arranged, playerID)
+		}
 	}
-	mutex.RUnlock()
+	matchLineups[matchID][teamID] = arranged
+}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(stats)
+// getMatchLineup returns the players of a team still on the pitch. Caller must hold mutex
+func getMatchLineup(matchID, teamID int) []*Player {
+	var lineup []*Player
+	for _, playerID := range matchLineups[matchID][teamID] {
+		if player := players[playerID]; player != nil && isPlayerAvailable(matchID, playerID) {
+			lineup = append(lineup, player)
+		}
+	}
+	return lineup
 }
 
-func getLeagueTable(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	league := vars["league"]
+// matchPlayerLine returns a player's stat line, or nil if they did not start
+func matchPlayerLine(matchID int, player *Player) *MatchPlayerStats {
+	if player == nil {
+		return nil
+	}
+	return matchPlayerStats[matchID][player.ID]
+}
 
-	mutex.RLock()
-	table, exists := leagueTables[league]
-	mutex.RUnlock()
+func recordShot(matchID int, match *Match, shooter *Player, onTarget bool) {
+	if line := matchPlayerLine(matchID, shooter); line != nil {
+		line.Shots++
+		if onTarget {
+			line.ShotsOnTarget++
+		}
+	}
 
-	if !exists {
-		http.Error(w, "League not found", http.StatusNotFound)
+	stats := matchStats[matchID]
+	if stats == nil {
 		return
 	}
+	if shooter.TeamID == match.HomeTeam.ID {
+		stats.HomeShots++
+		if onTarget {
+			stats.HomeShotsOnTarget++
+		}
+	} else {
+		stats.AwayShots++
+		if onTarget {
+			stats.AwayShotsOnTarget++
+		}
+	}
+}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"table":     table,
-		"league":    league,
-		"timestamp": time.Now(),
-	})
+func recordCard(matchID int, match *Match, player *Player, cardType string) {
+	if referee := matchReferee(match); referee != nil {
+		if cardType == "red" {
+			referee.RedCards++
+		} else {
+			referee.YellowCards++
+		}
+	}
+
+	line := matchPlayerLine(matchID, player)
+	if line == nil {
+		return
+	}
+	if cardType == "red" {
+		line.RedCards++
+		line.SentOffMinute = max(1, match.Minute)
+		delete(playerLocations[matchID], player.ID)
+	} else {
+		line.YellowCards++
+	}
 }
 
-func getAllPlayers(w http.ResponseWriter, r *http.Request) {
-	teamIDStr := r.URL.Query().Get("team_id")
-	position := r.URL.Query().Get("position")
-	search := r.URL.Query().Get("search")
+// trackBallTouch counts a touch whenever a new player takes the ball and settles any pass in flight
+func trackBallTouch(matchID int, ball *BallPosition) {
+	if ball.PossessorID == 0 || ball.PossessorID == ball.touchPlayerID {
+		return
+	}
+	receiver := players[ball.PossessorID]
+	ball.touchPlayerID = ball.PossessorID
 
-	mutex.RLock()
-	var playerList []*Player
-	for _, player := range players {
-		if teamIDStr != "" {
-			teamID, err := strconv.Atoi(teamIDStr)
-			if err == nil && player.TeamID != teamID {
-				continue
+	if line := matchPlayerLine(matchID, receiver); line != nil {
+		line.Touches++
+	}
+
+	if ball.passerID != 0 {
+		passer := players[ball.passerID]
+		if passer != nil && receiver != nil && passer.TeamID == receiver.TeamID && passer.ID != receiver.ID {
+			if line := matchPlayerLine(matchID, passer); line != nil {
+				line.PassesCompleted++
 			}
+			recordPassCompletion(matchID, passer.ID, ball.passTargetID, receiver.ID)
 		}
+		ball.passerID, ball.passTargetID = 0, 0
+	}
+}
 
-		if position != "" && player.Position != position {
-			continue
+// refreshMatchPlayerStats brings minutes and live ratings up to date. Caller must hold mutex
+func refreshMatchPlayerStats(matchID int, match *Match) {
+	for _, line := range matchPlayerStats[matchID] {
+		lastMinute := min(match.Minute, MatchDurationSeconds)
+		if line.SentOffMinute > 0 {
+			lastMinute = line.SentOffMinute
+		}
+		if line.SubbedOffMinute > 0 {
+			lastMinute = line.SubbedOffMinute
+		}
+		line.Minutes = max(0, lastMinute-line.SubbedOnMinute)
+		if line.Passes > 0 {
+			line.PassAccuracy = math.Round(float64(line.PassesCompleted)/float64(line.Passes)*1000) / 10
 		}
+		line.Rating = calculateMatchRating(line, match)
+		line.LastUpdate = time.Now()
 
-		if search != "" && !strings.Contains(strings.ToLower(player.Name), strings.ToLower(search)) {
-			continue
+		if player := players[line.PlayerID]; player != nil {
+			player.CurrentRating = line.Rating
 		}
+	}
+}
 
-		playerList = append(playerList, player)
+// calculateMatchRating scores a player from this match's numbers only
+func calculateMatchRating(line *MatchPlayerStats, match *Match) float64 {
+	rating := 6.0
+
+	rating += float64(line.Goals) * 1.0
+	rating += float64(line.Assists) * 0.6
+	rating += float64(line.ShotsOnTarget-line.Goals) * 0.1
+	rating += math.Min(0.5, float64(line.Touches)*0.02)
+	if line.Passes >= 3 {
+		rating += (float64(line.PassesCompleted)/float64(line.Passes) - 0.75) * 2
 	}
-	mutex.RUnlock()
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"players":   playerList,
-		"count":     len(playerList),
-		"timestamp": time.Now(),
-	})
+	rating -= float64(line.Fouls) * 0.1
+	rating -= float64(line.YellowCards) * 0.3
+	rating -= float64(line.RedCards) * 1.5
+
+	scored, conceded := match.HomeScore, match.AwayScore
+	if line.TeamID == match.AwayTeam.ID {
+		scored, conceded = conceded, scored
+	}
+
+	switch fantasyPositionGroup(line.Position) {
+	case "GK":
+		if conceded == 0 && line.Minutes >= 60 {
+			rating += 1.0
+		}
+		rating -= float64(conceded) * 0.3
+	case "DEF":
+		if conceded == 0 && line.Minutes >= 60 {
+			rating += 0.7
+		}
+		rating -= float64(conceded) * 0.2
+	}
+
+	if scored > conceded {
+		rating += 0.3
+	} else if scored < conceded {
+		rating -= 0.3
+	}
+
+	return math.Round(math.Max(3.0, math.Min(10.0, rating))*10) / 10
 }
 
-func getPlayer(w http.ResponseWriter, r *http.Request) {
+func getMatchPlayerStats(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id, err := strconv.Atoi(vars["id"])
 	if err != nil {
-		http.Error(w, "Invalid player ID", http.StatusBadRequest)
+		http.Error(w, "Invalid match ID", http.StatusBadRequest)
 		return
 	}
 
-	mutex.RLock()
-	player, exists := players[id]
-	mutex.RUnlock()
+	teamID := 0
+	if value := r.URL.Query().Get("team_id"); value != "" {
+		if teamID, err = strconv.Atoi(value); err != nil {
+			http.Error(w, "Invalid team ID", http.StatusBadRequest)
+			return
+		}
+	}
 
-	if !exists {
-		http.Error(w, "Player not found", http.StatusNotFound)
+	mutex.RLock()
+	match := findMatch(id)
+	if match == nil {
+		mutex.RUnlock()
+		http.Error(w, "Match not found", http.StatusNotFound)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(player)
-}
-
-func getAllTeams(w http.ResponseWriter, r *http.Request) {
-	league := r.URL.Query().Get("league")
-
-	mutex.RLock()
-	var teamList []*TeamInfo
-	for _, team := range teams {
-		if league == "" || team.League == league {
-			teamList = append(teamList, team)
+	lines := make([]MatchPlayerStats, 0, len(matchPlayerStats[id]))
+	for _, line := range matchPlayerStats[id] {
+		if teamID != 0 && line.TeamID != teamID {
+			continue
 		}
+		lines = append(lines, *line)
 	}
+	homeTeamID := match.HomeTeam.ID
+	status := match.Status
+	minute := match.Minute
 	mutex.RUnlock()
 
+	// Home side first, then best rated
+	sort.Slice(lines, func(i, j int) bool {
+		if (lines[i].TeamID == homeTeamID) != (lines[j].TeamID == homeTeamID) {
+			return lines[i].TeamID == homeTeamID
+		}
+		if lines[i].Rating != lines[j].Rating {
+			return lines[i].Rating > lines[j].Rating
+		}
+		return lines[i].PlayerID < lines[j].PlayerID
+	})
+
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]interface{}{
-		"teams":     teamList,
-		"count":     len(teamList),
+		"match_id":  id,
+		"status":    status,
+		"minute":    minute,
+		"players":   lines,
+		"count":     len(lines),
 		"timestamp": time.Now(),
 	})
 }
 
-func getTeam(w http.ResponseWriter, r *http.Request) {
+func getPlayerMatches(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id, err := strconv.Atoi(vars["id"])
 	if err != nil {
-		http.Error(w, "Invalid team ID", http.StatusBadRequest)
+		http.Error(w, "Invalid player ID", http.StatusBadRequest)
 		return
 	}
 
-	mutex.RLock()
-	team, exists := teams[id]
-	mutex.RUnlock()
+	options, err := parseListOptions(r, "-match_id")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 
+	mutex.RLock()
+	player, exists := players[id]
 	if !exists {
-		http.Error(w, "Team not found", http.StatusNotFound)
+		player, exists = retiredPlayers[id]
+	}
+	if !exists {
+		mutex.RUnlock()
+		http.Error(w, "Player not found", http.StatusNotFound)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(team)
+	type gameLogEntry struct {
+		MatchPlayerStats
+		Season      int       `json:"season"`
+		Matchweek   int       `json:"matchweek"`
+		Competition string    `json:"competition"`
+		Status      string    `json:"status"`
+		Home        bool      `json:"home"`
+		Opponent    string    `json:"opponent"`
+		OpponentID  int       `json:"opponent_id"`
+		Score       string    `json:"score"`
+		Result      string    `json:"result,omitempty"`
+		StartTime   time.Time `json:"start_time"`
+	}
+
+	games := make([]gameLogEntry, 0)
+	minutes, goals, assists, totalRating := 0, 0, 0, 0.0
+	for _, matchMap := range []map[int]*Match{matches, finishedMatches} {
+		for matchID, match := range matchMap {
+			line := matchPlayerStats[matchID][id]
+			if line == nil {
+				continue
+			}
+
+			entry := gameLogEntry{
+				MatchPlayerStats: *line,
+				Season:           match.Season,
+				Matchweek:        match.MatchweekNum,
+				Competition:      match.Competition,
+				Status:           match.Status,
+				Home:             line.TeamID == match.HomeTeam.ID,
+				Score:            fmt.Sprintf("%d-%d", match.HomeScore, match.AwayScore),
+				StartTime:        match.StartTime,
+			}
+			scored, conceded := match.HomeScore, match.AwayScore
+			if entry.Home {
+				entry.Opponent, entry.OpponentID = match.AwayTeam.Name, match.AwayTeam.ID
+			} else {
+				entry.Opponent, entry.OpponentID = match.HomeTeam.Name, match.HomeTeam.ID
+				scored, conceded = conceded, scored
+			}
+			if match.Status == StatusFinished {
+				switch {
+				case scored > conceded:
+					entry.Result = "W"
+				case scored < conceded:
+					entry.Result = "L"
+				default:
+					entry.Result = "D"
+				}
+			}
+
+			games = append(games, entry)
+			minutes += line.Minutes
+			goals += line.Goals
+			assists += line.Assists
+			totalRating += line.Rating
+		}
+	}
+	defer mutex.RUnlock()
+
+	averageRating := 0.0
+	if len(games) > 0 {
+		averageRating = math.Round(totalRating/float64(len(games))*100) / 100
+	}
+
+	writeListResponse(w, r, games, options, "match_id", map[string]interface{}{
+		"player_id":   id,
+		"player_name": player.Name,
+		"totals": map[string]interface{}{
+			"appearances":    len(games),
+			"minutes":        minutes,
+			"goals":          goals,
+			"assists":        assists,
+			"average_rating": averageRating,
+		},
+	})
 }
 
-func getGlobalStats(w http.ResponseWriter, r *http.Request) {
-	mutex.RLock()
-	stats := *globalStats
-	mutex.RUnlock()
+// recordTrackingFrame keeps this tick's ball and player positions. Caller must hold mutex
+func recordTrackingFrame(matchID int, match *Match, ball *BallPosition) {
+	frame := &TrackingFrame{
+		Minute:    match.Minute,
+		Ball:      *ballPitchPosition(ball),
+		Players:   make(map[int]PitchPosition, len(playerLocations[matchID])),
+		Timestamp: time.Now(),
+	}
+	for playerID, location := range playerLocations[matchID] {
+		frame.Players[playerID] = PitchPosition{X: math.Round(location.X*10) / 10, Y: math.Round(location.Y*10) / 10}
+	}
+	matchTracking[matchID] = append(matchTracking[matchID], frame)
+}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(stats)
+// trackingTeams maps each tracked player to their side for the match. Caller must hold mutex
+func trackingTeams(matchID int) map[int]int {
+	teamOf := make(map[int]int)
+	for teamID, lineup := range matchLineups[matchID] {
+		for _, playerID := range lineup {
+			teamOf[playerID] = teamID
+		}
+	}
+	return teamOf
 }
 
-func searchAPI(w http.ResponseWriter, r *http.Request) {
-	query := r.URL.Query().Get("q")
-	if query == "" {
-		http.Error(w, "Search query required", http.StatusBadRequest)
+func parseTrackingFilters(r *http.Request) (playerID, teamID int, err error) {
+	query := r.URL.Query()
+	if value := query.Get("player_id"); value != "" {
+		if playerID, err = strconv.Atoi(value); err != nil {
+			return 0, 0, fmt.Errorf("invalid player ID")
+		}
+	}
+	if value := query.Get("team_id"); value != "" {
+		if teamID, err = strconv.Atoi(value); err != nil {
+			return 0, 0, fmt.Errorf("invalid team ID")
+		}
+	}
+	return playerID, teamID, nil
+}
+
+func getMatchHeatmap(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		http.Error(w, "Invalid match ID", http.StatusBadRequest)
 		return
 	}
 
-	query = strings.ToLower(query)
+	playerID, teamID, err := parseTrackingFilters(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	ballOnly := r.URL.Query().Get("ball") == "true"
 
-	mutex.RLock()
+	cols, rows := DefaultHeatmapColumns, DefaultHeatmapRows
+	for param, target := range map[string]*int{"cols": &cols, "rows": &rows} {
+		if value := r.URL.Query().Get(param); value != "" {
+			size, err := strconv.Atoi(value)
+			if err != nil || size < 1 || size > MaxHeatmapCells {
+				http.Error(w, fmt.Sprintf("Invalid %s, expected 1-%d", param, MaxHeatmapCells), http.StatusBadRequest)
+				return
+			}
+			*target = size
+		}
+	}
 
-	var results = struct {
-		Players []*Player   `json:"players"`
-		Teams   []*TeamInfo `json:"teams"`
-		Count   int         `json:"count"`
-	}{
-		Players: []*Player{},
-		Teams:   []*TeamInfo{},
+	grid := make([][]int, rows)
+	for i := range grid {
+		grid[i] = make([]int, cols)
+	}
+	addSample := func(position PitchPosition) {
+		col := min(int(position.X/FieldWidth*float64(cols)), cols-1)
+		row := min(int(position.Y/FieldHeight*float64(rows)), rows-1)
+		grid[row][col]++
 	}
 
-	for _, player := range players {
-		if strings.Contains(strings.ToLower(player.Name), query) ||
-			strings.Contains(strings.ToLower(player.Position), query) ||
-			strings.Contains(strings.ToLower(player.Nationality), query) {
-			results.Players = append(results.Players, player)
-		}
+	mutex.RLock()
+	if findMatch(id) == nil {
+		mutex.RUnlock()
+		http.Error(w, "Match not found", http.StatusNotFound)
+		return
 	}
 
-	for _, team := range teams {
-		if strings.Contains(strings.ToLower(team.Name), query) ||
-			strings.Contains(strings.ToLower(team.League), query) ||
-			strings.Contains(strings.ToLower(team.Manager), query) {
-			results.Teams = append(results.Teams, team)
+	teamOf := trackingTeams(id)
+	frames := matchTracking[id]
+	samples := 0
+	for _, frame := range frames {
+		if ballOnly {
+			addSample(frame.Ball)
+			samples++
+			continue
+		}
+		for pid, position := range frame.Players {
+			if (playerID != 0 && pid != playerID) || (teamID != 0 && teamOf[pid] != teamID) {
+				continue
+			}
+			addSample(position)
+			samples++
 		}
 	}
-
 	mutex.RUnlock()
 
-	results.Count = len(results.Players) + len(results.Teams)
+	maxCount := 0
+	for _, row := range grid {
+		for _, count := range row {
+			maxCount = max(maxCount, count)
+		}
+	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(results)
-}
-
-// For goroutine monitoring - can be hooked to a grafana dashboard
-func getGoroutineStats() map[string]interface{} {
-	numGoroutines := runtime.NumGoroutine()
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"match_id":    id,
+		"player_id":   playerID,
+		"team_id":     teamID,
+		"ball":        ballOnly,
+		"columns":     cols,
+		"rows":        rows,
+		"cell_width":  FieldWidth / float64(cols),
+		"cell_height": FieldHeight / float64(rows),
+		"grid":        grid,
+		"max":         maxCount,
+		"samples":     samples,
+		"frames":      len(frames),
+		"timestamp":   time.Now(),
+	})
+}
 
-	// Get memory stats
-	var memStats runtime.MemStats
-	runtime.ReadMemStats(&memStats)
+// computeAveragePositions averages each tracked player's positions, optionally for one side. Caller must hold mutex
+func computeAveragePositions(matchID, teamID int) map[int]*AveragePosition {
+	teamOf := trackingTeams(matchID)
+	averages := make(map[int]*AveragePosition)
+	for _, frame := range matchTracking[matchID] {
+		for pid, position := range frame.Players {
+			if teamID != 0 && teamOf[pid] != teamID {
+				continue
+			}
+			average := averages[pid]
+			if average == nil {
+				average = &AveragePosition{PlayerID: pid, TeamID: teamOf[pid]}
+				if line := matchPlayerStats[matchID][pid]; line != nil {
+					average.PlayerName, average.Position = line.PlayerName, line.Position
+				}
+				averages[pid] = average
+			}
+			average.X += position.X
+			average.Y += position.Y
+			average.Samples++
+		}
+	}
 
-	return map[string]interface{}{
-		"goroutine_count": numGoroutines,
-		"memory_alloc":    memStats.Alloc,
-		"memory_total":    memStats.TotalAlloc,
-		"memory_sys":      memStats.Sys,
-		"num_gc":          memStats.NumGC,
-		"status":          getGoroutineStatus(numGoroutines),
+	for _, average := range averages {
+		average.X = math.Round(average.X/float64(average.Samples)*10) / 10
+		average.Y = math.Round(average.Y/float64(average.Samples)*10) / 10
 	}
+	return averages
 }
 
-func getGoroutineStatus(count int) string {
-	if count < 50 {
-		return "healthy"
-	} else if count < 100 {
-		return "warning"
-	} else {
-		return "critical"
+func getMatchAveragePositions(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		http.Error(w, "Invalid match ID", http.StatusBadRequest)
+		return
+	}
+
+	_, teamID, err := parseTrackingFilters(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	mutex.RLock()
+	match := findMatch(id)
+	if match == nil {
+		mutex.RUnlock()
+		http.Error(w, "Match not found", http.StatusNotFound)
+		return
+	}
+	averages := computeAveragePositions(id, teamID)
+	homeTeamID := match.HomeTeam.ID
+	mutex.RUnlock()
+
+	positions := make([]AveragePosition, 0, len(averages))
+	for _, average := range averages {
+		positions = append(positions, *average)
 	}
+	sort.Slice(positions, func(i, j int) bool {
+		if (positions[i].TeamID == homeTeamID) != (positions[j].TeamID == homeTeamID) {
+			return positions[i].TeamID == homeTeamID
+		}
+		return positions[i].PlayerID < positions[j].PlayerID
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"match_id":  id,
+		"positions": positions,
+		"count":     len(positions),
+		"timestamp": time.Now(),
+	})
 }
 
-func healthCheck(w http.ResponseWriter, r *http.Request) {
+func getMatchTrails(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		http.Error(w, "Invalid match ID", http.StatusBadRequest)
+		return
+	}
+
+	playerID, teamID, err := parseTrackingFilters(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	type trailPoint struct {
+		Minute    int       `json:"minute"`
+		X         float64   `json:"x"`
+		Y         float64   `json:"y"`
+		Timestamp time.Time `json:"timestamp"`
+	}
+
 	mutex.RLock()
-	matchCount := len(matches)
-	playerCount := len(players)
-	teamCount := len(teams)
+	match := findMatch(id)
+	if match == nil {
+		mutex.RUnlock()
+		http.Error(w, "Match not found", http.StatusNotFound)
+		return
+	}
 
-	// Get goroutine stats
-	goroutineStats := getGoroutineStats()
+	// Default window is the last DefaultTrailMinutes of play
+	to := match.Minute
+	from := max(0, to-DefaultTrailMinutes)
+	for param, target := range map[string]*int{"from": &from, "to": &to} {
+		if value := r.URL.Query().Get(param); value != "" {
+			minute, err := strconv.Atoi(value)
+			if err != nil || minute < 0 {
+				mutex.RUnlock()
+				http.Error(w, fmt.Sprintf("Invalid %s minute", param), http.StatusBadRequest)
+				return
+			}
+			*target = minute
+		}
+	}
 
-	// Get active matches count
-	activeMatches := 0
-	for _, match := range matches {
-		if match.Status == StatusLive || match.Status == StatusHalftime {
-			activeMatches++
+	teamOf := trackingTeams(id)
+	trails := make(map[string][]trailPoint)
+	ball := make([]trailPoint, 0)
+	for _, frame := range matchTracking[id] {
+		if frame.Minute < from || frame.Minute > to {
+			continue
+		}
+		ball = append(ball, trailPoint{Minute: frame.Minute, X: frame.Ball.X, Y: frame.Ball.Y, Timestamp: frame.Timestamp})
+		for pid, position := range frame.Players {
+			if (playerID != 0 && pid != playerID) || (teamID != 0 && teamOf[pid] != teamID) {
+				continue
+			}
+			key := strconv.Itoa(pid)
+			trails[key] = append(trails[key], trailPoint{Minute: frame.Minute, X: position.X, Y: position.Y, Timestamp: frame.Timestamp})
 		}
 	}
+	mutex.RUnlock()
 
-	// Get current season progress
-	seasonProgress := float64(currentMatchweek) / float64(SeasonMatches) * 100
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"match_id":  id,
+		"from":      from,
+		"to":        to,
+		"players":   trails,
+		"ball":      ball,
+		"count":     len(trails),
+		"timestamp": time.Now(),
+	})
+}
 
-	// Get system uptime
-	uptime := time.Since(startTime).Round(time.Second)
+// recordPassAttempt counts a pass on the passer -> intended receiver edge. Caller must hold mutex
+func recordPassAttempt(matchID int, passer, target *Player) {
+	if matchPasses[matchID] == nil {
+		matchPasses[matchID] = make(map[passKey]*PassEdge)
+	}
+	key := passKey{from: passer.ID, to: target.ID}
+	edge := matchPasses[matchID][key]
+	if edge == nil {
+		edge = &PassEdge{FromPlayerID: passer.ID, ToPlayerID: target.ID, TeamID: passer.TeamID}
+		matchPasses[matchID][key] = edge
+	}
+	edge.Passes++
+}
 
-	// Get memory stats
-	var memStats runtime.MemStats
-	runtime.ReadMemStats(&memStats)
+// recordPassCompletion credits the edge that actually connected, moving the attempt
+// over when a different teammate than the intended one received the ball
+func recordPassCompletion(matchID, passerID, targetID, receiverID int) {
+	passer, receiver := players[passerID], players[receiverID]
+	if passer == nil || receiver == nil {
+		return
+	}
 
-	// Prepare detailed health check response
-	healthData := map[string]interface{}{
-		"status":          "healthy",
-		"name":            "MatchPulse API",
-		"version":         version,
-		"uptime":          uptime.String(),
-		"active_matches":  activeMatches,
-		"max_matches":     MaxSimultaneousMatches,
-		"total_matches":   matchCount,
-		"total_players":   playerCount,
-		"total_teams":     teamCount,
-		"current_season":  currentSeason,
-		"matchweek":       currentMatchweek,
-		"season_progress": fmt.Sprintf("%.1f%%", seasonProgress),
-		"goroutines":      goroutineStats,
-		"memory": map[string]interface{}{
-			"alloc":       memStats.Alloc,
-			"total_alloc": memStats.TotalAlloc,
-			"sys":         memStats.Sys,
-			"num_gc":      memStats.NumGC,
-		},
-		"timestamp": time.Now(),
+	key := passKey{from: passerID, to: receiverID}
+	if receiverID != targetID {
+		if intended := matchPasses[matchID][passKey{from: passerID, to: targetID}]; intended != nil && intended.Passes > intended.Completed {
+			intended.Passes--
+		}
+		recordPassAttempt(matchID, passer, receiver)
+	} else if matchPasses[matchID][key] == nil {
+		// Count the attempt now rather than lose the completion
+		recordPassAttempt(matchID, passer, receiver)
 	}
+	matchPasses[matchID][key].Completed++
+}
 
-	// Log health check details
-	log.Printf("🏥 Health Check: %d/%d active matches, %d total matches, %d goroutines (%s)",
-		activeMatches, MaxSimultaneousMatches, matchCount, goroutineStats["goroutine_count"], goroutineStats["status"])
+func getMatchPassNetwork(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		http.Error(w, "Invalid match ID", http.StatusBadRequest)
+		return
+	}
+
+	_, teamID, err := parseTrackingFilters(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	minPasses := 1
+	if value := r.URL.Query().Get("min_passes"); value != "" {
+		if minPasses, err = strconv.Atoi(value); err != nil || minPasses < 1 {
+			http.Error(w, "Invalid min_passes", http.StatusBadRequest)
+			return
+		}
+	}
+
+	type passNode struct {
+		AveragePosition
+		Touches         int `json:"touches"`
+		Passes          int `json:"passes"`
+		PassesCompleted int `json:"passes_completed"`
+	}
+	type teamNetwork struct {
+		TeamID         int        `json:"team_id"`
+		TeamName       string     `json:"team_name"`
+		Nodes          []passNode `json:"nodes"`
+		Edges          []PassEdge `json:"edges"`
+		TotalPasses    int        `json:"total_passes"`
+		Completed      int        `json:"completed"`
+		CompletionRate float64    `json:"completion_rate"`
+	}
+
+	mutex.RLock()
+	match := findMatch(id)
+	if match == nil {
+		mutex.RUnlock()
+		http.Error(w, "Match not found", http.StatusNotFound)
+		return
+	}
+
+	averages := computeAveragePositions(id, 0)
+	networks := make([]teamNetwork, 0, 2)
+	for _, team := range []TeamInfo{match.HomeTeam, match.AwayTeam} {
+		if teamID != 0 && team.ID != teamID {
+			continue
+		}
+		network := teamNetwork{TeamID: team.ID, TeamName: team.Name, Nodes: []passNode{}, Edges: []PassEdge{}}
+
+		for _, playerID := range matchLineups[id][team.ID] {
+			line := matchPlayerStats[id][playerID]
+			if line == nil {
+				continue
+			}
+			node := passNode{
+				AveragePosition: AveragePosition{PlayerID: playerID, PlayerName: line.PlayerName, TeamID: team.ID, Position: line.Position},
+				Touches:         line.Touches,
+				Passes:          line.Passes,
+				PassesCompleted: line.PassesCompleted,
+			}
+			if average := averages[playerID]; average != nil {
+				node.AveragePosition = *average
+			}
+			network.Nodes = append(network.Nodes, node)
+		}
+
+		for _, edge := range matchPasses[id] {
+			if edge.TeamID != team.ID || edge.Passes == 0 {
+				continue
+			}
+			network.TotalPasses += edge.Passes
+			network.Completed += edge.Completed
+			if edge.Passes < minPasses {
+				continue
+			}
+			copied := *edge
+			copied.CompletionRate = math.Round(float64(edge.Completed)/float64(edge.Passes)*1000) / 10
+			network.Edges = append(network.Edges, copied)
+		}
+		if network.TotalPasses > 0 {
+			network.CompletionRate = math.Round(float64(network.Completed)/float64(network.TotalPasses)*1000) / 10
+		}
 
+		// Heaviest links first
+		sort.Slice(network.Edges, func(i, j int) bool {
+			if network.Edges[i].Passes != network.Edges[j].Passes {
+				return network.Edges[i].Passes > network.Edges[j].Passes
+			}
+			if network.Edges[i].FromPlayerID != network.Edges[j].FromPlayerID {
+				return network.Edges[i].FromPlayerID < network.Edges[j].FromPlayerID
+			}
+			return network.Edges[i].ToPlayerID < network.Edges[j].ToPlayerID
+		})
+		networks = append(networks, network)
+	}
+	status := match.Status
 	mutex.RUnlock()
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(healthData)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"match_id":  id,
+		"status":    status,
+		"teams":     networks,
+		"count":     len(networks),
+		"timestamp": time.Now(),
+	})
 }
 
-func serveHomepage(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+// startMatchTactics records each manager's kickoff setup. Caller must hold mutex
+func startMatchTactics(match *Match) {
+	tactics := getMatchTactics(match.ID)
+	recordTacticalChange(match, &TacticalChange{
+		TeamID: match.HomeTeam.ID, TeamName: match.HomeTeam.Name, Reason: "Starting setup",
+		Formation: match.HomeFormation, Offensive: tactics.HomeOffensive, Defensive: tactics.HomeDefensive,
+	})
+	recordTacticalChange(match, &TacticalChange{
+		TeamID: match.AwayTeam.ID, TeamName: match.AwayTeam.Name, Reason: "Starting setup",
+		Formation: match.AwayFormation, Offensive: tactics.AwayOffensive, Defensive: tactics.AwayDefensive,
+	})
+}
 
-	mutex.RLock()
-	// Find first live match ID
-	var firstLiveMatchID int
-	for id, match := range matches {
-		if match.Status == StatusLive {
-			firstLiveMatchID = id
-			break
+func recordTacticalChange(match *Match, change *TacticalChange) {
+	tacticsCounter++
+	change.ID = tacticsCounter
+	change.MatchID = match.ID
+	change.Minute = match.Minute
+	change.Timestamp = time.Now()
+	tacticsHistory[match.ID] = append(tacticsHistory[match.ID], change)
+}
+
+// sentOffCount counts a team's red cards in a match
+func sentOffCount(matchID, teamID int) int {
+	count := 0
+	for playerID, availability := range playerAvailability[matchID] {
+		if player := players[playerID]; player != nil && player.TeamID == teamID && availability.Status == PlayerRedCard {
+			count++
 		}
 	}
+	return count
+}
 
-	// If no live match found, find the first match in the map
-	if firstLiveMatchID == 0 && len(matches) > 0 {
-		for id := range matches {
-			firstLiveMatchID = id
-			break
+// updateManagerTactics lets both managers react to the score, the clock, red cards and momentum. Caller must hold mutex
+func updateManagerTactics(matchID int, match *Match) {
+	tactics := getMatchTactics(matchID)
+	momentum := matchMomentum[matchID]
+	if momentum == nil {
+		return
+	}
+
+	homeSentOff := sentOffCount(matchID, match.HomeTeam.ID)
+	awaySentOff := sentOffCount(matchID, match.AwayTeam.ID)
+
+	manageTeam(match, tactics, momentum, true, match.HomeScore-match.AwayScore, momentum.HomeTeamMomentum, homeSentOff, awaySentOff)
+	manageTeam(match, tactics, momentum, false, match.AwayScore-match.HomeScore, momentum.AwayTeamMomentum, awaySentOff, homeSentOff)
+}
+
+func manageTeam(match *Match, tactics *MatchTactics, momentum *MatchMomentum, isHome bool, goalDiff int, teamMomentum float64, sentOff, opponentSentOff int) {
+	team, formation, offensive, defensive := match.AwayTeam, match.AwayFormation, tactics.AwayOffensive, tactics.AwayDefensive
+	if isHome {
+		team, formation, offensive, defensive = match.HomeTeam, match.HomeFormation, tactics.HomeOffensive, tactics.HomeDefensive
+	}
+	if tactics.lastChange == nil {
+		tactics.lastChange = make(map[int]int)
+		tactics.redCardsSeen = make(map[int]int)
+	}
+
+	newFormation, newOffensive, newDefensive := formation, offensive, defensive
+	reason := ""
+
+	if sentOff > tactics.redCardsSeen[team.ID] {
+		// A red card always forces a reshuffle, whatever the clock says
+		tactics.redCardsSeen[team.ID] = sentOff
+		men := 11 - sentOff
+		switch {
+		case goalDiff > 0:
+			newFormation, newOffensive, newDefensive = Formation532, TacticCounterAttack, TacticLowBlock
+			reason = fmt.Sprintf("Down to %d men and shoring up the lead", men)
+		case goalDiff == 0:
+			newFormation, newOffensive, newDefensive = Formation442, TacticCounterAttack, TacticCompactDefense
+			reason = fmt.Sprintf("Reorganising with %d men", men)
+		default:
+			newFormation, newOffensive, newDefensive = Formation442, TacticDirectPlay, TacticZonalMarking
+			reason = fmt.Sprintf("Down to %d men and chasing the game", men)
 		}
+		applyTacticalChange(match, tactics, momentum, isHome, newFormation, newOffensive, newDefensive, reason, true)
+		return
 	}
 
-	templateData := struct {
-		ActiveMatches    int
-		TotalPlayers     int
-		TotalTeams       int
-		CurrentSeason    int
-		CurrentMatchweek int
-		LastUpdated      string
-		Version          string
-		FirstLiveMatchID int
-	}{
-		ActiveMatches:    len(matches),
-		TotalPlayers:     len(players),
-		TotalTeams:       len(teams),
-		CurrentSeason:    currentSeason,
-		CurrentMatchweek: currentMatchweek,
-		LastUpdated:      time.Now().Format("2006-01-02 15:04:05"),
-		Version:          version,
-		FirstLiveMatchID: firstLiveMatchID,
+	// Managers settle in first and give each change time to work
+	if match.Minute < ManagerFirstChangeMinute || match.Minute-tactics.lastChange[team.ID] < ManagerChangeCooldown {
+		return
+	}
+	if rand.Float64() > ManagerReactionChance {
+		return
 	}
-	mutex.RUnlock()
 
-	const htmlTemplate = `<!DOCTYPE html>
-<html>
-<head>
-    <title>MatchPulse API v{{.Version}}</title>
-    <style>
-        body {
-            font-family: system-ui, -apple-system, sans-serif;
-            margin: 0;
-            padding: 20px;
-            background: linear-gradient(135deg, #667eea 0%, #764ba2 100%);
-            min-height: 100vh;
-            box-sizing: border-box;
-        }
-        .container {
-            max-width: 1400px;
-            margin: 0 auto;
+	switch {
+	case goalDiff > 0 && match.Minute >= 70:
+		newOffensive, newDefensive = TacticCounterAttack, TacticLowBlock
+		if goalDiff == 1 {
+			newFormation = Formation532
+		}
+		reason = "Protecting the lead"
+	case goalDiff > 0 && teamMomentum < -0.3:
+		newDefensive = TacticCompactDefense
+		reason = "Under pressure and sitting deeper"
+	case goalDiff < 0 && match.Minute >= 60:
+		newFormation, newOffensive, newDefensive = Formation433, TacticPressing, TacticOffside
+		if goalDiff == -1 && match.Minute < 75 && opponentSentOff == 0 {
+			newFormation = Formation4231
+		}
+		reason = "Chasing the game"
+	case goalDiff < 0 && teamMomentum < -0.3:
+		newFormation, newOffensive = Formation4231, TacticDirectPlay
+		reason = "Changing shape to get back into it"
+	case goalDiff == 0 && match.Minute >= 75 && (teamMomentum > 0.3 || opponentSentOff > sentOff):
+		newOffensive, newDefensive = TacticPressing, TacticOffside
+		reason = "Going for the winner"
+	case goalDiff == 0 && match.Minute >= 75 && teamMomentum < -0.3:
+		newOffensive, newDefensive = TacticCounterAttack, TacticCompactDefense
+		reason = "Settling for a point"
+	default:
+		return
+	}
+
+	if newFormation == formation && newOffensive == offensive && newDefensive == defensive {
+		return
+	}
+	applyTacticalChange(match, tactics, momentum, isHome, newFormation, newOffensive, newDefensive, reason, false)
+}
+
+// applyTacticalChange puts a manager's decision into effect, records it and announces it
+func applyTacticalChange(match *Match, tactics *MatchTactics, momentum *MatchMomentum, isHome bool,
+	formation, offensive, defensive, reason string, reshuffle bool) {
+
+	change := &TacticalChange{Reason: reason, Formation: formation, Offensive: offensive, Defensive: defensive}
+	if isHome {
+		change.TeamID, change.TeamName = match.HomeTeam.ID, match.HomeTeam.Name
+		change.PreviousFormation, change.PreviousOffensive, change.PreviousDefensive = match.HomeFormation, tactics.HomeOffensive, tactics.HomeDefensive
+		match.HomeFormation, tactics.HomeOffensive, tactics.HomeDefensive = formation, offensive, defensive
+	} else {
+		change.TeamID, change.TeamName = match.AwayTeam.ID, match.AwayTeam.Name
+		change.PreviousFormation, change.PreviousOffensive, change.PreviousDefensive = match.AwayFormation, tactics.AwayOffensive, tactics.AwayDefensive
+		match.AwayFormation, tactics.AwayOffensive, tactics.AwayDefensive = formation, offensive, defensive
+	}
+
+	if formation != change.PreviousFormation {
+		momentum.FormationAdjustments++
+	}
+	if formation != change.PreviousFormation || reshuffle {
+		arrangeLineup(match.ID, change.TeamID, formation)
+	}
+
+	tactics.lastChange[change.TeamID] = match.Minute
+	recordTacticalChange(match, change)
+
+	text := fmt.Sprintf("Tactical change: %s go %s with %s and %s", change.TeamName, formation, tacticLabel(offensive), tacticLabel(defensive))
+	if reason != "" {
+		text += " - " + strings.ToLower(reason[:1]) + reason[1:]
+	}
+	addLiveCommentary(match.ID, match.Minute, text, EventTacticalChange, nil)
+
+	logWithFields(LevelInfo, matchLogFields(match, "manager"), "🧠 Match %d: %s switch to %s %s/%s (%s)",
+		match.ID, change.TeamName, formation, offensive, defensive, reason)
+}
+
+// updateSubstitutions lets both managers bring on fresh legs in the second half. Caller must hold mutex
+func updateSubstitutions(matchID int, match *Match) {
+	if match.Minute < SubstitutionFirstMinute {
+		return
+	}
+	for _, team := range []TeamInfo{match.HomeTeam, match.AwayTeam} {
+		if substitutionCount(matchID, team.ID) >= MaxSubstitutions || rand.Float64() > SubstitutionChance {
+			continue
+		}
+		makeSubstitution(matchID, match, team)
+	}
+}
+
+// substitutionCount counts the players a team has taken off in a match
+func substitutionCount(matchID, teamID int) int {
+	count := 0
+	for _, line := range matchPlayerStats[matchID] {
+		if line.TeamID == teamID && line.SubbedOffMinute > 0 {
+			count++
+		}
+	}
+	return count
+}
+
+// makeSubstitution replaces the most tired outfield player with the best fit from the bench.
+// The newcomer takes the same slot in the formation and the same spot on the pitch
+func makeSubstitution(matchID int, match *Match, team TeamInfo) {
+	var off *Player
+	for _, player := range getMatchLineup(matchID, team.ID) {
+		if player.Position == PosGK || (ballPositions[matchID] != nil && ballPositions[matchID].PossessorID == player.ID) {
+			continue
+		}
+		if off == nil || playerStamina(matchID, player) < playerStamina(matchID, off) {
+			off = player
+		}
+	}
+	if off == nil || (playerStamina(matchID, off) >= SubstitutionStamina && match.Minute < 75) {
+		return
+	}
+
+	used := make(map[int]bool)
+	for _, playerID := range matchLineups[matchID][team.ID] {
+		used[playerID] = true
+	}
+	var on *Player
+	for _, player := range getPlayersFromTeam(team.ID) {
+		if used[player.ID] || player.Position == PosGK {
+			continue
+		}
+		if on == nil {
+			on = player
+			continue
+		}
+		// Prefer a like-for-like change, then the strongest player
+		sameRole := fantasyPositionGroup(player.Position) == fantasyPositionGroup(off.Position)
+		onSameRole := fantasyPositionGroup(on.Position) == fantasyPositionGroup(off.Position)
+		if sameRole != onSameRole {
+			if sameRole {
+				on = player
+			}
+			continue
+		}
+		if player.Characteristics.Overall > on.Characteristics.Overall ||
+			(player.Characteristics.Overall == on.Characteristics.Overall && player.ID < on.ID) {
+			on = player
+		}
+	}
+	if on == nil {
+		return
+	}
+
+	// Swap the slot and keep the outgoing player's ID at the end, like a sent-off player
+	lineup := matchLineups[matchID][team.ID]
+	for i, playerID := range lineup {
+		if playerID == off.ID {
+			lineup[i] = on.ID
+			break
+		}
+	}
+	matchLineups[matchID][team.ID] = append(lineup, off.ID)
+
+	setPlayerUnavailable(matchID, off.ID, PlayerSubstituted, match.Minute, "Substituted")
+	if line := matchPlayerLine(matchID, off); line != nil {
+		line.SubbedOffMinute = max(1, match.Minute)
+	}
+	matchPlayerStats[matchID][on.ID] = &MatchPlayerStats{
+		MatchID:        matchID,
+		PlayerID:       on.ID,
+		PlayerName:     on.Name,
+		TeamID:         team.ID,
+		Position:       on.Position,
+		Rating:         6.0,
+		Stamina:        100,
+		SubbedOnMinute: max(1, match.Minute),
+		LastUpdate:     time.Now(),
+	}
+	if location := playerLocations[matchID][off.ID]; location != nil {
+		playerLocations[matchID][on.ID] = &PlayerLocation{PlayerID: on.ID, X: location.X, Y: location.Y, Timestamp: time.Now()}
+		delete(playerLocations[matchID], off.ID)
+	}
+
+	recordTimelineEvent(match, &TimelineEvent{
+		Type: EventSubstitution, TeamID: team.ID, PlayerID: on.ID, PlayerName: on.Name,
+		PlayerOffID: off.ID, PlayerOffName: off.Name,
+	})
+	addLiveCommentary(matchID, match.Minute, fmt.Sprintf("Substitution for %s: %s comes on for %s", team.Name, on.Name, off.Name), EventSubstitution, on)
+	logWithFields(LevelDebug, matchLogFields(match, "manager"), "🔁 Match %d: %s replace %s with %s", matchID, team.Name, off.Name, on.Name)
+}
+
+// tacticLabel turns a tactic constant into commentary text, e.g. LOW_BLOCK -> "low block"
+func tacticLabel(tactic string) string {
+	return strings.ToLower(strings.ReplaceAll(tactic, "_", " "))
+}
+
+func getMatchTacticsHistory(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		http.Error(w, "Invalid match ID", http.StatusBadRequest)
+		return
+	}
+
+	teamID := 0
+	if value := r.URL.Query().Get("team_id"); value != "" {
+		if teamID, err = strconv.Atoi(value); err != nil {
+			http.Error(w, "Invalid team_id", http.StatusBadRequest)
+			return
+		}
+	}
+
+	mutex.RLock()
+	match := findMatch(id)
+	if match == nil {
+		mutex.RUnlock()
+		http.Error(w, "Match not found", http.StatusNotFound)
+		return
+	}
+
+	// Tactics are set at kickoff; a match that has not started has none yet
+	var tactics *MatchTactics
+	if current := matchTactics[id]; current != nil {
+		copied := *current
+		tactics = &copied
+	}
+	history := make([]TacticalChange, 0)
+	for _, change := range tacticsHistory[id] {
+		if teamID != 0 && change.TeamID != teamID {
+			continue
+		}
+		history = append(history, *change)
+	}
+
+	response := map[string]interface{}{
+		"match_id":       id,
+		"status":         match.Status,
+		"minute":         match.Minute,
+		"tactics":        tactics,
+		"home_formation": match.HomeFormation,
+		"away_formation": match.AwayFormation,
+		"history":        history,
+		"count":          len(history),
+		"timestamp":      time.Now(),
+	}
+	mutex.RUnlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// rainIntensity is 0 on a dry pitch, 0.5 in light rain and 1 in heavy rain
+func rainIntensity(weather string) float64 {
+	switch weather {
+	case WeatherLightRain:
+		return 0.5
+	case WeatherHeavyRain:
+		return 1.0
+	}
+	return 0
+}
+
+// heatStress grows by 0.1 for every degree above HeatStressTemperature
+func heatStress(temperature int) float64 {
+	return math.Max(0, float64(temperature-HeatStressTemperature)) / 10
+}
+
+// windStrength scales the match wind to 0-1
+func windStrength(match *Match) float64 {
+	return math.Min(1, float64(match.WindSpeed)/MaxWindSpeed)
+}
+
+// drainStamina tires a player by the ground they covered, faster in the heat and for weaker players
+func drainStamina(line *MatchPlayerStats, player *Player, moved float64, match *Match) {
+	cost := StaminaPerUnit * (1 + heatStress(match.Temperature)) * (1.5 - float64(player.Characteristics.Physicality)/100)
+	line.Stamina = math.Round(math.Max(MinStamina, line.Stamina-moved*cost)*10) / 10
+}
+
+// playerStamina returns a starter's remaining stamina, or full stamina when untracked
+func playerStamina(matchID int, player *Player) float64 {
+	if line := matchPlayerLine(matchID, player); line != nil {
+		return line.Stamina
+	}
+	return 100
+}
+
+// shotConditionsFactor scales shot quality for wind and the shooter's fatigue
+func shotConditionsFactor(matchID int, match *Match, shooter *Player) float64 {
+	return (1 - windStrength(match)*0.25) * (0.75 + 0.25*playerStamina(matchID, shooter)/100)
+}
+
+// updateMatchWeather occasionally shifts the weather or wind during a match. Caller must hold mutex
+func updateMatchWeather(matchID int, match *Match) {
+	if rand.Float64() >= WeatherChangeChance {
+		return
+	}
+
+	var text, detail string
+	if rand.Float64() < 0.3 {
+		// The wind picks up or drops
+		previous := match.WindSpeed
+		match.WindSpeed = max(0, min(int(MaxWindSpeed), match.WindSpeed+rand.Intn(21)-10))
+		if match.WindSpeed == previous {
+			return
+		}
+		if match.WindSpeed > previous {
+			text = "The wind is picking up, long balls are hard to judge"
+		} else {
+			text = "The wind is dropping"
+		}
+		detail = fmt.Sprintf("wind %d -> %d km/h", previous, match.WindSpeed)
+	} else {
+		// Weather moves one step wetter or drier
+		step := weatherStepIndex(match.Weather)
+		next := step + 1
+		if rand.Float64() < 0.5 {
+			next = step - 1
+		}
+		if next < 0 || next >= len(weatherProgression) {
+			return
+		}
+		previous := match.Weather
+		match.Weather = weatherProgression[next]
+		if rainIntensity(match.Weather) > rainIntensity(previous) {
+			match.Temperature--
+		}
+
+		switch {
+		case match.Weather == WeatherHeavyRain:
+			text = "The rain is getting heavier, the ball is skidding off the surface"
+		case match.Weather == WeatherLightRain && previous != WeatherHeavyRain:
+			text = "Rain starts to fall"
+		case previous == WeatherHeavyRain:
+			text = "The rain is easing off"
+		case previous == WeatherLightRain:
+			text = "The rain has stopped"
+		case match.Weather == WeatherClear && next > step:
+			text = "The sun has gone in"
+		case next > step:
+			text = "The clouds are rolling in"
+		default:
+			text = "The sun is breaking through"
+		}
+		detail = fmt.Sprintf("%s -> %s", previous, match.Weather)
+	}
+
+	addLiveCommentary(matchID, match.Minute,
+		fmt.Sprintf("Weather conditions affecting play: %s (%s, %d°C, wind %d km/h)", text, match.Weather, match.Temperature, match.WindSpeed),
+		EventWeather, nil)
+	recordTimelineEvent(match, &TimelineEvent{Type: EventWeather, Detail: detail})
+	logWithFields(LevelInfo, matchLogFields(match, "weather"), "🌦️  Match %d: weather %s", matchID, detail)
+}
+
+// weatherStepIndex finds a condition on the dry-to-wet scale
+func weatherStepIndex(weather string) int {
+	for i, condition := range weatherProgression {
+		if condition == weather {
+			return i
+		}
+	}
+	return 0
+}
+
+func initializeReferees() {
+	for _, data := range refereeData {
+		referees[data.ID] = &Referee{
+			ID:          data.ID,
+			Name:        data.Name,
+			Nationality: data.Nationality,
+			Strictness:  data.Strictness,
+			Consistency: data.Consistency,
+		}
+	}
+}
+
+// assignReferee picks a referee who is not already officiating a match in progress
+func assignReferee() *Referee {
+	busy := make(map[int]bool)
+	for _, match := range matches {
+		if match.Status != StatusFinished {
+			busy[match.Referee.ID] = true
+		}
+	}
+
+	var available []*Referee
+	for _, referee := range referees {
+		if !busy[referee.ID] {
+			available = append(available, referee)
+		}
+	}
+	if len(available) == 0 {
+		for _, referee := range referees {
+			available = append(available, referee)
+		}
+	}
+	if len(available) == 0 {
+		return nil
+	}
+
+	sort.Slice(available, func(i, j int) bool { return available[i].ID < available[j].ID })
+	referee := available[rand.Intn(len(available))]
+	referee.Matches++
+	return referee
+}
+
+// matchReferee returns the referee in charge of a match, or nil
+func matchReferee(match *Match) *Referee {
+	return referees[match.Referee.ID]
+}
+
+// refereeStrictness is 0 for the most lenient and 1 for the strictest referee
+func refereeStrictness(match *Match) float64 {
+	if referee := matchReferee(match); referee != nil {
+		return float64(referee.Strictness) / 100
+	}
+	return 0.5
+}
+
+// refereeJudgement blurs a decision probability; inconsistent referees stray further from it
+func refereeJudgement(match *Match, chance float64) float64 {
+	consistency := 0.5
+	if referee := matchReferee(match); referee != nil {
+		consistency = float64(referee.Consistency) / 100
+	}
+	return chance * (1 + (rand.Float64()-0.5)*(1-consistency))
+}
+
+// reviewGoal sends the latest goal to VAR some of the time. Caller must hold mutex
+func reviewGoal(match *Match) {
+	event := lastTimelineEvent(match.ID, EventGoal)
+	if event == nil || rand.Float64() >= VARGoalReviewChance {
+		return
+	}
+	reasons := []string{"Possible offside", "Possible handball", "Foul in the build-up"}
+	startVARReview(match, &VARReview{
+		Decision:   VARDecisionGoal,
+		TeamID:     event.TeamID,
+		PlayerID:   event.PlayerID,
+		PlayerName: event.PlayerName,
+		Reason:     reasons[rand.Intn(len(reasons))],
+		EventID:    event.ID,
+	})
+}
+
+// reviewPenalty sends a penalty award to VAR some of the time. Caller must hold mutex
+func reviewPenalty(match *Match, event *TimelineEvent) {
+	if rand.Float64() >= VARPenaltyReviewChance {
+		return
+	}
+	reasons := []string{"Checking the contact", "Possible dive", "Was it inside the box?"}
+	startVARReview(match, &VARReview{
+		Decision: VARDecisionPenalty,
+		TeamID:   event.TeamID,
+		Reason:   reasons[rand.Intn(len(reasons))],
+		EventID:  event.ID,
+	})
+}
+
+// reviewRedCard sends a red card to VAR some of the time. Caller must hold mutex
+func reviewRedCard(match *Match, event *TimelineEvent) {
+	if rand.Float64() >= VARRedCardReviewChance {
+		return
+	}
+	reasons := []string{"Checking the severity of the challenge", "Possible mistaken identity", "Was there contact?"}
+	startVARReview(match, &VARReview{
+		Decision:   VARDecisionRedCard,
+		TeamID:     event.TeamID,
+		PlayerID:   event.PlayerID,
+		PlayerName: event.PlayerName,
+		Reason:     reasons[rand.Intn(len(reasons))],
+		EventID:    event.ID,
+	})
+}
+
+// startVARReview stops play while a decision is checked. Only one review runs at a time
+func startVARReview(match *Match, review *VARReview) {
+	if match.Status != StatusLive {
+		return
+	}
+
+	varReviewCounter++
+	now := time.Now()
+	seconds := VARMinReviewSeconds + rand.Intn(VARMaxReviewSeconds-VARMinReviewSeconds+1)
+	review.ID = varReviewCounter
+	review.MatchID = match.ID
+	review.Minute = match.Minute
+	review.Outcome = VARPending
+	review.StartedAt = now
+	review.ResolvesAt = now.Add(time.Duration(seconds) * time.Second)
+
+	match.Status = StatusVARCheck
+	match.VARReview = review
+	matchVARReviews[match.ID] = append(matchVARReviews[match.ID], review)
+	if referee := matchReferee(match); referee != nil {
+		referee.VARReviews++
+	}
+
+	recordTimelineEvent(match, &TimelineEvent{
+		Type:       EventVARCheck,
+		TeamID:     review.TeamID,
+		PlayerID:   review.PlayerID,
+		PlayerName: review.PlayerName,
+		Reason:     review.Reason,
+		Detail:     varDecisionLabel(review.Decision),
+		RelatedID:  review.EventID,
+	})
+	addLiveCommentary(match.ID, match.Minute,
+		fmt.Sprintf("VAR CHECK: the %s is being reviewed - %s", varDecisionLabel(review.Decision), strings.ToLower(review.Reason)),
+		EventVARCheck, players[review.PlayerID])
+	suspendMatchOdds(match.ID, "VAR_CHECK", seconds+2)
+
+	logWithFields(LevelInfo, matchLogFields(match, "var"), "📺 Match %d: VAR checking %s (%s)", match.ID, review.Decision, review.Reason)
+}
+
+// resolveVARReview settles a pending review and restarts play, adding the lost time back
+func resolveVARReview(match *Match, now time.Time) {
+	review := match.VARReview
+	match.Status = StatusLive
+	match.VARReview = nil
+	if review == nil {
+		return
+	}
+	match.StartTime = match.StartTime.Add(now.Sub(review.StartedAt))
+
+	chance := 0.3 + (1-float64(consistencyOf(match))/100)*0.3
+	if review.Decision != VARDecisionGoal {
+		chance += 0.05 + refereeStrictness(match)*0.1
+	}
+	overturned := rand.Float64() < chance
+
+	resolvedAt := now
+	review.ResolvedAt = &resolvedAt
+	review.Outcome = VARStands
+	if overturned {
+		review.Outcome = VAROverturned
+		if referee := matchReferee(match); referee != nil {
+			referee.Overturned++
+		}
+		if event := timelineEventByID(match.ID, review.EventID); event != nil {
+			event.Overturned = true
+		}
+
+		switch review.Decision {
+		case VARDecisionGoal:
+			overturnGoal(match, review)
+		case VARDecisionPenalty:
+			overturnPenalty(match, review)
+		case VARDecisionRedCard:
+			overturnRedCard(match, review)
+		}
+		recalculateMatchProbabilities(match.ID, match)
+	} else if review.Decision == VARDecisionGoal {
+		confirmGoal(match.ID, match)
+	}
+
+	recordTimelineEvent(match, &TimelineEvent{
+		Type:       EventVARDecision,
+		TeamID:     review.TeamID,
+		PlayerID:   review.PlayerID,
+		PlayerName: review.PlayerName,
+		Reason:     review.Reason,
+		Detail:     strings.ToLower(review.Outcome),
+		RelatedID:  review.EventID,
+	})
+
+	var text string
+	switch {
+	case !overturned:
+		text = fmt.Sprintf("VAR: the %s stands!", varDecisionLabel(review.Decision))
+	case review.Decision == VARDecisionGoal:
+		text = fmt.Sprintf("VAR: NO GOAL! %s - it's %s %d-%d %s", review.Reason, match.HomeTeam.Name, match.HomeScore, match.AwayScore, match.AwayTeam.Name)
+	case review.Decision == VARDecisionPenalty:
+		text = "VAR: no penalty! The decision is overturned"
+	default:
+		text = fmt.Sprintf("VAR: the red card for %s is rescinded and the player stays on", review.PlayerName)
+	}
+	addLiveCommentary(match.ID, match.Minute, text, EventVARDecision, players[review.PlayerID])
+
+	logWithFields(LevelInfo, matchLogFields(match, "var"), "📺 Match %d: VAR %s %s", match.ID, review.Decision, review.Outcome)
+}
+
+func consistencyOf(match *Match) int {
+	if referee := matchReferee(match); referee != nil {
+		return referee.Consistency
+	}
+	return 50
+}
+
+func overturnGoal(match *Match, review *VARReview) {
+	event := timelineEventByID(match.ID, review.EventID)
+	scorer := players[review.PlayerID]
+	if event == nil || scorer == nil {
+		return
+	}
+
+	if review.TeamID == match.HomeTeam.ID {
+		match.HomeScore = max(0, match.HomeScore-1)
+	} else {
+		match.AwayScore = max(0, match.AwayScore-1)
+	}
+
+	scorer.Goals--
+	scorer.SeasonStats.GoalsThisSeason--
+	if line := matchPlayerLine(match.ID, scorer); line != nil {
+		line.Goals--
+	}
+	if matchScorers[match.ID] != nil {
+		matchScorers[match.ID][scorer.ID]--
+		if matchScorers[match.ID][scorer.ID] <= 0 {
+			delete(matchScorers[match.ID], scorer.ID)
+		}
+	}
+	undoFantasyEvent(match.ID, scorer, "goal")
+	removeNewsEntry(event.newsID)
+
+	if assister := players[event.AssistID]; assister != nil {
+		assister.Assists--
+		assister.SeasonStats.AssistsThisSeason--
+		if line := matchPlayerLine(match.ID, assister); line != nil {
+			line.Assists--
+		}
+		undoFantasyEvent(match.ID, assister, "assist")
+	}
+
+	if momentum := matchMomentum[match.ID]; momentum != nil {
+		if review.TeamID == match.HomeTeam.ID {
+			momentum.HomeTeamMomentum -= 0.3
+			momentum.AwayTeamMomentum += 0.2
+		} else {
+			momentum.AwayTeamMomentum -= 0.3
+			momentum.HomeTeamMomentum += 0.2
+		}
+		momentum.HomeTeamMomentum = math.Max(-1.0, math.Min(1.0, momentum.HomeTeamMomentum))
+		momentum.AwayTeamMomentum = math.Max(-1.0, math.Min(1.0, momentum.AwayTeamMomentum))
+		momentum.ConsecutiveGoals = max(0, momentum.ConsecutiveGoals-1)
+	}
+
+	restartWithGoalKickAgainst(match, review.TeamID)
+}
+
+func overturnPenalty(match *Match, review *VARReview) {
+	restartWithGoalKickAgainst(match, review.TeamID)
+}
+
+func overturnRedCard(match *Match, review *VARReview) {
+	player := players[review.PlayerID]
+	if player == nil {
+		return
+	}
+	if event := timelineEventByID(match.ID, review.EventID); event != nil {
+		removeNewsEntry(event.newsID)
+	}
+	if referee := matchReferee(match); referee != nil {
+		referee.RedCards = max(0, referee.RedCards-1)
+	}
+
+	delete(playerAvailability[match.ID], player.ID)
+	player.RedCards--
+	player.SeasonStats.RedCardsThisSeason--
+	if line := matchPlayerLine(match.ID, player); line != nil {
+		line.RedCards--
+		line.SentOffMinute = 0
+	}
+	undoFantasyEvent(match.ID, player, "red")
+
+	if stats := matchStats[match.ID]; stats != nil {
+		if player.TeamID == match.HomeTeam.ID {
+			stats.HomeRedCards--
+		} else {
+			stats.AwayRedCards--
+		}
+	}
+	if tactics := matchTactics[match.ID]; tactics != nil && tactics.redCardsSeen != nil {
+		tactics.redCardsSeen[player.TeamID] = max(0, tactics.redCardsSeen[player.TeamID]-1)
+	}
+	if momentum := matchMomentum[match.ID]; momentum != nil {
+		if player.TeamID == match.HomeTeam.ID {
+			momentum.HomeTeamMomentum = math.Min(1.0, momentum.HomeTeamMomentum+0.4)
+		} else {
+			momentum.AwayTeamMomentum = math.Min(1.0, momentum.AwayTeamMomentum+0.4)
+		}
+	}
+}
+
+// restartWithGoalKickAgainst gives the ball back to the side a cancelled decision went against
+func restartWithGoalKickAgainst(match *Match, teamID int) {
+	// Goal kicks in the left half belong to the home side
+	x := 6.0
+	if teamID == match.HomeTeam.ID {
+		x = FieldWidth - 6.0
+	}
+	setBallEvent(match.ID, BallEventGoalkick, x, FieldHeight/2, 0)
+}
+
+func varDecisionLabel(decision string) string {
+	return strings.ToLower(strings.ReplaceAll(decision, "_", " "))
+}
+
+// lastTimelineEvent returns the newest event of a type in a match
+func lastTimelineEvent(matchID int, eventType string) *TimelineEvent {
+	timeline := matchTimelines[matchID]
+	for i := len(timeline) - 1; i >= 0; i-- {
+		if timeline[i].Type == eventType {
+			return timeline[i]
+		}
+	}
+	return nil
+}
+
+func timelineEventByID(matchID, eventID int) *TimelineEvent {
+	for _, event := range matchTimelines[matchID] {
+		if event.ID == eventID {
+			return event
+		}
+	}
+	return nil
+}
+
+func getReferees(w http.ResponseWriter, r *http.Request) {
+	mutex.RLock()
+	list := make([]Referee, 0, len(referees))
+	for _, referee := range referees {
+		list = append(list, *referee)
+	}
+	mutex.RUnlock()
+
+	sort.Slice(list, func(i, j int) bool { return list[i].ID < list[j].ID })
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"referees":  list,
+		"count":     len(list),
+		"timestamp": time.Now(),
+	})
+}
+
+func getMatchVARReviews(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		http.Error(w, "Invalid match ID", http.StatusBadRequest)
+		return
+	}
+
+	mutex.RLock()
+	match := findMatch(id)
+	if match == nil {
+		mutex.RUnlock()
+		http.Error(w, "Match not found", http.StatusNotFound)
+		return
+	}
+
+	reviews := make([]VARReview, 0, len(matchVARReviews[id]))
+	for _, review := range matchVARReviews[id] {
+		reviews = append(reviews, *review)
+	}
+	response := map[string]interface{}{
+		"match_id":  id,
+		"status":    match.Status,
+		"referee":   match.Referee,
+		"reviews":   reviews,
+		"count":     len(reviews),
+		"timestamp": time.Now(),
+	}
+	mutex.RUnlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+func getMatch(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		http.Error(w, "Invalid match ID", http.StatusBadRequest)
+		return
+	}
+
+	mutex.RLock()
+	match := findMatch(id)
+	mutex.RUnlock()
+
+	if match == nil {
+		http.Error(w, "Match not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(match)
+}
+
+func getMatchStats(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		http.Error(w, "Invalid match ID", http.StatusBadRequest)
+		return
+	}
+
+	mutex.RLock()
+	stats, exists := matchStats[id]
+	mutex.RUnlock()
+
+	if !exists {
+		http.Error(w, "Match stats not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(stats)
+}
+
+func getMatchCommentary(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		http.Error(w, "Invalid match ID", http.StatusBadRequest)
+		return
+	}
+
+	query := r.URL.Query()
+
+	limit := DefaultCommentaryLimit
+	if value := query.Get("limit"); value != "" {
+		limit, err = strconv.Atoi(value)
+		if err != nil || limit < 1 {
+			http.Error(w, "Invalid limit", http.StatusBadRequest)
+			return
+		}
+		if limit > MaxCommentaryLimit {
+			limit = MaxCommentaryLimit
+		}
+	}
+
+	// since accepts either a commentary ID or an RFC3339 timestamp
+	sinceID := 0
+	var sinceTime time.Time
+	if value := query.Get("since"); value != "" {
+		if sinceID, err = strconv.Atoi(value); err != nil {
+			sinceID = 0
+			if sinceTime, err = time.Parse(time.RFC3339, value); err != nil {
+				http.Error(w, "Invalid since, expected commentary ID or RFC3339 timestamp", http.StatusBadRequest)
+				return
+			}
+		}
+	}
+	paging := query.Get("since") != ""
+
+	// Newest first by default; incremental fetches read forward from since
+	order := strings.ToLower(query.Get("order"))
+	if order == "" {
+		order = "desc"
+		if paging {
+			order = "asc"
+		}
+	}
+	if order != "asc" && order != "desc" {
+		http.Error(w, "Invalid order, expected asc or desc", http.StatusBadRequest)
+		return
+	}
+
+	eventTypes := make(map[string]bool)
+	for _, eventType := range splitListParam(query.Get("event_type")) {
+		eventTypes[strings.ToUpper(eventType)] = true
+	}
+
+	mutex.RLock()
+	if findMatch(id) == nil {
+		mutex.RUnlock()
+		http.Error(w, "Match not found", http.StatusNotFound)
+		return
+	}
+	history := liveCommentary[id]
+	latestID := 0
+	if len(history) > 0 {
+		latestID = history[len(history)-1].ID
+	}
+
+	// History is stored oldest first
+	matching := make([]*LiveCommentary, 0)
+	for _, entry := range history {
+		if entry.ID <= sinceID || (!sinceTime.IsZero() && !entry.Timestamp.After(sinceTime)) {
+			continue
+		}
+		if len(eventTypes) > 0 && !eventTypes[entry.EventType] {
+			continue
+		}
+		matching = append(matching, entry)
+	}
+	mutex.RUnlock()
+
+	// With since the page is the oldest entries after it, so nothing is skipped between
+	// polls; otherwise it is the newest entries. order only sets how the page is listed
+	total := len(matching)
+	page := matching[max(0, total-limit):]
+	if paging || order == "asc" {
+		page = matching[:min(limit, total)]
+	}
+	commentary := make([]*LiveCommentary, len(page))
+	for i, entry := range page {
+		if order == "asc" {
+			commentary[i] = entry
+		} else {
+			commentary[len(page)-1-i] = entry
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"commentary": commentary,
+		"match_id":   id,
+		"count":      len(commentary),
+		"total":      total,
+		"has_more":   total > len(commentary),
+		"latest_id":  latestID,
+		"order":      order,
+		"timestamp":  time.Now(),
+	})
+}
+
+func getMatchLocations(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		http.Error(w, "Invalid match ID", http.StatusBadRequest)
+		return
+	}
+
+	mutex.RLock()
+	match := findMatch(id)
+	if match == nil {
+		mutex.RUnlock()
+		http.Error(w, "Match not found", http.StatusNotFound)
+		return
+	}
+
+	locations, exists := playerLocations[id]
+	if !exists {
+		locations = make(map[int]*PlayerLocation)
+	}
+
+	// Get only the first 11 players from each team
+	homeTeamID := match.HomeTeam.ID
+	awayTeamID := match.AwayTeam.ID
+
+	homeCount := 0
+	awayCount := 0
+	locationList := make([]*PlayerLocation, 0, 22) // Pre-allocate for 22 players
+
+	for _, location := range locations {
+		if player, exists := players[location.PlayerID]; exists {
+			if player.TeamID == homeTeamID && homeCount < 11 {
+				locationList = append(locationList, location)
+				homeCount++
+			} else if player.TeamID == awayTeamID && awayCount < 11 {
+				locationList = append(locationList, location)
+				awayCount++
+			}
+		}
+	}
+
+	// Get ball position
+	ball := ballPositions[id]
+	status := match.Status
+	mutex.RUnlock()
+
+	// Finished matches report the last known positions
+	response := map[string]interface{}{
+		"locations": locationList,
+		"match_id":  id,
+		"status":    status,
+		"count":     len(locationList),
+		"timestamp": time.Now(),
+	}
+
+	// Add ball position if it exists
+	if ball != nil {
+		response["ball"] = ball
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+func getMatchMomentum(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		http.Error(w, "Invalid match ID", http.StatusBadRequest)
+		return
+	}
+
+	mutex.RLock()
+	momentum := matchMomentum[id]
+	mutex.RUnlock()
+
+	response := map[string]interface{}{
+		"match_id":  id,
+		"timestamp": time.Now(),
+	}
+
+	if momentum != nil {
+		response["momentum"] = momentum
+	} else {
+		response["momentum"] = nil
+		response["message"] = "No momentum data available for this match"
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+func getMatchProbabilities(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		http.Error(w, "Invalid match ID", http.StatusBadRequest)
+		return
+	}
+
+	mutex.RLock()
+	defer mutex.RUnlock()
+
+	probs := dynamicProbabilities[id]
+	match := findMatch(id)
+	if match == nil {
+		http.Error(w, "Match not found", http.StatusNotFound)
+		return
+	}
+
+	response := map[string]interface{}{
+		"match_id":  id,
+		"timestamp": time.Now(),
+	}
+
+	if probs != nil {
+		response["probabilities"] = probs
+	} else {
+		// Calculate initial probabilities if none exist
+		homeWin, draw, awayWin := calculateMatchProbabilities(match.ID, &match.HomeTeam, &match.AwayTeam, match.CrowdFactor)
+
+		response["probabilities"] = map[string]interface{}{
+			"home_win_prob": homeWin,
+			"draw_prob":     draw,
+			"away_win_prob": awayWin,
+			"calculated_at": time.Now(),
+			"note":          "Initial pre-match probabilities",
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+func getMatchOdds(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		http.Error(w, "Invalid match ID", http.StatusBadRequest)
+		return
+	}
+
+	// Markets are updated in place by the engine, so encode under the lock
+	mutex.RLock()
+	defer mutex.RUnlock()
+
+	match := findMatch(id)
+	odds := matchOdds[id]
+	if match == nil || odds == nil {
+		http.Error(w, "Match odds not found", http.StatusNotFound)
+		return
+	}
+
+	history := oddsHistory[id]
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"match_id":  id,
+		"odds":      odds,
+		"history":   history,
+		"count":     len(history),
+		"timestamp": time.Now(),
+	})
+}
+
+func getMatchAvailability(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		http.Error(w, "Invalid match ID", http.StatusBadRequest)
+		return
+	}
+
+	mutex.RLock()
+	availability := playerAvailability[id]
+	match := findMatch(id)
+	if match == nil {
+		mutex.RUnlock()
+		http.Error(w, "Match not found", http.StatusNotFound)
+		return
+	}
+
+	// Get available and unavailable players
+	availablePlayers := getAvailablePlayersForMatch(id)
+	var unavailablePlayers []map[string]interface{}
+
+	for _, avail := range availability {
+		if avail.Status != PlayerAvailable {
+			player := getPlayerByID(avail.PlayerID)
+			if player != nil {
+				unavailablePlayers = append(unavailablePlayers, map[string]interface{}{
+					"player":           player,
+					"status":           avail.Status,
+					"unavailable_from": avail.UnavailableFrom,
+					"reason":           avail.Reason,
+				})
+			}
+		}
+	}
+
+	response := map[string]interface{}{
+		"match_id":            id,
+		"available_count":     len(availablePlayers),
+		"unavailable_count":   len(unavailablePlayers),
+		"unavailable_players": unavailablePlayers,
+		"home_player_count":   11 - getRedCardCount(id, match.HomeTeam.ID),
+		"away_player_count":   11 - getRedCardCount(id, match.AwayTeam.ID),
+		"status":              match.Status,
+		"timestamp":           time.Now(),
+	}
+	mutex.RUnlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+func getSeasonHistory(w http.ResponseWriter, r *http.Request) {
+	limit := 10
+	if value := r.URL.Query().Get("limit"); value != "" {
+		if parsed, err := strconv.Atoi(value); err == nil && parsed > 0 {
+			limit = min(parsed, 50)
+		}
+	}
+
+	// Most recent seasons, oldest first
+	mutex.RLock()
+	recent := seasonHistory[max(0, len(seasonHistory)-limit):]
+	history := make([]SeasonHistory, len(recent))
+	copy(history, recent)
+	mutex.RUnlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"history":   history,
+		"count":     len(history),
+		"timestamp": time.Now(),
+	})
+}
+
+// getSeason serves one archived season: its summary and awards, final standings and results
+func getSeason(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	season, err := strconv.Atoi(vars["season"])
+	if err != nil {
+		http.Error(w, "Invalid season", http.StatusBadRequest)
+		return
+	}
+	league := r.URL.Query().Get("league")
+
+	mutex.RLock()
+	defer mutex.RUnlock()
+
+	archive, exists := seasonArchives[season]
+	if !exists {
+		if season == currentSeason {
+			http.Error(w, "Season still in progress", http.StatusNotFound)
+		} else {
+			http.Error(w, "Season not found", http.StatusNotFound)
+		}
+		return
+	}
+	if league != "" {
+		if _, exists := archive.Standings[league]; !exists {
+			http.Error(w, "League not found", http.StatusNotFound)
+			return
+		}
+	}
+
+	var summary *SeasonHistory
+	for i := range seasonHistory {
+		if seasonHistory[i].Season == season {
+			summary = &seasonHistory[i]
+		}
+	}
+
+	standings := make(map[string][]LeagueTable)
+	for name, table := range archive.Standings {
+		if league == "" || name == league {
+			standings[name] = table
+		}
+	}
+	results := make([]SeasonResult, 0, len(archive.Results))
+	for _, result := range archive.Results {
+		if league == "" || result.League == league {
+			results = append(results, result)
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"season":    season,
+		"summary":   summary,
+		"standings": standings,
+		"results":   results,
+		"count":     len(results),
+		"timestamp": time.Now(),
+	})
+}
+
+func getSeasonStats(w http.ResponseWriter, r *http.Request) {
+	mutex.RLock()
+	stats := map[string]interface{}{
+		"current_season":    currentSeason,
+		"current_matchweek": currentMatchweek,
+		"season_progress":   float64(currentMatchweek) / float64(SeasonMatches) * 100,
+		"total_seasons":     len(seasonHistory),
+		"timestamp":         time.Now(),
+	}
+	mutex.RUnlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(stats)
+}
+
+func getLeagueTable(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	league := vars["league"]
+
+	options, err := parseListOptions(r, "position")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	mutex.RLock()
+	defer mutex.RUnlock()
+
+	table, exists := leagueTables[league]
+	if !exists {
+		http.Error(w, "League not found", http.StatusNotFound)
+		return
+	}
+
+	writeListResponse(w, r, table, options, "team.id", map[string]interface{}{"league": league})
+}
+
+func getAllPlayers(w http.ResponseWriter, r *http.Request) {
+	teamIDStr := r.URL.Query().Get("team_id")
+	position := r.URL.Query().Get("position")
+	search := r.URL.Query().Get("search")
+
+	options, err := parseListOptions(r, "id")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	mutex.RLock()
+	var playerList []*Player
+	for _, player := range players {
+		if teamIDStr != "" {
+			teamID, err := strconv.Atoi(teamIDStr)
+			if err == nil && player.TeamID != teamID {
+				continue
+			}
+		}
+
+		if position != "" && player.Position != position {
+			continue
+		}
+
+		if search != "" && !strings.Contains(strings.ToLower(player.Name), strings.ToLower(search)) {
+			continue
+		}
+
+		playerList = append(playerList, player)
+	}
+	writeListResponse(w, r, playerList, options, "id", nil)
+	mutex.RUnlock()
+}
+
+func getPlayer(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		http.Error(w, "Invalid player ID", http.StatusBadRequest)
+		return
+	}
+
+	mutex.RLock()
+	player, exists := players[id]
+	if !exists {
+		player, exists = retiredPlayers[id]
+	}
+	mutex.RUnlock()
+
+	if !exists {
+		http.Error(w, "Player not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(player)
+}
+
+func getAllTeams(w http.ResponseWriter, r *http.Request) {
+	league := r.URL.Query().Get("league")
+
+	options, err := parseListOptions(r, "id")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	mutex.RLock()
+	var teamList []*TeamInfo
+	for _, team := range teams {
+		if league == "" || team.League == league {
+			teamList = append(teamList, team)
+		}
+	}
+	writeListResponse(w, r, teamList, options, "id", nil)
+	mutex.RUnlock()
+}
+
+func getTeam(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		http.Error(w, "Invalid team ID", http.StatusBadRequest)
+		return
+	}
+
+	mutex.RLock()
+	team, exists := teams[id]
+	mutex.RUnlock()
+
+	if !exists {
+		http.Error(w, "Team not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(team)
+}
+
+func getGlobalStats(w http.ResponseWriter, r *http.Request) {
+	mutex.RLock()
+	stats := *globalStats
+	mutex.RUnlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(stats)
+}
+
+func searchAPI(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query().Get("q")
+	if query == "" {
+		http.Error(w, "Search query required", http.StatusBadRequest)
+		return
+	}
+
+	// Accept singular or plural type names
+	resultType := strings.TrimSuffix(strings.ToLower(r.URL.Query().Get("type")), "s")
+	if resultType != "" && resultType != "player" && resultType != "team" && resultType != "match" {
+		http.Error(w, "Invalid type (use players, teams or matches)", http.StatusBadRequest)
+		return
+	}
+
+	options, err := parseListOptions(r, "type,name")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	originalQuery := query
+	query = strings.ToLower(query)
+
+	mutex.RLock()
+	var results []map[string]interface{}
+
+	if resultType == "" || resultType == "player" {
+		for _, player := range players {
+			if strings.Contains(strings.ToLower(player.Name), query) ||
+				strings.Contains(strings.ToLower(player.Position), query) ||
+				strings.Contains(strings.ToLower(player.Nationality), query) {
+				teamName := ""
+				if team, exists := teams[player.TeamID]; exists {
+					teamName = team.Name
+				}
+				results = append(results, map[string]interface{}{
+					"key":         fmt.Sprintf("player-%d", player.ID),
+					"type":        "player",
+					"id":          player.ID,
+					"name":        player.Name,
+					"team":        teamName,
+					"team_id":     player.TeamID,
+					"position":    player.Position,
+					"nationality": player.Nationality,
+				})
+			}
+		}
+	}
+
+	if resultType == "" || resultType == "team" {
+		for _, team := range teams {
+			if strings.Contains(strings.ToLower(team.Name), query) ||
+				strings.Contains(strings.ToLower(team.League), query) ||
+				strings.Contains(strings.ToLower(team.Manager), query) {
+				results = append(results, map[string]interface{}{
+					"key":     fmt.Sprintf("team-%d", team.ID),
+					"type":    "team",
+					"id":      team.ID,
+					"name":    team.Name,
+					"league":  team.League,
+					"manager": team.Manager,
+				})
+			}
+		}
+	}
+
+	if resultType == "" || resultType == "match" {
+		for _, matchMap := range []map[int]*Match{matches, finishedMatches} {
+			for _, match := range matchMap {
+				if strings.Contains(strings.ToLower(match.HomeTeam.Name), query) ||
+					strings.Contains(strings.ToLower(match.AwayTeam.Name), query) ||
+					strings.Contains(strings.ToLower(match.Venue), query) {
+					results = append(results, map[string]interface{}{
+						"key":    fmt.Sprintf("match-%d", match.ID),
+						"type":   "match",
+						"id":     match.ID,
+						"name":   fmt.Sprintf("%s vs %s", match.HomeTeam.Name, match.AwayTeam.Name),
+						"league": match.Competition,
+						"status": match.Status,
+						"score":  fmt.Sprintf("%d-%d", match.HomeScore, match.AwayScore),
+					})
+				}
+			}
+		}
+	}
+	mutex.RUnlock()
+
+	writeListResponse(w, r, results, options, "key", map[string]interface{}{"query": originalQuery})
+}
+
+// Prometheus metrics in the text exposition format
+type histogram struct {
+	buckets []float64
+	counts  []uint64 // Per bucket, cumulated when written
+	sum     float64
+	count   uint64
+}
+
+type requestKey struct {
+	method string
+	route  string
+	status int
+}
+
+type routeKey struct {
+	method string
+	route  string
+}
+
+type metricsRegistry struct {
+	sync.Mutex
+	requests        map[requestKey]uint64
+	requestDuration map[routeKey]*histogram
+	goals           map[string]uint64 // League -> goals
+	events          map[string]uint64 // Event type -> count
+	tickDuration    *histogram
+	mutexWait       map[string]*histogram // Lock site -> wait time
+}
+
+var (
+	latencyBuckets = []float64{0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5}
+	waitBuckets    = []float64{0.00001, 0.0001, 0.001, 0.005, 0.01, 0.05, 0.1, 0.5, 1}
+
+	metrics = &metricsRegistry{
+		requests:        make(map[requestKey]uint64),
+		requestDuration: make(map[routeKey]*histogram),
+		goals:           make(map[string]uint64),
+		events:          make(map[string]uint64),
+		tickDuration:    newHistogram(latencyBuckets),
+		mutexWait:       make(map[string]*histogram),
+	}
+)
+
+func newHistogram(buckets []float64) *histogram {
+	return &histogram{buckets: buckets, counts: make([]uint64, len(buckets))}
+}
+
+func (h *histogram) observe(value float64) {
+	for i, bound := range h.buckets {
+		if value <= bound {
+			h.counts[i]++
+			break
+		}
+	}
+	h.sum += value
+	h.count++
+}
+
+// Captures the status code written by a handler
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func metricsMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(recorder, r)
+
+		// Label by route template to keep cardinality bounded
+		route := "unmatched"
+		if current := mux.CurrentRoute(r); current != nil {
+			if template, err := current.GetPathTemplate(); err == nil {
+				route = template
+			}
+		}
+
+		metrics.Lock()
+		metrics.requests[requestKey{r.Method, route, recorder.status}]++
+		key := routeKey{r.Method, route}
+		if metrics.requestDuration[key] == nil {
+			metrics.requestDuration[key] = newHistogram(latencyBuckets)
+		}
+		metrics.requestDuration[key].observe(time.Since(start).Seconds())
+		metrics.Unlock()
+	})
+}
+
+// Acquire the main mutex and record how long the caller waited. Every writer takes the lock here
+func lockMutex(site string) {
+	start := time.Now()
+	mutex.Lock()
+	wait := time.Since(start).Seconds()
+
+	metrics.Lock()
+	if metrics.mutexWait[site] == nil {
+		metrics.mutexWait[site] = newHistogram(waitBuckets)
+	}
+	metrics.mutexWait[site].observe(wait)
+	metrics.Unlock()
+}
+
+func recordGoalMetric(league string) {
+	metrics.Lock()
+	metrics.goals[league]++
+	metrics.Unlock()
+}
+
+func recordEventMetric(eventType string) {
+	metrics.Lock()
+	metrics.events[eventType]++
+	metrics.Unlock()
+}
+
+func recordTickMetric(duration time.Duration) {
+	metrics.Lock()
+	metrics.tickDuration.observe(duration.Seconds())
+	metrics.Unlock()
+}
+
+func getMetrics(w http.ResponseWriter, r *http.Request) {
+	var out strings.Builder
+
+	// Simulation gauges
+	mutex.RLock()
+	matchCounts := make(map[[2]string]int)
+	activeMatches := 0
+	for _, match := range matches {
+		matchCounts[[2]string{match.Competition, match.Status}]++
+		if match.Status == StatusLive || match.Status == StatusHalftime || match.Status == StatusBreak || match.Status == StatusVARCheck {
+			activeMatches++
+		}
+	}
+	finishedCount := len(finishedMatches)
+	playerCount := len(players)
+	season, matchweek := currentSeason, currentMatchweek
+	mutex.RUnlock()
+
+	writeMetricHeader(&out, "matchpulse_active_matches", "gauge", "Matches live, at halftime or in a break")
+	fmt.Fprintf(&out, "matchpulse_active_matches %d\n", activeMatches)
+
+	writeMetricHeader(&out, "matchpulse_matches", "gauge", "Matches in memory by league and status")
+	for _, league := range []string{LeaguePremier, LeagueCommunityLeague} {
+		for _, status := range []string{StatusLive, StatusVARCheck, StatusHalftime, StatusBreak, StatusFinished} {
+			fmt.Fprintf(&out, "matchpulse_matches{league=%q,status=%q} %d\n",
+				league, status, matchCounts[[2]string{league, status}])
+		}
+	}
+
+	writeMetricHeader(&out, "matchpulse_finished_matches_stored", "gauge", "Finished matches kept for this season")
+	fmt.Fprintf(&out, "matchpulse_finished_matches_stored %d\n", finishedCount)
+	writeMetricHeader(&out, "matchpulse_players", "gauge", "Active players")
+	fmt.Fprintf(&out, "matchpulse_players %d\n", playerCount)
+	writeMetricHeader(&out, "matchpulse_season", "gauge", "Current season number")
+	fmt.Fprintf(&out, "matchpulse_season %d\n", season)
+	writeMetricHeader(&out, "matchpulse_matchweek", "gauge", "Current matchweek")
+	fmt.Fprintf(&out, "matchpulse_matchweek %d\n", matchweek)
+
+	metrics.Lock()
+	writeMetricHeader(&out, "matchpulse_goals_total", "counter", "Goals scored by league")
+	for _, league := range []string{LeaguePremier, LeagueCommunityLeague} {
+		fmt.Fprintf(&out, "matchpulse_goals_total{league=%q} %d\n", league, metrics.goals[league])
+	}
+
+	writeMetricHeader(&out, "matchpulse_events_total", "counter", "Match events generated by type")
+	eventTypes := make([]string, 0, len(metrics.events))
+	for eventType := range metrics.events {
+		eventTypes = append(eventTypes, eventType)
+	}
+	sort.Strings(eventTypes)
+	for _, eventType := range eventTypes {
+		fmt.Fprintf(&out, "matchpulse_events_total{type=%q} %d\n", eventType, metrics.events[eventType])
+	}
+
+	writeMetricHeader(&out, "matchpulse_engine_tick_duration_seconds", "histogram", "Time spent in each match engine tick")
+	writeHistogram(&out, "matchpulse_engine_tick_duration_seconds", "", metrics.tickDuration)
+
+	writeMetricHeader(&out, "matchpulse_mutex_wait_seconds", "histogram", "Time spent waiting for the simulation mutex")
+	sites := make([]string, 0, len(metrics.mutexWait))
+	for site := range metrics.mutexWait {
+		sites = append(sites, site)
+	}
+	sort.Strings(sites)
+	for _, site := range sites {
+		writeHistogram(&out, "matchpulse_mutex_wait_seconds", fmt.Sprintf("site=%q", site), metrics.mutexWait[site])
+	}
+
+	writeMetricHeader(&out, "matchpulse_http_requests_total", "counter", "HTTP requests by method, route and status")
+	requestKeys := make([]requestKey, 0, len(metrics.requests))
+	for key := range metrics.requests {
+		requestKeys = append(requestKeys, key)
+	}
+	sort.Slice(requestKeys, func(i, j int) bool {
+		if requestKeys[i].route != requestKeys[j].route {
+			return requestKeys[i].route < requestKeys[j].route
+		}
+		if requestKeys[i].method != requestKeys[j].method {
+			return requestKeys[i].method < requestKeys[j].method
+		}
+		return requestKeys[i].status < requestKeys[j].status
+	})
+	for _, key := range requestKeys {
+		fmt.Fprintf(&out, "matchpulse_http_requests_total{method=%q,route=%q,status=\"%d\"} %d\n",
+			key.method, key.route, key.status, metrics.requests[key])
+	}
+
+	writeMetricHeader(&out, "matchpulse_http_request_duration_seconds", "histogram", "HTTP request latency by method and route")
+	routeKeys := make([]routeKey, 0, len(metrics.requestDuration))
+	for key := range metrics.requestDuration {
+		routeKeys = append(routeKeys, key)
+	}
+	sort.Slice(routeKeys, func(i, j int) bool {
+		if routeKeys[i].route != routeKeys[j].route {
+			return routeKeys[i].route < routeKeys[j].route
+		}
+		return routeKeys[i].method < routeKeys[j].method
+	})
+	for _, key := range routeKeys {
+		labels := fmt.Sprintf("method=%q,route=%q", key.method, key.route)
+		writeHistogram(&out, "matchpulse_http_request_duration_seconds", labels, metrics.requestDuration[key])
+	}
+	metrics.Unlock()
+
+	// Go runtime
+	var memStats runtime.MemStats
+	runtime.ReadMemStats(&memStats)
+	writeMetricHeader(&out, "go_goroutines", "gauge", "Number of goroutines that currently exist")
+	fmt.Fprintf(&out, "go_goroutines %d\n", runtime.NumGoroutine())
+	writeMetricHeader(&out, "go_memstats_alloc_bytes", "gauge", "Bytes allocated and still in use")
+	fmt.Fprintf(&out, "go_memstats_alloc_bytes %d\n", memStats.Alloc)
+	writeMetricHeader(&out, "go_memstats_alloc_bytes_total", "counter", "Total bytes allocated, even if freed")
+	fmt.Fprintf(&out, "go_memstats_alloc_bytes_total %d\n", memStats.TotalAlloc)
+	writeMetricHeader(&out, "go_memstats_sys_bytes", "gauge", "Bytes obtained from the system")
+	fmt.Fprintf(&out, "go_memstats_sys_bytes %d\n", memStats.Sys)
+	writeMetricHeader(&out, "go_memstats_heap_objects", "gauge", "Number of allocated heap objects")
+	fmt.Fprintf(&out, "go_memstats_heap_objects %d\n", memStats.HeapObjects)
+	writeMetricHeader(&out, "go_gc_cycles_total", "counter", "Completed garbage collection cycles")
+	fmt.Fprintf(&out, "go_gc_cycles_total %d\n", memStats.NumGC)
+
+	writeMetricHeader(&out, "process_start_time_seconds", "gauge", "Start time of the process since unix epoch in seconds")
+	fmt.Fprintf(&out, "process_start_time_seconds %d\n", startTime.Unix())
+	writeMetricHeader(&out, "matchpulse_build_info", "gauge", "Build information")
+	fmt.Fprintf(&out, "matchpulse_build_info{version=%q} 1\n", version)
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	w.Write([]byte(out.String()))
+}
+
+func writeMetricHeader(out *strings.Builder, name, metricType, help string) {
+	fmt.Fprintf(out, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, metricType)
+}
+
+func writeHistogram(out *strings.Builder, name, labels string, h *histogram) {
+	prefix := ""
+	if labels != "" {
+		prefix = labels + ","
+	}
+
+	cumulative := uint64(0)
+	for i, bound := range h.buckets {
+		cumulative += h.counts[i]
+		fmt.Fprintf(out, "%s_bucket{%sle=\"%s\"} %d\n", name, prefix, strconv.FormatFloat(bound, 'g', -1, 64), cumulative)
+	}
+	fmt.Fprintf(out, "%s_bucket{%sle=\"+Inf\"} %d\n", name, prefix, h.count)
+
+	if labels != "" {
+		labels = "{" + labels + "}"
+	}
+	fmt.Fprintf(out, "%s_sum%s %g\n", name, labels, h.sum)
+	fmt.Fprintf(out, "%s_count%s %d\n", name, labels, h.count)
+}
+
+// For goroutine monitoring - the same figures are exported on /metrics
+func getGoroutineStats() map[string]interface{} {
+	numGoroutines := runtime.NumGoroutine()
+
+	// Get memory stats
+	var memStats runtime.MemStats
+	runtime.ReadMemStats(&memStats)
+
+	return map[string]interface{}{
+		"goroutine_count": numGoroutines,
+		"memory_alloc":    memStats.Alloc,
+		"memory_total":    memStats.TotalAlloc,
+		"memory_sys":      memStats.Sys,
+		"num_gc":          memStats.NumGC,
+		"status":          getGoroutineStatus(numGoroutines),
+	}
+}
+
+func getGoroutineStatus(count int) string {
+	if count < 50 {
+		return "healthy"
+	} else if count < 100 {
+		return "warning"
+	} else {
+		return "critical"
+	}
+}
+
+func healthCheck(w http.ResponseWriter, r *http.Request) {
+	mutex.RLock()
+	matchCount := len(matches)
+	playerCount := len(players)
+	teamCount := len(teams)
+
+	// Get goroutine stats
+	goroutineStats := getGoroutineStats()
+
+	// Get active matches count
+	activeMatches := 0
+	for _, match := range matches {
+		if match.Status == StatusLive || match.Status == StatusHalftime || match.Status == StatusVARCheck {
+			activeMatches++
+		}
+	}
+
+	// Get current season progress
+	seasonProgress := float64(currentMatchweek) / float64(SeasonMatches) * 100
+
+	// Get system uptime
+	uptime := time.Since(startTime).Round(time.Second)
+
+	// Get memory stats
+	var memStats runtime.MemStats
+	runtime.ReadMemStats(&memStats)
+
+	// Prepare detailed health check response
+	healthData := map[string]interface{}{
+		"status":          "healthy",
+		"name":            "MatchPulse API",
+		"version":         version,
+		"uptime":          uptime.String(),
+		"active_matches":  activeMatches,
+		"max_matches":     MaxSimultaneousMatches,
+		"total_matches":   matchCount,
+		"total_players":   playerCount,
+		"total_teams":     teamCount,
+		"current_season":  currentSeason,
+		"matchweek":       currentMatchweek,
+		"season_progress": fmt.Sprintf("%.1f%%", seasonProgress),
+		"goroutines":      goroutineStats,
+		"memory": map[string]interface{}{
+			"alloc":       memStats.Alloc,
+			"total_alloc": memStats.TotalAlloc,
+			"sys":         memStats.Sys,
+			"num_gc":      memStats.NumGC,
+		},
+		"timestamp": time.Now(),
+	}
+
+	// Log health check details
+	logWithFields(LevelDebug, LogFields{Component: "api"}, "🏥 Health Check: %d/%d active matches, %d total matches, %d goroutines (%s)",
+		activeMatches, MaxSimultaneousMatches, matchCount, goroutineStats["goroutine_count"], goroutineStats["status"])
+
+	mutex.RUnlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(healthData)
+}
+
+func serveHomepage(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+
+	mutex.RLock()
+	// Find first live match ID
+	var firstLiveMatchID int
+	for id, match := range matches {
+		if match.Status == StatusLive {
+			firstLiveMatchID = id
+			break
+		}
+	}
+
+	// If no live match found, find the first match in the map
+	if firstLiveMatchID == 0 && len(matches) > 0 {
+		for id := range matches {
+			firstLiveMatchID = id
+			break
+		}
+	}
+
+	templateData := struct {
+		ActiveMatches    int
+		TotalPlayers     int
+		TotalTeams       int
+		CurrentSeason    int
+		CurrentMatchweek int
+		LastUpdated      string
+		Version          string
+		FirstLiveMatchID int
+	}{
+		ActiveMatches:    len(matches),
+		TotalPlayers:     len(players),
+		TotalTeams:       len(teams),
+		CurrentSeason:    currentSeason,
+		CurrentMatchweek: currentMatchweek,
+		LastUpdated:      time.Now().Format("2006-01-02 15:04:05"),
+		Version:          version,
+		FirstLiveMatchID: firstLiveMatchID,
+	}
+	mutex.RUnlock()
+
+	const htmlTemplate = `<!DOCTYPE html>
+<html>
+<head>
+    <title>MatchPulse API v{{.Version}}</title>
+    <style>
+        body {
+            font-family: system-ui, -apple-system, sans-serif;
+            margin: 0;
+            padding: 20px;
+            background: linear-gradient(135deg, #667eea 0%, #764ba2 100%);
+            min-height: 100vh;
+            box-sizing: border-box;
+        }
+        .container {
+            max-width: 1400px;
+            margin: 0 auto;
             background: white;
             padding: 30px;
             border-radius: 12px;
@@ -2702,7 +6878,7 @@ func serveHomepage(w http.ResponseWriter, r *http.Request) {
                         <li><a href="/api/v1/players">All Players</a></li>
                         <li><a href="/api/v1/players?position=ST">Strikers</a></li>
                         <li><a href="/api/v1/players?position=GK">Goalkeepers</a></li>
-                        <li><a href="/api/v1/players?team=1">Team Players</a></li>
+                        <li><a href="/api/v1/players?team_id=1">Team Players</a></li>
                         <li><a href="/api/v1/players/1">Player Details</a></li>
                     </ul>
                 </div>
@@ -2785,564 +6961,3792 @@ func serveHomepage(w http.ResponseWriter, r *http.Request) {
 </body>
 </html>`
 
-	tmpl, err := template.New("homepage").Parse(htmlTemplate)
-	if err != nil {
-		http.Error(w, "Template error", http.StatusInternalServerError)
+	tmpl, err := template.New("homepage").Parse(htmlTemplate)
+	if err != nil {
+		http.Error(w, "Template error", http.StatusInternalServerError)
+		return
+	}
+
+	err = tmpl.Execute(w, templateData)
+	if err != nil {
+		http.Error(w, "Template execution error", http.StatusInternalServerError)
+		return
+	}
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func shouldEndSeason() bool {
+	// Wait for the last matches to finish, not just kick off
+	for _, match := range matches {
+		if match.Status == StatusLive || match.Status == StatusHalftime || match.Status == StatusVARCheck {
+			return false
+		}
+	}
+
+	// Check if all matches in all leagues are finished
+	for _, league := range []string{LeaguePremier, LeagueCommunityLeague} {
+		if schedules, exists := seasonSchedules[league]; exists {
+			for _, schedule := range schedules {
+				if !schedule.IsPlayed {
+					return false
+				}
+			}
+		}
+	}
+	return true
+}
+
+// Called with mutex held, straight after endSeason. This is the only place the season
+// number advances; the fixtures are regenerated here for the new season
+func startNewSeason() {
+	logWithFields(LevelInfo, LogFields{Component: "season"}, "🎬 Starting new season %d...", currentSeason+1)
+	currentSeason++
+	currentMatchweek = 1
+
+	// Clear finished matches at the start of new season, with everything recorded per match.
+	// Match IDs are never reused, so nothing would ever read these entries again
+	for matchID := range finishedMatches {
+		delete(matchStats, matchID)
+		delete(liveCommentary, matchID)
+		delete(matchTimelines, matchID)
+		delete(matchLineups, matchID)
+		delete(matchPlayerStats, matchID)
+		delete(matchTracking, matchID)
+		delete(matchPasses, matchID)
+		delete(playerLocations, matchID)
+		delete(matchScorers, matchID)
+		delete(matchMomentum, matchID)
+		delete(playerAvailability, matchID)
+		delete(dynamicProbabilities, matchID)
+		delete(ballPositions, matchID)
+		delete(matchTactics, matchID)
+		delete(tacticsHistory, matchID)
+		delete(matchVARReviews, matchID)
+		delete(matchOdds, matchID)
+		delete(oddsHistory, matchID)
+	}
+	finishedMatches = make(map[int]*Match)
+
+	// Generate the new season's fixtures
+	schedulingMutex.Lock()
+	for league := range leagueConfigs {
+		generateSeasonSchedule(league)
+	}
+	schedulingMutex.Unlock()
+}
+
+func batchUpdatePlayerStats() {
+	// Batch update player statistics to reduce lock contention
+	lockMutex("statistics")
+	defer mutex.Unlock()
+
+	playersUpdated := 0
+	for _, player := range players {
+		if player.SeasonStats.MatchesPlayed > 0 {
+			player.SeasonStats.AverageRating = player.SeasonStats.TotalRating / float64(player.SeasonStats.MatchesPlayed)
+			playersUpdated++
+		}
+	}
+
+	if playersUpdated > 0 {
+		logDebug("📈 Updated stats for %d players", playersUpdated)
+	}
+}
+
+func batchUpdateTeamStats() {
+	// Update team statistics in batches
+	updateGlobalStats()
+}
+
+func endSeason() {
+	logWithFields(LevelInfo, LogFields{Component: "season"}, "🏆 Season %d ended! Calculating final standings...", currentSeason)
+
+	// Calculate season winners and stats
+	seasonWinner := calculateSeasonWinner()
+	topScorer := findTopScorer()
+	topAssists := findTopAssists()
+	mostFouls := findMostFouls()
+	playerOfSeason := findPlayerOfSeason()
+
+	// Archive final tables and results before they are reset
+	archive := archiveSeason()
+
+	// Store season history
+	seasonRecord := SeasonHistory{
+		Season:          currentSeason,
+		TopScorer:       playerOrEmpty(topScorer),
+		TopAssists:      playerOrEmpty(topAssists),
+		MostFouls:       playerOrEmpty(mostFouls),
+		PlayerOfSeason:  playerOrEmpty(playerOfSeason),
+		Champion:        *seasonWinner,
+		TotalMatches:    len(archive.Results),
+		EndDate:         time.Now(),
+		TeamOfSeason:    selectTeamOfSeason(),
+		BestYoungPlayer: findBestYoungPlayer(),
+		BestGoalkeeper:  findBestGoalkeeper(archive.Results),
+		ManagerOfSeason: findManagerOfSeason(),
+	}
+	for _, league := range []string{LeaguePremier, LeagueCommunityLeague} {
+		table := archive.Standings[league]
+		if len(table) == 0 {
+			continue
+		}
+		record := seasonLeagueRecord(league, table[0].Team, archive.Results)
+		seasonRecord.Leagues = append(seasonRecord.Leagues, record)
+		seasonRecord.TotalGoals += record.TotalGoals
+	}
+	for i := range seasonRecord.Leagues {
+		champion := &seasonRecord.Leagues[i].Champion
+		switch champion.League {
+		case LeaguePremier:
+			seasonRecord.Winners.PremierLeagueWinner = champion
+		case LeagueCommunityLeague:
+			seasonRecord.Winners.CommunityLeagueWinner = champion
+		}
+	}
+
+	// Every season is kept; results and tables live in the archive
+	seasonHistory = append(seasonHistory, seasonRecord)
+	seasonArchives[currentSeason] = archive
+	checkTitleRecord(&seasonRecord)
+
+	logWithFields(LevelInfo, LogFields{Component: "season"}, "🥇 Season %d Champions: %s", currentSeason, seasonWinner.Name)
+	if topScorer != nil {
+		logInfo("⚽ Top Scorer: %s (%d goals)", topScorer.Name, topScorer.SeasonStats.GoalsThisSeason)
+	}
+	if topAssists != nil {
+		logInfo("🅰️  Top Assists: %s (%d assists)", topAssists.Name, topAssists.SeasonStats.AssistsThisSeason)
+	}
+	generateSeasonEndNews(seasonWinner, topScorer, playerOfSeason)
+	generateSeasonAwardsNews(&seasonRecord)
+
+	// Pay prize money while the final tables are still available
+	awardPrizeMoney()
+
+	// Reset for new season
+	resetForNewSeason()
+
+	// Clubs trade players before the new season starts
+	runTransferWindow()
+}
+
+// playerOrEmpty copies an award winner into the season history; no winner is an empty player
+func playerOrEmpty(player *Player) Player {
+	if player == nil {
+		return Player{}
+	}
+	return *player
+}
+
+func calculateSeasonWinner() *TeamInfo {
+	// Get the team at the top of Premier League table (or default to first team)
+	if table, exists := leagueTables[LeaguePremier]; exists && len(table) > 0 {
+		return &table[0].Team
+	}
+
+	// Fallback to any team
+	for _, team := range teams {
+		return team
+	}
+
+	return &TeamInfo{Name: "Unknown"}
+}
+
+// findTopScorer returns the player with the most goals this season, or nil before anyone scores
+func findTopScorer() *Player {
+	var topScorer *Player
+	maxGoals := 0
+
+	for _, player := range players {
+		if player.SeasonStats.GoalsThisSeason > maxGoals {
+			maxGoals = player.SeasonStats.GoalsThisSeason
+			topScorer = player
+		}
+	}
+
+	return topScorer
+}
+
+// findTopAssists returns the player with the most assists this season, or nil if there are none
+func findTopAssists() *Player {
+	var topAssists *Player
+	maxAssists := 0
+
+	for _, player := range players {
+		if player.SeasonStats.AssistsThisSeason > maxAssists {
+			maxAssists = player.SeasonStats.AssistsThisSeason
+			topAssists = player
+		}
+	}
+
+	return topAssists
+}
+
+// findMostFouls returns the most booked player this season (a red counts twice), or nil if nobody was booked
+func findMostFouls() *Player {
+	var mostFouls *Player
+	maxFouls := 0
+
+	for _, player := range players {
+		totalFouls := player.SeasonStats.YellowCardsThisSeason + player.SeasonStats.RedCardsThisSeason*2
+		if totalFouls > maxFouls {
+			maxFouls = totalFouls
+			mostFouls = player
+		}
+	}
+
+	return mostFouls
+}
+
+// findPlayerOfSeason returns the best average rating over 10 or more matches, or nil if nobody qualifies
+func findPlayerOfSeason() *Player {
+	var playerOfSeason *Player
+	maxRating := 0.0
+
+	for _, player := range players {
+		if player.SeasonStats.AverageRating > maxRating && player.SeasonStats.MatchesPlayed >= 10 {
+			maxRating = player.SeasonStats.AverageRating
+			playerOfSeason = player
+		}
+	}
+
+	return playerOfSeason
+}
+
+// archiveSeason copies every league's final table and finished results. Caller must hold mutex
+func archiveSeason() *SeasonArchive {
+	archive := &SeasonArchive{Standings: make(map[string][]LeagueTable), Results: []SeasonResult{}}
+
+	for league, table := range leagueTables {
+		standings := make([]LeagueTable, 0, len(table))
+		for _, entry := range table {
+			standings = append(standings, *entry)
+		}
+		archive.Standings[league] = standings
+	}
+
+	for league, schedules := range seasonSchedules {
+		for _, schedule := range schedules {
+			if !schedule.IsPlayed || schedule.MatchID == 0 {
+				continue
+			}
+			match := findMatch(schedule.MatchID)
+			if match == nil || match.Status != StatusFinished {
+				continue
+			}
+			archive.Results = append(archive.Results, SeasonResult{
+				MatchID:    match.ID,
+				League:     league,
+				Matchweek:  match.MatchweekNum,
+				HomeTeamID: match.HomeTeam.ID,
+				HomeTeam:   match.HomeTeam.Name,
+				AwayTeamID: match.AwayTeam.ID,
+				AwayTeam:   match.AwayTeam.Name,
+				HomeScore:  match.HomeScore,
+				AwayScore:  match.AwayScore,
+				Attendance: match.Attendance,
+				Date:       match.StartTime,
+			})
+		}
+	}
+
+	sort.Slice(archive.Results, func(i, j int) bool {
+		a, b := archive.Results[i], archive.Results[j]
+		if a.League != b.League {
+			return a.League > b.League // Premier League first
+		}
+		if a.Matchweek != b.Matchweek {
+			return a.Matchweek < b.Matchweek
+		}
+		return a.MatchID < b.MatchID
+	})
+	return archive
+}
+
+// seasonLeagueRecord sums a league's season and names its scoring and assist leaders
+func seasonLeagueRecord(league string, champion TeamInfo, results []SeasonResult) SeasonLeagueRecord {
+	record := SeasonLeagueRecord{
+		League:     league,
+		Champion:   champion,
+		GoldenBoot: seasonLeaders(league, func(p *Player) int { return p.SeasonStats.GoalsThisSeason }),
+		TopAssists: seasonLeaders(league, func(p *Player) int { return p.SeasonStats.AssistsThisSeason }),
+	}
+	for _, result := range results {
+		if result.League == league {
+			record.TotalGoals += result.HomeScore + result.AwayScore
+			record.TotalMatches++
+		}
+	}
+	return record
+}
+
+// seasonLeaders returns a league's best players by a season count, fewest matches breaking ties
+func seasonLeaders(league string, count func(*Player) int) []SeasonAward {
+	var candidates []*Player
+	for _, player := range players {
+		if team := teams[player.TeamID]; team != nil && team.League == league && count(player) > 0 {
+			candidates = append(candidates, player)
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if count(a) != count(b) {
+			return count(a) > count(b)
+		}
+		if a.SeasonStats.MatchesPlayed != b.SeasonStats.MatchesPlayed {
+			return a.SeasonStats.MatchesPlayed < b.SeasonStats.MatchesPlayed
+		}
+		return a.ID < b.ID
+	})
+
+	leaders := []SeasonAward{}
+	for _, player := range candidates[:min(len(candidates), SeasonAwardLeaders)] {
+		leaders = append(leaders, seasonAward(player, float64(count(player))))
+	}
+	return leaders
+}
+
+func seasonAward(player *Player, value float64) SeasonAward {
+	award := SeasonAward{
+		PlayerID:   player.ID,
+		PlayerName: player.Name,
+		Position:   player.Position,
+		Age:        player.Age,
+		TeamID:     player.TeamID,
+		Value:      value,
+		Matches:    player.SeasonStats.MatchesPlayed,
+	}
+	if team := teams[player.TeamID]; team != nil {
+		award.TeamName, award.League = team.Name, team.League
+	}
+	return award
+}
+
+// seasonAverageRating is a player's average match rating, or 0 below the award threshold
+func seasonAverageRating(player *Player) float64 {
+	if player.SeasonStats.MatchesPlayed < MinAwardAppearances {
+		return 0
+	}
+	return math.Round(player.SeasonStats.TotalRating/float64(player.SeasonStats.MatchesPlayed)*100) / 100
+}
+
+// ratedPlayers returns players eligible for rating awards, best average first
+func ratedPlayers() []*Player {
+	var rated []*Player
+	for _, player := range players {
+		if seasonAverageRating(player) > 0 {
+			rated = append(rated, player)
+		}
+	}
+	sort.Slice(rated, func(i, j int) bool {
+		if seasonAverageRating(rated[i]) != seasonAverageRating(rated[j]) {
+			return seasonAverageRating(rated[i]) > seasonAverageRating(rated[j])
+		}
+		return rated[i].ID < rated[j].ID
+	})
+	return rated
+}
+
+// selectTeamOfSeason picks the best-rated 4-3-3 across both leagues
+func selectTeamOfSeason() []SeasonAward {
+	slots := map[string]int{"GK": 1, "DEF": 4, "MID": 3, "FWD": 3}
+	team := []SeasonAward{}
+	for _, player := range ratedPlayers() {
+		group := fantasyPositionGroup(player.Position)
+		if slots[group] == 0 {
+			continue
+		}
+		slots[group]--
+		team = append(team, seasonAward(player, seasonAverageRating(player)))
+	}
+
+	// Goalkeeper first, forwards last
+	order := map[string]int{"GK": 0, "DEF": 1, "MID": 2, "FWD": 3}
+	sort.SliceStable(team, func(i, j int) bool {
+		return order[fantasyPositionGroup(team[i].Position)] < order[fantasyPositionGroup(team[j].Position)]
+	})
+	return team
+}
+
+// findBestYoungPlayer returns the best-rated player aged YoungPlayerMaxAge or under
+func findBestYoungPlayer() *SeasonAward {
+	for _, player := range ratedPlayers() {
+		if player.Age <= YoungPlayerMaxAge {
+			award := seasonAward(player, seasonAverageRating(player))
+			return &award
+		}
+	}
+	return nil
+}
+
+// findBestGoalkeeper returns the goalkeeper with the most clean sheets in the season's results
+func findBestGoalkeeper(results []SeasonResult) *SeasonAward {
+	cleanSheets := make(map[int]int)
+	for _, result := range results {
+		for playerID, line := range matchPlayerStats[result.MatchID] {
+			if line.Position != PosGK {
+				continue
+			}
+			if (line.TeamID == result.HomeTeamID && result.AwayScore == 0) ||
+				(line.TeamID == result.AwayTeamID && result.HomeScore == 0) {
+				cleanSheets[playerID]++
+			}
+		}
+	}
+
+	var best *Player
+	for playerID, count := range cleanSheets {
+		player := players[playerID]
+		if player == nil {
+			continue
+		}
+		if best == nil || count > cleanSheets[best.ID] || (count == cleanSheets[best.ID] && player.ID < best.ID) {
+			best = player
+		}
+	}
+	if best == nil {
+		return nil
+	}
+	award := seasonAward(best, float64(cleanSheets[best.ID]))
+	return &award
+}
+
+// findManagerOfSeason rewards the side that most outperformed expectations, by Elo gained this season
+func findManagerOfSeason() *ManagerAward {
+	var award *ManagerAward
+	for teamID, history := range ratingHistory {
+		team := teams[teamID]
+		if team == nil {
+			continue
+		}
+		gain := 0.0
+		for _, change := range history {
+			if change.Season == currentSeason {
+				gain += change.Change
+			}
+		}
+		gain = math.Round(gain*10) / 10
+		if award != nil && (gain < award.RatingGain || (gain == award.RatingGain && teamID > award.TeamID)) {
+			continue
+		}
+
+		award = &ManagerAward{Manager: team.Manager, TeamID: team.ID, TeamName: team.Name, League: team.League, RatingGain: gain}
+		for _, entry := range leagueTables[team.League] {
+			if entry.Team.ID == team.ID {
+				award.Position, award.Points = entry.Position, entry.Points
+			}
+		}
+	}
+	return award
+}
+
+func resetForNewSeason() {
+	logInfo("🔄 Resetting for season %d...", currentSeason+1)
+
+	// Keep every player's season line for their career, retirees included
+	for _, player := range players {
+		if player.SeasonStats.MatchesPlayed > 0 {
+			playerCareers[player.ID] = append(playerCareers[player.ID], playerSeasonRecord(player, false))
+		}
+	}
+
+	// Develop, age and retire players before their season stats are cleared
+	developPlayers()
+
+	// Reset season stats for all players
+	playersReset := 0
+	for _, player := range players {
+		// Career totals are kept up to date live, so only the season line is cleared
+		player.SeasonStats = PlayerSeasonStats{}
+		player.CurrentRating = 6.0
+		playersReset++
+	}
+
+	// Reset home crowd tallies
+	for _, team := range teams {
+		team.AverageAttendance, team.AverageOccupancy = 0, 0
+		team.homeGates, team.totalAttendance = 0, 0
+	}
+
+	// Reset league tables
+	initializeLeagueTables()
+
+	logInfo("📊 Reset complete: %d players reset, league tables reinitialized", playersReset)
+}
+
+// Season-end player development, aging and retirement
+func developPlayers() {
+	improved, declined, retired := 0, 0, 0
+	maxMinutes := float64(MatchesPerTeam * MatchDurationSeconds)
+
+	for _, player := range players {
+		playingTime := math.Min(1.0, float64(player.SeasonStats.MinutesPlayed)/maxMinutes)
+		change := calculateDevelopmentChange(player, playingTime)
+
+		previousOverall := player.Characteristics.Overall
+		applyDevelopment(&player.Characteristics, change, player.Age)
+		player.Age++
+		player.MarketValue = calculateMarketValue(player.Characteristics, player.Age)
+
+		player.Development = append(player.Development, PlayerDevelopment{
+			Season:      currentSeason,
+			Age:         player.Age,
+			Overall:     player.Characteristics.Overall,
+			Change:      player.Characteristics.Overall - previousOverall,
+			MarketValue: player.MarketValue,
+		})
+		if len(player.Development) > MaxSeasonHistory {
+			player.Development = player.Development[1:]
+		}
+
+		if player.Characteristics.Overall > previousOverall {
+			improved++
+		} else if player.Characteristics.Overall < previousOverall {
+			declined++
+		}
+	}
+
+	// Retire veterans and promote a youth player in their place
+	for _, player := range players {
+		if !shouldRetire(player) {
+			continue
+		}
+		youth := generateYouthPlayer(player)
+		retirePlayer(player, youth)
+		retired++
+	}
+
+	logWithFields(LevelInfo, LogFields{Component: "season"}, "🌱 Player development: %d improved, %d declined, %d retired and replaced by youth players",
+		improved, declined, retired)
+}
+
+// Overall rating change from age, minutes played and average rating
+func calculateDevelopmentChange(player *Player, playingTime float64) int {
+	var change float64
+	switch {
+	case player.Age <= 21:
+		change = 2 + rand.Float64()*3
+	case player.Age <= 24:
+		change = 1 + rand.Float64()*2
+	case player.Age <= 29:
+		change = rand.Float64()*2 - 1
+	case player.Age <= 32:
+		change = -1 - rand.Float64()*2
+	default:
+		change = -2 - rand.Float64()*3
+	}
+
+	// Young players only reach their potential with minutes
+	if change > 0 {
+		change *= 0.4 + 0.6*playingTime
+	}
+
+	if player.SeasonStats.MatchesPlayed > 0 {
+		if player.SeasonStats.AverageRating >= 7.0 {
+			change += 1
+		} else if player.SeasonStats.AverageRating < 6.0 {
+			change -= 1
+		}
+	}
+
+	return int(math.Round(change))
+}
+
+func applyDevelopment(characteristics *PlayerCharacteristics, change, age int) {
+	adjust := func(value, extra int) int {
+		return max(1, min(99, value+change+extra+rand.Intn(3)-1))
+	}
+
+	// Veterans lose pace and power first but keep improving their reading of the game
+	physicalExtra, mentalExtra := 0, 0
+	if age >= 30 {
+		physicalExtra, mentalExtra = -1, 1
+	}
+
+	characteristics.Speed = adjust(characteristics.Speed, physicalExtra)
+	characteristics.Shooting = adjust(characteristics.Shooting, 0)
+	characteristics.Passing = adjust(characteristics.Passing, 0)
+	characteristics.Defending = adjust(characteristics.Defending, 0)
+	characteristics.Physicality = adjust(characteristics.Physicality, physicalExtra)
+	characteristics.Mentality = adjust(characteristics.Mentality, mentalExtra)
+	characteristics.Overall = (characteristics.Speed + characteristics.Shooting + characteristics.Passing +
+		characteristics.Defending + characteristics.Physicality + characteristics.Mentality) / 6
+}
+
+func shouldRetire(player *Player) bool {
+	if player.Age >= MaxPlayerAge {
+		return true
+	}
+	if player.Age >= RetirementAge {
+		return rand.Float64() < float64(player.Age-RetirementAge+1)*0.2
+	}
+	return false
+}
+
+func generateYouthPlayer(retiring *Player) *Player {
+	playerCounter++
+	template := playerNames[rand.Intn(len(playerNames))]
+
+	// Academy graduates start below first-team level
+	characteristics := generatePlayerCharacteristics(retiring.Position)
+	for _, attribute := range []*int{&characteristics.Speed, &characteristics.Shooting, &characteristics.Passing,
+		&characteristics.Defending, &characteristics.Physicality, &characteristics.Mentality} {
+		*attribute = max(1, *attribute-5-rand.Intn(11))
+	}
+	characteristics.Overall = (characteristics.Speed + characteristics.Shooting + characteristics.Passing +
+		characteristics.Defending + characteristics.Physicality + characteristics.Mentality) / 6
+
+	age := 17 + rand.Intn(3)
+	return &Player{
+		ID:              playerCounter,
+		Name:            fmt.Sprintf("%s %d", template.Name, retiring.Number),
+		Position:        retiring.Position,
+		Number:          retiring.Number,
+		Age:             age,
+		Nationality:     template.Nationality,
+		AvatarURL:       fmt.Sprintf("https://i.pravatar.cc/150?img=%d", (playerCounter%70)+1),
+		TeamID:          retiring.TeamID,
+		MarketValue:     calculateMarketValue(characteristics, age),
+		LastUpdate:      time.Now(),
+		Characteristics: characteristics,
+		SeasonStats:     PlayerSeasonStats{},
+		CurrentRating:   6.0,
+	}
+}
+
+func retirePlayer(player, replacement *Player) {
+	player.RetiredSeason = currentSeason
+	player.TeamID = 0
+	retiredPlayers[player.ID] = player
+	delete(players, player.ID)
+	players[replacement.ID] = replacement
+
+	// Fantasy squads inherit the academy replacement when it keeps them within the budget
+	// and club limits, otherwise the cheapest player in the same position group that does
+	candidates := []*Player{replacement}
+	for _, other := range players {
+		if other.ID != replacement.ID && fantasyPositionGroup(other.Position) == fantasyPositionGroup(player.Position) {
+			candidates = append(candidates, other)
+		}
+	}
+	sort.Slice(candidates[1:], func(i, j int) bool {
+		a, b := candidates[i+1], candidates[j+1]
+		if a.MarketValue != b.MarketValue {
+			return a.MarketValue < b.MarketValue
+		}
+		return a.ID < b.ID
+	})
+	for _, team := range fantasyTeams {
+		for i, playerID := range team.PlayerIDs {
+			if playerID == player.ID {
+				replaceFantasyPlayer(team, i, candidates)
+			}
+		}
+	}
+
+	logWithFields(LevelInfo, LogFields{Component: "season"}, "👋 %s (%d) retires from %s, replaced by academy graduate %s (%d)",
+		player.Name, player.Age, getTeamName(replacement.TeamID), replacement.Name, replacement.Age)
+}
+
+// replaceFantasyPlayer fills a retired player's squad slot with the first candidate that
+// leaves a valid squad. If the squad is already invalid (e.g. after price rises) the first
+// candidate is used
+func replaceFantasyPlayer(team *FantasyTeam, slot int, candidates []*Player) {
+	retiredID := team.PlayerIDs[slot]
+	selected := make(map[int]bool)
+	for _, playerID := range team.PlayerIDs {
+		selected[playerID] = true
+	}
+
+	squad := append([]int{}, team.PlayerIDs...)
+	chosen := candidates[0]
+	for _, candidate := range candidates {
+		if selected[candidate.ID] {
+			continue
+		}
+		squad[slot] = candidate.ID
+		if _, err := validateFantasySquad(squad); err == nil {
+			chosen = candidate
+			break
+		}
+	}
+
+	team.PlayerIDs[slot] = chosen.ID
+	if team.CaptainID == retiredID {
+		team.CaptainID = chosen.ID
+	}
+	team.SquadValue = 0
+	for _, playerID := range team.PlayerIDs {
+		if p := players[playerID]; p != nil {
+			team.SquadValue += p.MarketValue
+		}
+	}
+	team.Bank = FantasyBudget - team.SquadValue
+	team.LastUpdate = time.Now()
+}
+
+// Transfer window between seasons: clubs strengthen their weakest position group
+func runTransferWindow() {
+	season := currentSeason + 1
+	budgets := make(map[int]int)
+	signings := make(map[int]int)
+	attempted := make(map[int]map[int]bool) // buyerID -> playerIDs already bid for
+	squadSizes := make(map[int]int)
+	for _, player := range players {
+		squadSizes[player.TeamID]++
+	}
+
+	// Process clubs in a fixed order so a given seed replays the same window
+	var clubIDs []int
+	for id, team := range teams {
+		clubIDs = append(clubIDs, id)
+		budgets[id] = transferBudget(team)
+		attempted[id] = make(map[int]bool)
+	}
+	sort.Ints(clubIDs)
+
+	completed, rejected := 0, 0
+	for round := 0; round < MaxSigningsPerClub; round++ {
+		for _, buyerID := range clubIDs {
+			if squadSizes[buyerID] >= MaxSquadSize || signings[buyerID] > round {
+				continue
+			}
+
+			group, target := findTransferTarget(buyerID, budgets[buyerID], squadSizes, attempted[buyerID])
+			if target == nil {
+				continue
+			}
+			attempted[buyerID][target.ID] = true
+
+			transfer := negotiateTransfer(season, target, buyerID, budgets[buyerID], squadSizes[target.TeamID])
+			transfer.Reason = fmt.Sprintf("Strengthening %s", group)
+			recordTransfer(transfer)
+
+			if transfer.Status != TransferCompleted {
+				rejected++
+				continue
+			}
+
+			budgets[buyerID] -= transfer.Fee
+			budgets[target.TeamID] += transfer.Fee
+			squadSizes[buyerID]++
+			squadSizes[target.TeamID]--
+			signings[buyerID]++
+			completeTransfer(target, buyerID, transfer)
+			completed++
+		}
+	}
+
+	refreshSquadSummaries()
+	logWithFields(LevelInfo, LogFields{Component: "transfers"}, "💼 Transfer window for season %d closed: %d transfers completed, %d bids rejected", season, completed, rejected)
+}
+
+// Transfer budget in millions, scaled by league and squad value
+func transferBudget(team *TeamInfo) int {
+	budget := TransferBudgetCommunity
+	if team.League == LeaguePremier {
+		budget = TransferBudgetPremier
+	}
+	budget += team.SquadValue / 20
+
+	// Clubs cannot spend money they do not have
+	if finances, exists := clubFinances[team.ID]; exists {
+		budget = min(budget, max(0, int(finances.Balance)))
+	}
+	return budget
+}
+
+// Weakest position group relative to the league average and the best affordable upgrade for it
+func findTransferTarget(buyerID, budget int, squadSizes map[int]int, excluded map[int]bool) (string, *Player) {
+	buyer := teams[buyerID]
+	teamTotals, teamCounts := make(map[string]int), make(map[string]int)
+	leagueTotals, leagueCounts := make(map[string]int), make(map[string]int)
+	for _, player := range players {
+		group := fantasyPositionGroup(player.Position)
+		if player.TeamID == buyerID {
+			teamTotals[group] += player.Characteristics.Overall
+			teamCounts[group]++
+		}
+		if team, exists := teams[player.TeamID]; exists && team.League == buyer.League {
+			leagueTotals[group] += player.Characteristics.Overall
+			leagueCounts[group]++
+		}
+	}
+
+	weakestGroup, weakestAverage, largestGap := "", 0, 0
+	for _, group := range []string{"GK", "DEF", "MID", "FWD"} {
+		if teamCounts[group] == 0 || leagueCounts[group] == 0 {
+			continue
+		}
+		teamAverage := teamTotals[group] / teamCounts[group]
+		gap := leagueTotals[group]/leagueCounts[group] - teamAverage
+		if gap > largestGap {
+			weakestGroup, weakestAverage, largestGap = group, teamAverage, gap
+		}
+	}
+	if weakestGroup == "" {
+		return "", nil
+	}
+
+	var candidates []*Player
+	for _, player := range players {
+		if player.TeamID == buyerID || excluded[player.ID] || fantasyPositionGroup(player.Position) != weakestGroup {
+			continue
+		}
+		if squadSizes[player.TeamID] <= MinSquadSize || player.MarketValue > budget {
+			continue
+		}
+		if player.Characteristics.Overall < weakestAverage+MinTransferUpgrade {
+			continue
+		}
+		candidates = append(candidates, player)
+	}
+	if len(candidates) == 0 {
+		return weakestGroup, nil
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].Characteristics.Overall != candidates[j].Characteristics.Overall {
+			return candidates[i].Characteristics.Overall > candidates[j].Characteristics.Overall
+		}
+		return candidates[i].ID < candidates[j].ID
+	})
+	return weakestGroup, candidates[0]
+}
+
+// Bid and counter-offer rounds, seeded by season, player and buyer so outcomes replay for a given TRANSFER_SEED
+func negotiateTransfer(season int, player *Player, buyerID, budget, sellerSquadSize int) *Transfer {
+	rng := rand.New(rand.NewSource(transferSeed + int64(season)*1000003 + int64(player.ID)*7919 + int64(buyerID)))
+
+	value := float64(player.MarketValue)
+	asking := value * (1.1 + rng.Float64()*0.3)
+	if sellerSquadSize <= MinSquadSize+1 {
+		asking *= 1.2 // Thin squads hold out for more
+	}
+	bid := value * (0.85 + rng.Float64()*0.2)
+
+	transfer := &Transfer{
+		Season:      season,
+		PlayerID:    player.ID,
+		PlayerName:  player.Name,
+		Position:    player.Position,
+		FromTeamID:  player.TeamID,
+		FromTeam:    teams[player.TeamID].Name,
+		ToTeamID:    buyerID,
+		ToTeam:      teams[buyerID].Name,
+		MarketValue: player.MarketValue,
+		Status:      TransferRejected,
+		Timestamp:   time.Now(),
+	}
+
+	for round := 1; round <= MaxNegotiationRounds; round++ {
+		transfer.Rounds = round
+		if bid >= asking {
+			transfer.Status = TransferCompleted
+			transfer.Fee = int(math.Round(bid))
+			return transfer
+		}
+
+		// Buyer raises within budget, seller lowers the asking price
+		bid = math.Min(float64(budget), bid*(1.05+rng.Float64()*0.1))
+		asking *= 0.95 + rng.Float64()*0.03
+	}
+
+	transfer.Fee = int(math.Round(bid)) // Final rejected offer
+	return transfer
+}
+
+func completeTransfer(player *Player, buyerID int, transfer *Transfer) {
+	// Keep the shirt number unless it is taken at the new club
+	taken := make(map[int]bool)
+	for _, teammate := range getPlayersFromTeam(buyerID) {
+		taken[teammate.Number] = true
+	}
+	if taken[player.Number] {
+		for number := 1; number < 100; number++ {
+			if !taken[number] {
+				player.Number = number
+				break
+			}
+		}
+	}
+
+	player.TeamID = buyerID
+	player.LastUpdate = time.Now()
+
+	recordFinanceTransaction(buyerID, transfer.Season, 0, FinanceTransferIn,
+		fmt.Sprintf("Signed %s from %s", player.Name, transfer.FromTeam), -float64(transfer.Fee), 0)
+	recordFinanceTransaction(transfer.FromTeamID, transfer.Season, 0, FinanceTransferOut,
+		fmt.Sprintf("Sold %s to %s", player.Name, transfer.ToTeam), float64(transfer.Fee), 0)
+
+	if transfer.Fee >= MajorTransferFee {
+		addNewsEntry(NewsTransfer,
+			fmt.Sprintf("%s completes £%dm move to %s", player.Name, transfer.Fee, transfer.ToTeam),
+			fmt.Sprintf("%s have signed %s (%s, %d) from %s for £%dm after %d round(s) of negotiation. The %d-rated %s international arrives to strengthen the squad ahead of season %d.",
+				transfer.ToTeam, player.Name, player.Position, player.Age, transfer.FromTeam, transfer.Fee,
+				transfer.Rounds, player.Characteristics.Overall, player.Nationality, transfer.Season),
+			0, transfer.FromTeamID, transfer.ToTeamID)
+	}
+
+	logWithFields(LevelInfo, LogFields{Component: "transfers"}, "✍️  %s joins %s from %s for £%dm", player.Name, transfer.ToTeam, transfer.FromTeam, transfer.Fee)
+}
+
+func recordTransfer(transfer *Transfer) {
+	transferCounter++
+	transfer.ID = transferCounter
+	transfers = append(transfers, transfer)
+	if len(transfers) > MaxTransferHistory {
+		transfers = transfers[1:]
+	}
+}
+
+// Squad size and value shown on team and table endpoints
+func refreshSquadSummaries() {
+	for _, team := range teams {
+		team.SquadSize, team.SquadValue = 0, 0
+	}
+	for _, player := range players {
+		if team, exists := teams[player.TeamID]; exists {
+			team.SquadSize++
+			team.SquadValue += player.MarketValue
+		}
+	}
+	for _, table := range leagueTables {
+		for _, entry := range table {
+			if team, exists := teams[entry.Team.ID]; exists {
+				entry.Team.SquadSize = team.SquadSize
+				entry.Team.SquadValue = team.SquadValue
+			}
+		}
+	}
+}
+
+func addNewsEntry(newsType, title, content string, matchID int, teamIDs ...int) *NewsEntry {
+	newsCounter++
+	entry := &NewsEntry{
+		ID:        newsCounter,
+		Title:     title,
+		Content:   content,
+		MatchID:   matchID,
+		TeamIDs:   teamIDs,
+		Type:      newsType,
+		Timestamp: time.Now(),
+		Generated: true,
+	}
+
+	newsEntries = append(newsEntries, entry)
+	if len(newsEntries) > MaxNewsEntries {
+		newsEntries = newsEntries[1:]
+	}
+	return entry
+}
+
+// removeNewsEntry retracts an article, e.g. for a decision VAR overturned. ID 0 is a no-op
+func removeNewsEntry(id int) {
+	for i, entry := range newsEntries {
+		if entry.ID == id {
+			newsEntries = append(newsEntries[:i], newsEntries[i+1:]...)
+			return
+		}
+	}
+}
+
+func getTransfers(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	status := strings.ToLower(query.Get("status"))
+
+	season := 0
+	if value := query.Get("season"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			http.Error(w, "Invalid season", http.StatusBadRequest)
+			return
+		}
+		season = parsed
+	}
+
+	teamID := 0
+	if value := query.Get("team_id"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			http.Error(w, "Invalid team ID", http.StatusBadRequest)
+			return
+		}
+		teamID = parsed
+	}
+
+	limit := 50
+	if value := query.Get("limit"); value != "" {
+		if parsed, err := strconv.Atoi(value); err == nil && parsed > 0 {
+			limit = min(parsed, MaxTransferHistory)
+		}
+	}
+
+	mutex.RLock()
+	var transferList []*Transfer
+	totalFees := 0
+	// Newest first
+	for i := len(transfers) - 1; i >= 0; i-- {
+		transfer := transfers[i]
+		if season != 0 && transfer.Season != season {
+			continue
+		}
+		if teamID != 0 && transfer.FromTeamID != teamID && transfer.ToTeamID != teamID {
+			continue
+		}
+		if status != "" && transfer.Status != status {
+			continue
+		}
+		if transfer.Status == TransferCompleted {
+			totalFees += transfer.Fee
+		}
+		if len(transferList) < limit {
+			transferList = append(transferList, transfer)
+		}
+	}
+	mutex.RUnlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"transfers":  transferList,
+		"count":      len(transferList),
+		"total_fees": totalFees,
+		"seed":       transferSeed,
+		"timestamp":  time.Now(),
+	})
+}
+
+// Elo ratings: every team starts from its league's base rating and keeps its rating across seasons
+func initializeEloRatings() {
+	for _, team := range teams {
+		if _, exists := eloRatings[team.ID]; exists {
+			continue
+		}
+		start := EloStartCommunity
+		if team.League == LeaguePremier {
+			start = EloStartPremier
+		}
+		eloRatings[team.ID] = &EloRating{TeamID: team.ID, Rating: start, Initial: start, Peak: start, Lowest: start}
+	}
+}
+
+// eloRating returns a team's current rating, or its league's starting rating if it has none
+func eloRating(team *TeamInfo) float64 {
+	if rating := eloRatings[team.ID]; rating != nil {
+		return rating.Rating
+	}
+	if team.League == LeaguePremier {
+		return EloStartPremier
+	}
+	return EloStartCommunity
+}
+
+// eloExpected is the home side's expected score (win 1, draw 0.5) including home advantage
+func eloExpected(home, away float64) float64 {
+	return eloWinExpectancy(home + EloHomeAdvantage - away)
+}
+
+// eloWinExpectancy is the expected score of a side rated diff points above its opponent on neutral ground
+func eloWinExpectancy(diff float64) float64 {
+	return 1 / (1 + math.Pow(10, -diff/400))
+}
+
+// eloMarginMultiplier scales rating changes by goal margin: 1 goal x1, 2 goals x1.5, 3 goals x1.75, then +1/8 per goal
+func eloMarginMultiplier(margin int) float64 {
+	switch {
+	case margin <= 1:
+		return 1
+	case margin == 2:
+		return 1.5
+	default:
+		return 1.75 + float64(margin-3)/8
+	}
+}
+
+// updateEloRatings moves both sides' ratings by the same amount in opposite directions. Caller must hold mutex
+func updateEloRatings(match *Match) {
+	home, away := eloRatings[match.HomeTeam.ID], eloRatings[match.AwayTeam.ID]
+	if home == nil || away == nil {
+		return
+	}
+
+	expected := eloExpected(home.Rating, away.Rating)
+	actual := 0.5
+	if match.HomeScore > match.AwayScore {
+		actual = 1
+	} else if match.HomeScore < match.AwayScore {
+		actual = 0
+	}
+	margin := match.HomeScore - match.AwayScore
+	if margin < 0 {
+		margin = -margin
+	}
+	change := math.Round(EloKFactor*eloMarginMultiplier(margin)*(actual-expected)*10) / 10
+
+	applyEloChange(home, match, &match.AwayTeam, true, expected, change)
+	applyEloChange(away, match, &match.HomeTeam, false, 1-expected, -change)
+}
+
+func applyEloChange(rating *EloRating, match *Match, opponent *TeamInfo, isHome bool, expected, change float64) {
+	before := rating.Rating
+	rating.Rating = math.Round((rating.Rating+change)*10) / 10
+	rating.Peak = math.Max(rating.Peak, rating.Rating)
+	rating.Lowest = math.Min(rating.Lowest, rating.Rating)
+	rating.Played++
+	rating.LastChange = change
+
+	goalsFor, goalsAgainst := match.HomeScore, match.AwayScore
+	if !isHome {
+		goalsFor, goalsAgainst = goalsAgainst, goalsFor
+	}
+	result := "D"
+	if goalsFor > goalsAgainst {
+		result = "W"
+	} else if goalsFor < goalsAgainst {
+		result = "L"
+	}
+
+	history := append(ratingHistory[rating.TeamID], &RatingChange{
+		MatchID:      match.ID,
+		Season:       match.Season,
+		Matchweek:    match.MatchweekNum,
+		OpponentID:   opponent.ID,
+		OpponentName: opponent.Name,
+		Home:         isHome,
+		Result:       result,
+		Score:        fmt.Sprintf("%d-%d", goalsFor, goalsAgainst),
+		Expected:     math.Round(expected*1000) / 1000,
+		Before:       before,
+		After:        rating.Rating,
+		Change:       change,
+		Timestamp:    time.Now(),
+	})
+	if len(history) > MaxRatingHistory {
+		history = history[len(history)-MaxRatingHistory:]
+	}
+	ratingHistory[rating.TeamID] = history
+}
+
+// getRankings serves a power ranking of every team by Elo rating, across leagues
+func getRankings(w http.ResponseWriter, r *http.Request) {
+	league := r.URL.Query().Get("league")
+
+	mutex.RLock()
+	defer mutex.RUnlock()
+
+	if league != "" {
+		if _, exists := leagueConfigs[league]; !exists {
+			http.Error(w, "League not found", http.StatusNotFound)
+			return
+		}
+	}
+
+	rankings := make([]map[string]interface{}, 0, len(eloRatings))
+	for teamID, rating := range eloRatings {
+		team := teams[teamID]
+		if team == nil || (league != "" && team.League != league) {
+			continue
+		}
+		position, _ := tableStanding(team)
+
+		// Rating change over the last five matches
+		history := ratingHistory[teamID]
+		trend := 0.0
+		for i := max(0, len(history)-5); i < len(history); i++ {
+			trend += history[i].Change
+		}
+
+		rankings = append(rankings, map[string]interface{}{
+			"team_id":         team.ID,
+			"team_name":       team.Name,
+			"short_name":      team.ShortName,
+			"league":          team.League,
+			"league_position": position,
+			"rating":          rating.Rating,
+			"peak":            rating.Peak,
+			"lowest":          rating.Lowest,
+			"played":          rating.Played,
+			"last_change":     rating.LastChange,
+			"trend":           math.Round(trend*10) / 10,
+		})
+	}
+
+	sort.Slice(rankings, func(i, j int) bool {
+		if rankings[i]["rating"].(float64) != rankings[j]["rating"].(float64) {
+			return rankings[i]["rating"].(float64) > rankings[j]["rating"].(float64)
+		}
+		return rankings[i]["team_id"].(int) < rankings[j]["team_id"].(int)
+	})
+	for i, entry := range rankings {
+		entry["rank"] = i + 1
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"rankings":  rankings,
+		"count":     len(rankings),
+		"timestamp": time.Now(),
+	})
+}
+
+// getTeamRatingHistory serves a team's Elo rating after each match, oldest first
+func getTeamRatingHistory(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		http.Error(w, "Invalid team ID", http.StatusBadRequest)
+		return
+	}
+
+	query := r.URL.Query()
+	season := 0
+	if value := query.Get("season"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			http.Error(w, "Invalid season", http.StatusBadRequest)
+			return
+		}
+		season = parsed
+	}
+
+	limit := MaxRatingHistory
+	if value := query.Get("limit"); value != "" {
+		if parsed, err := strconv.Atoi(value); err == nil && parsed > 0 {
+			limit = min(parsed, MaxRatingHistory)
+		}
+	}
+
+	mutex.RLock()
+	defer mutex.RUnlock()
+
+	team, exists := teams[id]
+	rating, hasRating := eloRatings[id]
+	if !exists || !hasRating {
+		http.Error(w, "Team not found", http.StatusNotFound)
+		return
+	}
+
+	// Most recent changes, returned oldest first for charting
+	history := make([]RatingChange, 0)
+	for _, change := range ratingHistory[id] {
+		if season == 0 || change.Season == season {
+			history = append(history, *change)
+		}
+	}
+	if len(history) > limit {
+		history = history[len(history)-limit:]
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"team_id":   team.ID,
+		"team_name": team.Name,
+		"rating":    *rating,
+		"history":   history,
+		"count":     len(history),
+		"timestamp": time.Now(),
+	})
+}
+
+// Club finances: matchday, broadcast and prize income against wages and transfers
+func initializeClubFinances() {
+	for _, team := range teams {
+		balance := StartingBalanceCommunity
+		if team.League == LeaguePremier {
+			balance = StartingBalancePremier
+		}
+		clubFinances[team.ID] = &ClubFinances{
+			TeamID:  team.ID,
+			Balance: roundMillions(balance + float64(team.SquadValue)/10),
+			Ledger:  make([]*FinanceTransaction, 0, MaxFinanceLedger),
+		}
+	}
+}
+
+func recordFinanceTransaction(teamID, season, matchweek int, txType, description string, amount float64, matchID int) {
+	finances, exists := clubFinances[teamID]
+	if !exists {
+		return
+	}
+
+	// Look up the summary first so a new season opens at the pre-transaction balance
+	summary := getSeasonFinance(finances, season)
+	amount = roundMillions(amount)
+	finances.Balance = roundMillions(finances.Balance + amount)
+	financeCounter++
+	finances.Ledger = append(finances.Ledger, &FinanceTransaction{
+		ID:          financeCounter,
+		Season:      season,
+		Matchweek:   matchweek,
+		Type:        txType,
+		Description: description,
+		Amount:      amount,
+		Balance:     finances.Balance,
+		MatchID:     matchID,
+		Timestamp:   time.Now(),
+	})
+	if len(finances.Ledger) > MaxFinanceLedger {
+		finances.Ledger = finances.Ledger[1:]
+	}
+
+	switch txType {
+	case FinanceMatchday:
+		summary.MatchdayRevenue = roundMillions(summary.MatchdayRevenue + amount)
+	case FinanceBroadcast:
+		summary.BroadcastRevenue = roundMillions(summary.BroadcastRevenue + amount)
+	case FinancePrizeMoney:
+		summary.PrizeMoney = roundMillions(summary.PrizeMoney + amount)
+	case FinanceWages:
+		summary.Wages = roundMillions(summary.Wages - amount)
+	case FinanceTransferIn:
+		summary.TransferSpend = roundMillions(summary.TransferSpend - amount)
+	case FinanceTransferOut:
+		summary.TransferIncome = roundMillions(summary.TransferIncome + amount)
+	}
+	if amount >= 0 {
+		summary.Revenue = roundMillions(summary.Revenue + amount)
+	} else {
+		summary.Expenses = roundMillions(summary.Expenses - amount)
+	}
+	summary.Profit = roundMillions(summary.Revenue - summary.Expenses)
+	summary.ClosingBalance = finances.Balance
+
+	// Per-matchweek series for charting
+	if len(summary.Matchweeks) == 0 || summary.Matchweeks[len(summary.Matchweeks)-1].Matchweek != matchweek {
+		summary.Matchweeks = append(summary.Matchweeks, MatchweekFinance{Matchweek: matchweek})
+	}
+	period := &summary.Matchweeks[len(summary.Matchweeks)-1]
+	if amount >= 0 {
+		period.Revenue = roundMillions(period.Revenue + amount)
+	} else {
+		period.Expenses = roundMillions(period.Expenses - amount)
+	}
+	period.Balance = finances.Balance
+}
+
+func getSeasonFinance(finances *ClubFinances, season int) *SeasonFinance {
+	for _, summary := range finances.Seasons {
+		if summary.Season == season {
+			return summary
+		}
+	}
+
+	summary := &SeasonFinance{Season: season, OpeningBalance: finances.Balance}
+	finances.Seasons = append(finances.Seasons, summary)
+	if len(finances.Seasons) > MaxSeasonHistory+1 {
+		finances.Seasons = finances.Seasons[1:]
+	}
+	return summary
+}
+
+// Gate receipts for the home side, broadcast income and wages for both clubs
+func settleMatchFinances(matchID int, match *Match) {
+	ticketPrice := TicketPriceCommunity
+	broadcast := BroadcastFeeCommunity
+	if match.Competition == LeaguePremier {
+		ticketPrice = TicketPricePremier
+		broadcast = BroadcastFeePremier
+	}
+
+	// Demand peaks on the opening weekends and the run-in and dips mid-season
+	seasonality := 1 + 0.15*math.Cos(2*math.Pi*float64(match.MatchweekNum-1)/float64(MatchesPerTeam))
+	gate := float64(match.Attendance) * ticketPrice * seasonality / 1e6
+	recordFinanceTransaction(match.HomeTeam.ID, match.Season, match.MatchweekNum, FinanceMatchday,
+		fmt.Sprintf("Gate receipts vs %s (%d fans)", match.AwayTeam.Name, match.Attendance), gate, matchID)
+
+	for _, team := range []TeamInfo{match.HomeTeam, match.AwayTeam} {
+		recordFinanceTransaction(team.ID, match.Season, match.MatchweekNum, FinanceBroadcast,
+			fmt.Sprintf("Broadcast fee, matchweek %d", match.MatchweekNum), broadcast, matchID)
+		recordFinanceTransaction(team.ID, match.Season, match.MatchweekNum, FinanceWages,
+			fmt.Sprintf("Wages, matchweek %d", match.MatchweekNum), -calculateWageBill(team.ID), matchID)
+	}
+}
+
+// Wage bill per matchweek in millions, derived from squad market values
+func calculateWageBill(teamID int) float64 {
+	ratio := WageRatioCommunity
+	if team, exists := teams[teamID]; exists && team.League == LeaguePremier {
+		ratio = WageRatioPremier
+	}
+
+	total := 0
+	for _, player := range getPlayersFromTeam(teamID) {
+		total += player.MarketValue
+	}
+	return float64(total) * ratio / float64(MatchesPerTeam)
+}
+
+// Prize money by final league position, paid before the tables are reset
+func awardPrizeMoney() {
+	for league, table := range leagueTables {
+		pool := PrizePoolCommunity
+		if league == LeaguePremier {
+			pool = PrizePoolPremier
+		}
+
+		// Linear split: the champion earns the most, the bottom club the least
+		weights := 0
+		for i := range table {
+			weights += len(table) - i
+		}
+		for i, entry := range table {
+			prize := pool * float64(len(table)-i) / float64(weights)
+			recordFinanceTransaction(entry.Team.ID, currentSeason, MatchesPerTeam, FinancePrizeMoney,
+				fmt.Sprintf("Prize money for finishing %s in the %s", ordinal(entry.Position), league), prize, 0)
+		}
+	}
+}
+
+func roundMillions(value float64) float64 {
+	return math.Round(value*100) / 100
+}
+
+// getTeamRatings serves attack, midfield and defence ratings for a team's XI: the lineup of
+// match_id, else of its current match, else its strongest squad XI
+func getTeamRatings(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		http.Error(w, "Invalid team ID", http.StatusBadRequest)
+		return
+	}
+
+	matchID := 0
+	if value := r.URL.Query().Get("match_id"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			http.Error(w, "Invalid match ID", http.StatusBadRequest)
+			return
+		}
+		matchID = parsed
+	}
+
+	mutex.RLock()
+	defer mutex.RUnlock()
+
+	team, exists := teams[id]
+	if !exists {
+		http.Error(w, "Team not found", http.StatusNotFound)
+		return
+	}
+
+	if matchID != 0 {
+		if _, played := matchLineups[matchID][team.ID]; !played {
+			http.Error(w, "Team did not play in this match", http.StatusNotFound)
+			return
+		}
+	} else {
+		for _, match := range matches {
+			if (match.Status == StatusLive || match.Status == StatusHalftime || match.Status == StatusVARCheck) &&
+				(match.HomeTeam.ID == team.ID || match.AwayTeam.ID == team.ID) {
+				matchID = match.ID
+				break
+			}
+		}
+	}
+
+	response := map[string]interface{}{
+		"ratings":   calculateTeamRatings(matchID, team),
+		"timestamp": time.Now(),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+func getTeamFinances(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		http.Error(w, "Invalid team ID", http.StatusBadRequest)
+		return
+	}
+
+	query := r.URL.Query()
+	txType := query.Get("type")
+	season := 0
+	if value := query.Get("season"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			http.Error(w, "Invalid season", http.StatusBadRequest)
+			return
+		}
+		season = parsed
+	}
+
+	limit := 100
+	if value := query.Get("limit"); value != "" {
+		if parsed, err := strconv.Atoi(value); err == nil && parsed > 0 {
+			limit = min(parsed, MaxFinanceLedger)
+		}
+	}
+
+	mutex.RLock()
+	defer mutex.RUnlock()
+
+	team, exists := teams[id]
+	finances, hasFinances := clubFinances[id]
+	if !exists || !hasFinances {
+		http.Error(w, "Team not found", http.StatusNotFound)
+		return
+	}
+
+	// Newest first
+	var ledger []*FinanceTransaction
+	for i := len(finances.Ledger) - 1; i >= 0 && len(ledger) < limit; i-- {
+		transaction := finances.Ledger[i]
+		if season != 0 && transaction.Season != season {
+			continue
+		}
+		if txType != "" && transaction.Type != txType {
+			continue
+		}
+		ledger = append(ledger, transaction)
+	}
+
+	var seasons []*SeasonFinance
+	for _, summary := range finances.Seasons {
+		if season == 0 || summary.Season == season {
+			seasons = append(seasons, summary)
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"team_id":       team.ID,
+		"team_name":     team.Name,
+		"balance":       finances.Balance,
+		"wage_bill":     roundMillions(calculateWageBill(team.ID)),
+		"squad_value":   team.SquadValue,
+		"seasons":       seasons,
+		"ledger":        ledger,
+		"count":         len(ledger),
+		"currency_unit": "GBP millions",
+		"timestamp":     time.Now(),
+	})
+}
+
+// seasonFinishedMatches returns the current season's finished matches, oldest first,
+// including those still in their post-match break. Caller must hold mutex
+func seasonFinishedMatches() []*Match {
+	var finished []*Match
+	for _, pool := range []map[int]*Match{matches, finishedMatches} {
+		for _, match := range pool {
+			if match.Status == StatusFinished && match.Season == currentSeason {
+				finished = append(finished, match)
+			}
+		}
+	}
+	sort.Slice(finished, func(i, j int) bool { return finished[i].ID < finished[j].ID })
+	return finished
+}
+
+// goalInterval maps a minute to its 15-minute window (0-5)
+func goalInterval(minute int) int {
+	if minute <= 45 {
+		return max(0, min(2, (minute-1)/15))
+	}
+	return min(5, 3+(minute-46)/15)
+}
+
+// calculateTeamSeasonStats aggregates a team's finished matches. Caller must hold mutex
+func calculateTeamSeasonStats(team *TeamInfo, finished []*Match) *TeamSeasonStats {
+	stats := &TeamSeasonStats{
+		TeamID:             team.ID,
+		TeamName:           team.Name,
+		League:             team.League,
+		PointsPerGameTrend: []PointsPerGame{},
+	}
+	intervals := []string{"1-15", "16-30", "31-45", "46-60", "61-75", "76-90"}
+	stats.GoalsByInterval = make([]GoalInterval, len(intervals))
+	for i, label := range intervals {
+		stats.GoalsByInterval[i].Interval = label
+	}
+
+	possession := 0
+	for _, match := range finished {
+		home := match.HomeTeam.ID == team.ID
+		if !home && match.AwayTeam.ID != team.ID {
+			continue
+		}
+
+		goalsFor, goalsAgainst, opponent := match.HomeScore, match.AwayScore, match.AwayTeam.Name
+		if home {
+			stats.HomePlayed++
+			stats.HomeGoalsFor += goalsFor
+			stats.HomeGoalsAgainst += goalsAgainst
+		} else {
+			goalsFor, goalsAgainst, opponent = match.AwayScore, match.HomeScore, match.HomeTeam.Name
+			stats.AwayPlayed++
+			stats.AwayGoalsFor += goalsFor
+			stats.AwayGoalsAgainst += goalsAgainst
+		}
+		stats.Played++
+		stats.GoalsFor += goalsFor
+		stats.GoalsAgainst += goalsAgainst
+		if goalsAgainst == 0 {
+			stats.CleanSheets++
+		}
+		if goalsFor == 0 {
+			stats.FailedToScore++
+		}
+
+		result, points := "D", 1
+		if goalsFor > goalsAgainst {
+			result, points = "W", 3
+		} else if goalsFor < goalsAgainst {
+			result, points = "L", 0
+		}
+		stats.Points += points
+		stats.PointsPerGameTrend = append(stats.PointsPerGameTrend, PointsPerGame{
+			MatchID:       match.ID,
+			Matchweek:     match.MatchweekNum,
+			Opponent:      opponent,
+			Home:          home,
+			Score:         fmt.Sprintf("%d-%d", goalsFor, goalsAgainst),
+			Result:        result,
+			Points:        points,
+			PointsPerGame: math.Round(float64(stats.Points)/float64(stats.Played)*100) / 100,
+		})
+
+		if matchStat := matchStats[match.ID]; matchStat != nil {
+			if home {
+				possession += matchStat.HomePossession
+				stats.Shots += matchStat.HomeShots
+				stats.ShotsOnTarget += matchStat.HomeShotsOnTarget
+				stats.ShotsConceded += matchStat.AwayShots
+				stats.Corners += matchStat.HomeCorners
+				stats.Fouls += matchStat.HomeFouls
+				stats.YellowCards += matchStat.HomeYellowCards
+				stats.RedCards += matchStat.HomeRedCards
+			} else {
+				possession += matchStat.AwayPossession
+				stats.Shots += matchStat.AwayShots
+				stats.ShotsOnTarget += matchStat.AwayShotsOnTarget
+				stats.ShotsConceded += matchStat.HomeShots
+				stats.Corners += matchStat.AwayCorners
+				stats.Fouls += matchStat.AwayFouls
+				stats.YellowCards += matchStat.AwayYellowCards
+				stats.RedCards += matchStat.AwayRedCards
+			}
+		}
+
+		for _, event := range matchTimelines[match.ID] {
+			if event.Type != EventGoal || event.Overturned {
+				continue
+			}
+			if event.TeamID == team.ID {
+				stats.GoalsByInterval[goalInterval(event.Minute)].Scored++
+			} else {
+				stats.GoalsByInterval[goalInterval(event.Minute)].Conceded++
+			}
+		}
+	}
+
+	if stats.Played > 0 {
+		played := float64(stats.Played)
+		stats.PointsPerGame = math.Round(float64(stats.Points)/played*100) / 100
+		stats.AveragePossession = math.Round(float64(possession)/played*10) / 10
+		stats.ShotsPerGame = math.Round(float64(stats.Shots)/played*10) / 10
+		stats.CornersPerGame = math.Round(float64(stats.Corners)/played*10) / 10
+		stats.CardsPerGame = math.Round(float64(stats.YellowCards+stats.RedCards)/played*10) / 10
+	}
+	if stats.Shots > 0 {
+		stats.ShotAccuracy = math.Round(float64(stats.ShotsOnTarget)/float64(stats.Shots)*1000) / 10
+	}
+	return stats
+}
+
+// getTeamStats serves a team's aggregated statistics for the current season
+func getTeamStats(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		http.Error(w, "Invalid team ID", http.StatusBadRequest)
+		return
+	}
+
+	mutex.RLock()
+	defer mutex.RUnlock()
+
+	team, exists := teams[id]
+	if !exists {
+		http.Error(w, "Team not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"season":    currentSeason,
+		"stats":     calculateTeamSeasonStats(team, seasonFinishedMatches()),
+		"timestamp": time.Now(),
+	})
+}
+
+// leagueStatMetrics are the metrics ranked by /leagues/{league}/stats; lower ranks
+// first for metrics where fewer is better
+var leagueStatMetrics = []struct {
+	Name          string
+	LowerIsBetter bool
+	Value         func(stats *TeamSeasonStats) float64
+}{
+	{"points_per_game", false, func(s *TeamSeasonStats) float64 { return s.PointsPerGame }},
+	{"goals_for", false, func(s *TeamSeasonStats) float64 { return float64(s.GoalsFor) }},
+	{"goals_against", true, func(s *TeamSeasonStats) float64 { return float64(s.GoalsAgainst) }},
+	{"home_goals_for", false, func(s *TeamSeasonStats) float64 { return float64(s.HomeGoalsFor) }},
+	{"away_goals_for", false, func(s *TeamSeasonStats) float64 { return float64(s.AwayGoalsFor) }},
+	{"clean_sheets", false, func(s *TeamSeasonStats) float64 { return float64(s.CleanSheets) }},
+	{"average_possession", false, func(s *TeamSeasonStats) float64 { return s.AveragePossession }},
+	{"shots_per_game", false, func(s *TeamSeasonStats) float64 { return s.ShotsPerGame }},
+	{"shot_accuracy", false, func(s *TeamSeasonStats) float64 { return s.ShotAccuracy }},
+	{"shots_conceded", true, func(s *TeamSeasonStats) float64 { return float64(s.ShotsConceded) }},
+	{"corners_per_game", false, func(s *TeamSeasonStats) float64 { return s.CornersPerGame }},
+	{"cards_per_game", true, func(s *TeamSeasonStats) float64 { return s.CardsPerGame }},
+}
+
+// getLeagueStats compares a league's teams on each season metric
+func getLeagueStats(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	league := vars["league"]
+
+	mutex.RLock()
+	defer mutex.RUnlock()
+
+	finished := seasonFinishedMatches()
+	var teamStats []*TeamSeasonStats
+	for _, team := range teams {
+		if team.League == league {
+			teamStats = append(teamStats, calculateTeamSeasonStats(team, finished))
+		}
+	}
+	if len(teamStats) == 0 {
+		http.Error(w, "League not found", http.StatusNotFound)
+		return
+	}
+	sort.Slice(teamStats, func(i, j int) bool { return teamStats[i].TeamID < teamStats[j].TeamID })
+
+	// Equal values share a rank
+	rankings := make(map[string][]map[string]interface{})
+	for _, metric := range leagueStatMetrics {
+		ranked := append([]*TeamSeasonStats{}, teamStats...)
+		sort.SliceStable(ranked, func(i, j int) bool {
+			if metric.LowerIsBetter {
+				return metric.Value(ranked[i]) < metric.Value(ranked[j])
+			}
+			return metric.Value(ranked[i]) > metric.Value(ranked[j])
+		})
+
+		entries := make([]map[string]interface{}, len(ranked))
+		for i, stats := range ranked {
+			rank := i + 1
+			if i > 0 && metric.Value(stats) == metric.Value(ranked[i-1]) {
+				rank = entries[i-1]["rank"].(int)
+			}
+			entries[i] = map[string]interface{}{
+				"rank":      rank,
+				"team_id":   stats.TeamID,
+				"team_name": stats.TeamName,
+				"value":     metric.Value(stats),
+			}
+		}
+		rankings[metric.Name] = entries
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"league":    league,
+		"season":    currentSeason,
+		"rankings":  rankings,
+		"teams":     teamStats,
+		"count":     len(teamStats),
+		"timestamp": time.Now(),
+	})
+}
+
+// Templated news articles built from match and season data
+func generateHalftimeNews(matchID int, match *Match) {
+	stats := matchStats[matchID]
+	if stats == nil {
+		return
+	}
+
+	title := fmt.Sprintf("HT: %s %d-%d %s", match.HomeTeam.Name, match.HomeScore, match.AwayScore, match.AwayTeam.Name)
+	var summary string
+	switch {
+	case match.HomeScore > match.AwayScore:
+		summary = fmt.Sprintf("%s lead at the break", match.HomeTeam.Name)
+	case match.AwayScore > match.HomeScore:
+		summary = fmt.Sprintf("%s lead at the break", match.AwayTeam.Name)
+	default:
+		summary = "All square at the break"
+	}
+
+	content := fmt.Sprintf("%s at %s. %s have had %d%% of the ball with %d shots (%d on target) to %s's %d (%d on target).%s",
+		summary, match.Venue, match.HomeTeam.Name, stats.HomePossession, stats.HomeShots, stats.HomeShotsOnTarget,
+		match.AwayTeam.Name, stats.AwayShots, stats.AwayShotsOnTarget, formatMatchScorers(matchID))
+	addNewsEntry(NewsHalftime, title, content, matchID, match.HomeTeam.ID, match.AwayTeam.ID)
+}
+
+func generateFulltimeNews(matchID int, match *Match) {
+	var title string
+	switch {
+	case match.HomeScore > match.AwayScore:
+		title = fmt.Sprintf("%s beat %s %d-%d", match.HomeTeam.Name, match.AwayTeam.Name, match.HomeScore, match.AwayScore)
+	case match.AwayScore > match.HomeScore:
+		title = fmt.Sprintf("%s win %d-%d at %s", match.AwayTeam.Name, match.AwayScore, match.HomeScore, match.HomeTeam.Name)
+	default:
+		title = fmt.Sprintf("%s and %s draw %d-%d", match.HomeTeam.Name, match.AwayTeam.Name, match.HomeScore, match.AwayScore)
+	}
+
+	content := fmt.Sprintf("Full time in matchweek %d of the %s: %s %d-%d %s in front of %d fans at %s.%s",
+		match.MatchweekNum, match.Competition, match.HomeTeam.Name, match.HomeScore, match.AwayScore,
+		match.AwayTeam.Name, match.Attendance, match.Venue, formatMatchScorers(matchID))
+
+	// Name the best-rated player once ratings are in
+	bestRating := 0.0
+	var best *Player
+	for playerID, rating := range match.PlayerRatings {
+		if player, exists := players[playerID]; exists && rating > bestRating {
+			bestRating, best = rating, player
+		}
+	}
+	if best != nil {
+		content += fmt.Sprintf(" Player of the match: %s (%.1f).", best.Name, bestRating)
+	}
+
+	addNewsEntry(NewsFulltime, title, content, matchID, match.HomeTeam.ID, match.AwayTeam.ID)
+}
+
+func generateRedCardNews(matchID int, match *Match, player *Player) *NewsEntry {
+	opponent := match.AwayTeam
+	if player.TeamID == match.AwayTeam.ID {
+		opponent = match.HomeTeam
+	}
+
+	// Count every dismissal of the side so far, this one included
+	sentOff := 0
+	booked := false
+	for _, line := range matchPlayerStats[matchID] {
+		if line.TeamID == player.TeamID && line.RedCards > 0 {
+			sentOff++
+		}
+		if line.PlayerID == player.ID && line.YellowCards > 0 {
+			booked = true
+		}
+	}
+	sentOff = max(1, sentOff)
+
+	dismissal := "was shown a straight red card"
+	if booked {
+		dismissal = "was sent off, having already been booked,"
+	}
+	content := fmt.Sprintf("%s are down to %s men after %s %s in the %d' minute with the score at %d-%d.",
+		getTeamName(player.TeamID), playersLeft(11-sentOff), player.Name, dismissal, match.Minute, match.HomeScore, match.AwayScore)
+	if sentOff > 1 {
+		content += fmt.Sprintf(" It is their %s red card of the match.", ordinal(sentOff))
+	}
+
+	return addNewsEntry(NewsRedCard,
+		fmt.Sprintf("%s sent off against %s", player.Name, opponent.Name),
+		content, matchID, match.HomeTeam.ID, match.AwayTeam.ID)
+}
+
+// playersLeft spells out how many men a side has left
+func playersLeft(count int) string {
+	words := []string{"no", "one", "two", "three", "four", "five", "six", "seven", "eight", "nine", "ten"}
+	if count >= 0 && count < len(words) {
+		return words[count]
+	}
+	return strconv.Itoa(count)
+}
+
+// ordinal formats 1 as "1st", 12 as "12th", 22 as "22nd"
+func ordinal(n int) string {
+	suffix := "th"
+	switch n % 100 {
+	case 11, 12, 13:
+	default:
+		switch n % 10 {
+		case 1:
+			suffix = "st"
+		case 2:
+			suffix = "nd"
+		case 3:
+			suffix = "rd"
+		}
+	}
+	return fmt.Sprintf("%d%s", n, suffix)
+}
+
+// Count a goal and announce the third; returns the hat-trick article, if any
+func recordMatchGoal(matchID int, match *Match, scorer *Player) *NewsEntry {
+	if matchScorers[matchID] == nil {
+		matchScorers[matchID] = make(map[int]int)
+	}
+	matchScorers[matchID][scorer.ID]++
+
+	if matchScorers[matchID][scorer.ID] != 3 {
+		return nil
+	}
+	return addNewsEntry(NewsHatTrick,
+		fmt.Sprintf("Hat-trick for %s!", scorer.Name),
+		fmt.Sprintf("%s completed a hat-trick in the %d' minute for %s. The score is now %s %d-%d %s. It is their %s goal of the season.",
+			scorer.Name, match.Minute, getTeamName(scorer.TeamID), match.HomeTeam.Name, match.HomeScore,
+			match.AwayScore, match.AwayTeam.Name, ordinal(scorer.SeasonStats.GoalsThisSeason)),
+		matchID, match.HomeTeam.ID, match.AwayTeam.ID)
+}
+
+func formatMatchScorers(matchID int) string {
+	var scorers []string
+	for playerID, goals := range matchScorers[matchID] {
+		if player, exists := players[playerID]; exists {
+			if goals > 1 {
+				scorers = append(scorers, fmt.Sprintf("%s (%d)", player.Name, goals))
+			} else {
+				scorers = append(scorers, player.Name)
+			}
+		}
+	}
+	if len(scorers) == 0 {
+		return ""
+	}
+	sort.Strings(scorers)
+	return " Scorers: " + strings.Join(scorers, ", ") + "."
+}
+
+func currentLeagueLeaders() map[string]int {
+	leaders := make(map[string]int)
+	for league, table := range leagueTables {
+		if len(table) > 0 {
+			leaders[league] = table[0].Team.ID
+		}
+	}
+	return leaders
+}
+
+func generateLeaderChangeNews(previous map[string]int, match *Match) {
+	table := leagueTables[match.Competition]
+	if len(table) == 0 || previous[match.Competition] == table[0].Team.ID {
+		return
+	}
+
+	// A tie on points and goal difference is not a new leader
+	leader := table[0]
+	for _, entry := range table {
+		if entry.Team.ID == previous[match.Competition] &&
+			entry.Points == leader.Points && entry.GoalDiff == leader.GoalDiff {
+			return
+		}
+	}
+
+	content := fmt.Sprintf("%s go top of the %s after matchweek %d with %d points from %d matches (goal difference %+d).",
+		leader.Team.Name, match.Competition, match.MatchweekNum, leader.Points, leader.Played, leader.GoalDiff)
+	teamIDs := []int{leader.Team.ID}
+	if previousTeam, exists := teams[previous[match.Competition]]; exists {
+		content += fmt.Sprintf(" %s drop out of first place.", previousTeam.Name)
+		teamIDs = append(teamIDs, previousTeam.ID)
+	}
+
+	addNewsEntry(NewsLeaderChange, fmt.Sprintf("%s go top of the %s", leader.Team.Name, match.Competition),
+		content, match.ID, teamIDs...)
+}
+
+func generateSeasonEndNews(champion *TeamInfo, topScorer, playerOfSeason *Player) {
+	content := fmt.Sprintf("%s are the season %d champions.", champion.Name, currentSeason)
+	if topScorer != nil && topScorer.Name != "" {
+		content += fmt.Sprintf(" %s (%s) finished as top scorer with %d goals.",
+			topScorer.Name, getTeamName(topScorer.TeamID), topScorer.SeasonStats.GoalsThisSeason)
+	}
+	if playerOfSeason != nil && playerOfSeason.Name != "" {
+		content += fmt.Sprintf(" %s was named player of the season with an average rating of %.2f.",
+			playerOfSeason.Name, playerOfSeason.SeasonStats.AverageRating)
+	}
+
+	addNewsEntry(NewsSeasonEnd, fmt.Sprintf("%s crowned season %d champions", champion.Name, currentSeason),
+		content, 0, champion.ID)
+}
+
+// updateRecords checks a finished match against the all-time records and announces any
+// that fall once enough matches have been played. Caller must hold mutex
+func updateRecords(matchID int, match *Match) {
+	allTimeRecords.MatchesRecorded++
+	announce := allTimeRecords.MatchesRecorded > RecordNewsMinMatches
+
+	updateScoreRecords(matchID, match, announce)
+	updateTeamRun(match, &match.HomeTeam, match.HomeScore, match.AwayScore, announce)
+	updateTeamRun(match, &match.AwayTeam, match.AwayScore, match.HomeScore, announce)
+}
+
+// confirmGoal counts a goal once VAR can no longer take it away and checks the records
+// it may have broken. Caller must hold mutex
+func confirmGoal(matchID int, match *Match) {
+	recordGoalMetric(match.Competition)
+
+	announce := allTimeRecords.MatchesRecorded >= RecordNewsMinMatches
+	updateScoreRecords(matchID, match, announce)
+	if goal := lastTimelineEvent(matchID, EventGoal); goal != nil && announce {
+		if scorer := players[goal.PlayerID]; scorer != nil {
+			checkCareerGoalRecord(matchID, scorer)
+		}
+	}
+}
+
+// updateScoreRecords puts a match's current score and opening goal in the records, replacing
+// the entry from its previous goal
+func updateScoreRecords(matchID int, match *Match, announce bool) {
+	result := MatchRecord{
+		MatchID:    match.ID,
+		Season:     match.Season,
+		Matchweek:  match.MatchweekNum,
+		League:     match.Competition,
+		HomeTeamID: match.HomeTeam.ID,
+		HomeTeam:   match.HomeTeam.Name,
+		AwayTeamID: match.AwayTeam.ID,
+		AwayTeam:   match.AwayTeam.Name,
+		HomeScore:  match.HomeScore,
+		AwayScore:  match.AwayScore,
+		Date:       match.StartTime,
+	}
+	scoreline := fmt.Sprintf("%s %d-%d %s", match.HomeTeam.Name, match.HomeScore, match.AwayScore, match.AwayTeam.Name)
+
+	// A level score takes the match back out of the biggest wins
+	margin := match.HomeScore - match.AwayScore
+	result.Value = max(margin, -margin)
+	var broken bool
+	allTimeRecords.BiggestWins, broken = insertMatchRecord(allTimeRecords.BiggestWins, result)
+	if broken && announce {
+		addNewsEntry(NewsRecord, fmt.Sprintf("Record win: %s", scoreline),
+			fmt.Sprintf("%s is the biggest win on record, by %d goals, in matchweek %d of season %d.",
+				scoreline, result.Value, match.MatchweekNum, match.Season),
+			matchID, match.HomeTeam.ID, match.AwayTeam.ID)
+	}
+
+	if total := match.HomeScore + match.AwayScore; total > 0 {
+		result.Value = total
+		allTimeRecords.HighestScoring, broken = insertMatchRecord(allTimeRecords.HighestScoring, result)
+		if broken && announce {
+			addNewsEntry(NewsRecord, fmt.Sprintf("Goal fest: %s", scoreline),
+				fmt.Sprintf("%s is the highest-scoring match on record with %d goals.", scoreline, total),
+				matchID, match.HomeTeam.ID, match.AwayTeam.ID)
+		}
+	}
+
+	if goal := firstGoal(match); goal != nil {
+		allTimeRecords.FastestGoals, broken = insertGoalRecord(allTimeRecords.FastestGoals, *goal)
+		if broken && announce {
+			addNewsEntry(NewsRecord, fmt.Sprintf("%s scores the fastest goal on record", goal.PlayerName),
+				fmt.Sprintf("%s's goal for %s against %s after %d minute(s) is the fastest on record.",
+					goal.PlayerName, goal.TeamName, goal.Opponent, goal.Minute),
+				matchID, goal.TeamID)
+		}
+	}
+}
+
+// insertMatchRecord ranks a result by value, replacing the match's earlier entry; ties keep
+// the older record ahead and a value of 0 only removes the entry. Reports whether it has just
+// beaten the previous best
+func insertMatchRecord(list []MatchRecord, record MatchRecord) ([]MatchRecord, bool) {
+	led := false
+	for i, existing := range list {
+		if existing.MatchID == record.MatchID {
+			led = i == 0
+			list = append(list[:i], list[i+1:]...)
+			break
+		}
+	}
+	if record.Value <= 0 {
+		return list, false
+	}
+
+	position := len(list)
+	for i, existing := range list {
+		if record.Value > existing.Value {
+			position = i
+			break
+		}
+	}
+	if position >= MaxRecordEntries {
+		return list, false
+	}
+	list = append(list[:position], append([]MatchRecord{record}, list[position:]...)...)
+	if len(list) > MaxRecordEntries {
+		list = list[:MaxRecordEntries]
+	}
+	return list, position == 0 && len(list) > 1 && !led
+}
+
+// insertGoalRecord ranks a goal by minute, earliest first; ties keep the older record ahead.
+// A match keeps the goal it was first entered with
+func insertGoalRecord(list []GoalRecord, record GoalRecord) ([]GoalRecord, bool) {
+	for _, existing := range list {
+		if existing.MatchID == record.MatchID {
+			return list, false
+		}
+	}
+
+	position := len(list)
+	for i, existing := range list {
+		if record.Minute < existing.Minute {
+			position = i
+			break
+		}
+	}
+	if position >= MaxRecordEntries {
+		return list, false
+	}
+	list = append(list[:position], append([]GoalRecord{record}, list[position:]...)...)
+	if len(list) > MaxRecordEntries {
+		list = list[:MaxRecordEntries]
+	}
+	return list, position == 0 && len(list) > 1
+}
+
+// firstGoal returns the opening goal of a match that VAR let stand
+func firstGoal(match *Match) *GoalRecord {
+	for _, event := range matchTimelines[match.ID] {
+		if event.Type != EventGoal || event.Overturned {
+			continue
+		}
+		goal := &GoalRecord{
+			MatchID:    match.ID,
+			Season:     match.Season,
+			Minute:     event.Minute,
+			PlayerID:   event.PlayerID,
+			PlayerName: event.PlayerName,
+			TeamID:     event.TeamID,
+			Date:       event.Timestamp,
+		}
+		if event.TeamID == match.HomeTeam.ID {
+			goal.TeamName, goal.Opponent = match.HomeTeam.Name, match.AwayTeam.Name
+		} else {
+			goal.TeamName, goal.Opponent = match.AwayTeam.Name, match.HomeTeam.Name
+		}
+		return goal
+	}
+	return nil
+}
+
+// updateTeamRun extends or ends a team's winning and unbeaten runs after a result
+func updateTeamRun(match *Match, team *TeamInfo, goalsFor, goalsAgainst int, announce bool) {
+	run := teamRuns[team.ID]
+	if run == nil {
+		run = &teamRun{}
+		teamRuns[team.ID] = run
+	}
+
+	if goalsFor > goalsAgainst {
+		if run.wins == 0 {
+			run.winStart, run.winSeason = match.ID, match.Season
+		}
+		run.wins++
+		var broken bool
+		allTimeRecords.WinningStreaks, broken = recordStreak(allTimeRecords.WinningStreaks, team, run.wins, run.winStart, run.winSeason, match.ID)
+		if broken && announce {
+			addNewsEntry(NewsRecord, fmt.Sprintf("%s set a record with %d straight wins", team.Name, run.wins),
+				fmt.Sprintf("Victory over %s makes it %d wins in a row for %s, the longest winning streak on record.",
+					opponentName(match, team.ID), run.wins, team.Name),
+				match.ID, team.ID)
+		}
+	} else {
+		allTimeRecords.WinningStreaks = endStreak(allTimeRecords.WinningStreaks, team.ID, run.winStart)
+		run.wins = 0
+	}
+
+	if goalsFor >= goalsAgainst {
+		if run.unbeaten == 0 {
+			run.unbeatenStart, run.unbeatenSeason = match.ID, match.Season
+		}
+		run.unbeaten++
+		var broken bool
+		allTimeRecords.UnbeatenStreaks, broken = recordStreak(allTimeRecords.UnbeatenStreaks, team, run.unbeaten, run.unbeatenStart, run.unbeatenSeason, match.ID)
+		if broken && announce {
+			addNewsEntry(NewsRecord, fmt.Sprintf("%s unbeaten in a record %d matches", team.Name, run.unbeaten),
+				fmt.Sprintf("%s are now %d matches unbeaten after facing %s, the longest unbeaten run on record.",
+					team.Name, run.unbeaten, opponentName(match, team.ID)),
+				match.ID, team.ID)
+		}
+	} else {
+		allTimeRecords.UnbeatenStreaks = endStreak(allTimeRecords.UnbeatenStreaks, team.ID, run.unbeatenStart)
+		run.unbeaten = 0
+	}
+}
+
+func opponentName(match *Match, teamID int) string {
+	if match.HomeTeam.ID == teamID {
+		return match.AwayTeam.Name
+	}
+	return match.HomeTeam.Name
+}
+
+// recordStreak updates or adds a run, longest first with older runs ahead on ties.
+// Reports whether the run has just overtaken every other run on record
+func recordStreak(list []StreakRecord, team *TeamInfo, length, startMatchID, startSeason, lastMatchID int) ([]StreakRecord, bool) {
+	if length < MinRecordStreak {
+		return list, false
+	}
+
+	best := 0
+	found := false
+	for i := range list {
+		if list[i].TeamID == team.ID && list[i].StartMatchID == startMatchID {
+			list[i].Length, list[i].LastMatchID, list[i].LastUpdate = length, lastMatchID, time.Now()
+			found = true
+		} else {
+			best = max(best, list[i].Length)
+		}
+	}
+	if !found {
+		list = append(list, StreakRecord{
+			TeamID:       team.ID,
+			TeamName:     team.Name,
+			League:       team.League,
+			Length:       length,
+			Active:       true,
+			StartMatchID: startMatchID,
+			StartSeason:  startSeason,
+			LastMatchID:  lastMatchID,
+			LastUpdate:   time.Now(),
+		})
+	}
+
+	sort.SliceStable(list, func(i, j int) bool {
+		if list[i].Length != list[j].Length {
+			return list[i].Length > list[j].Length
+		}
+		return list[i].StartMatchID < list[j].StartMatchID
+	})
+	if len(list) > MaxRecordEntries {
+		list = list[:MaxRecordEntries]
+	}
+	return list, best > 0 && length == best+1
+}
+
+// endStreak marks a run as finished
+func endStreak(list []StreakRecord, teamID, startMatchID int) []StreakRecord {
+	for i := range list {
+		if list[i].TeamID == teamID && list[i].StartMatchID == startMatchID {
+			list[i].Active = false
+		}
+	}
+	return list
+}
+
+// playerSeasonRecord is a player's current season line
+func playerSeasonRecord(player *Player, inProgress bool) PlayerSeasonRecord {
+	stats := player.SeasonStats
+	record := PlayerSeasonRecord{
+		Season:      currentSeason,
+		TeamID:      player.TeamID,
+		Age:         player.Age,
+		Matches:     stats.MatchesPlayed,
+		Minutes:     stats.MinutesPlayed,
+		Goals:       stats.GoalsThisSeason,
+		Assists:     stats.AssistsThisSeason,
+		YellowCards: stats.YellowCardsThisSeason,
+		RedCards:    stats.RedCardsThisSeason,
+		InProgress:  inProgress,
+	}
+	if stats.MatchesPlayed > 0 {
+		record.AverageRating = math.Round(stats.TotalRating/float64(stats.MatchesPlayed)*100) / 100
+	}
+	if team := teams[player.TeamID]; team != nil {
+		record.TeamName, record.League = team.Name, team.League
+	}
+	return record
+}
+
+// playerCareer returns a player's seasons, the current one included for active players
+func playerCareer(player *Player) []PlayerSeasonRecord {
+	seasons := append([]PlayerSeasonRecord{}, playerCareers[player.ID]...)
+	if _, active := players[player.ID]; active && player.SeasonStats.MatchesPlayed > 0 {
+		seasons = append(seasons, playerSeasonRecord(player, true))
+	}
+	return seasons
+}
+
+// careerTotals sums a player's seasons; the average rating is weighted by matches
+func careerTotals(seasons []PlayerSeasonRecord) map[string]interface{} {
+	matches, minutes, goals, assists, yellows, reds := 0, 0, 0, 0, 0, 0
+	ratingSum := 0.0
+	for _, season := range seasons {
+		matches += season.Matches
+		minutes += season.Minutes
+		goals += season.Goals
+		assists += season.Assists
+		yellows += season.YellowCards
+		reds += season.RedCards
+		ratingSum += season.AverageRating * float64(season.Matches)
+	}
+	averageRating := 0.0
+	if matches > 0 {
+		averageRating = math.Round(ratingSum/float64(matches)*100) / 100
+	}
+	return map[string]interface{}{
+		"seasons":        len(seasons),
+		"matches":        matches,
+		"minutes":        minutes,
+		"goals":          goals,
+		"assists":        assists,
+		"yellow_cards":   yellows,
+		"red_cards":      reds,
+		"average_rating": averageRating,
+	}
+}
+
+// checkCareerGoalRecord announces a scorer whose latest goal has just passed the all-time
+// career goals record. Player.Goals is the live career total
+func checkCareerGoalRecord(matchID int, scorer *Player) {
+	best := 0
+	for _, pool := range []map[int]*Player{players, retiredPlayers} {
+		for _, player := range pool {
+			if player.ID != scorer.ID {
+				best = max(best, player.Goals)
+			}
+		}
+	}
+	if best == 0 || scorer.Goals != best+1 {
+		return
+	}
+
+	addNewsEntry(NewsRecord, fmt.Sprintf("%s becomes the all-time top scorer", scorer.Name),
+		fmt.Sprintf("%s (%s) now has %d career goals, passing the previous record of %d.",
+			scorer.Name, getTeamName(scorer.TeamID), scorer.Goals, best),
+		matchID, scorer.TeamID)
+}
+
+// titleCounts tallies league titles per team from the season history
+func titleCounts() map[int]int {
+	titles := make(map[int]int)
+	for _, season := range seasonHistory {
+		for _, league := range season.Leagues {
+			titles[league.Champion.ID]++
+		}
+	}
+	return titles
+}
+
+// checkTitleRecord announces a champion that now holds more titles than any other club
+func checkTitleRecord(record *SeasonHistory) {
+	titles := titleCounts()
+	for _, league := range record.Leagues {
+		champion := league.Champion
+		best := 0
+		for teamID, count := range titles {
+			if teamID != champion.ID {
+				best = max(best, count)
+			}
+		}
+		if best == 0 || titles[champion.ID] != best+1 {
+			continue
+		}
+		addNewsEntry(NewsRecord, fmt.Sprintf("%s become the most successful club", champion.Name),
+			fmt.Sprintf("The %s crown is title number %d for %s, more than any other club.", league.League, titles[champion.ID], champion.Name),
+			0, champion.ID)
+	}
+}
+
+// getRecords serves the all-time records, title counts and career leaders
+func getRecords(w http.ResponseWriter, r *http.Request) {
+	limit := MaxRecordEntries
+	if value := r.URL.Query().Get("limit"); value != "" {
+		if parsed, err := strconv.Atoi(value); err == nil && parsed > 0 {
+			limit = min(parsed, MaxRecordEntries)
+		}
+	}
+
+	mutex.RLock()
+	defer mutex.RUnlock()
+
+	// Most titles, ties by earliest first title
+	titles := titleCounts()
+	firstTitle := make(map[int]int)
+	for _, season := range seasonHistory {
+		for _, league := range season.Leagues {
+			if firstTitle[league.Champion.ID] == 0 {
+				firstTitle[league.Champion.ID] = season.Season
+			}
+		}
+	}
+	mostTitles := make([]map[string]interface{}, 0, len(titles))
+	for teamID, count := range titles {
+		mostTitles = append(mostTitles, map[string]interface{}{
+			"team_id":      teamID,
+			"team_name":    getTeamFullName(teamID),
+			"titles":       count,
+			"first_season": firstTitle[teamID],
+		})
+	}
+	sort.Slice(mostTitles, func(i, j int) bool {
+		if mostTitles[i]["titles"].(int) != mostTitles[j]["titles"].(int) {
+			return mostTitles[i]["titles"].(int) > mostTitles[j]["titles"].(int)
+		}
+		return mostTitles[i]["first_season"].(int) < mostTitles[j]["first_season"].(int)
+	})
+
+	// Career leaders across active and retired players
+	type careerLeader struct {
+		PlayerID   int    `json:"player_id"`
+		PlayerName string `json:"player_name"`
+		Position   string `json:"position"`
+		TeamName   string `json:"team_name"`
+		Retired    bool   `json:"retired"`
+		Value      int    `json:"value"`
+		Seasons    int    `json:"seasons"`
+	}
+	var goals, assists, appearances []careerLeader
+	for _, pool := range []map[int]*Player{players, retiredPlayers} {
+		for _, player := range pool {
+			totals := careerTotals(playerCareer(player))
+			if totals["matches"].(int) == 0 {
+				continue
+			}
+			leader := careerLeader{
+				PlayerID:   player.ID,
+				PlayerName: player.Name,
+				Position:   player.Position,
+				TeamName:   getTeamFullName(player.TeamID),
+				Retired:    player.RetiredSeason > 0,
+				Seasons:    totals["seasons"].(int),
+			}
+			leader.Value = totals["goals"].(int)
+			goals = append(goals, leader)
+			leader.Value = totals["assists"].(int)
+			assists = append(assists, leader)
+			leader.Value = totals["matches"].(int)
+			appearances = append(appearances, leader)
+		}
+	}
+	top := func(leaders []careerLeader) []careerLeader {
+		sort.Slice(leaders, func(i, j int) bool {
+			if leaders[i].Value != leaders[j].Value {
+				return leaders[i].Value > leaders[j].Value
+			}
+			return leaders[i].PlayerID < leaders[j].PlayerID
+		})
+		result := []careerLeader{}
+		for _, leader := range leaders[:min(len(leaders), limit)] {
+			if leader.Value > 0 {
+				result = append(result, leader)
+			}
+		}
+		return result
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"biggest_wins":       allTimeRecords.BiggestWins[:min(len(allTimeRecords.BiggestWins), limit)],
+		"highest_scoring":    allTimeRecords.HighestScoring[:min(len(allTimeRecords.HighestScoring), limit)],
+		"fastest_goals":      allTimeRecords.FastestGoals[:min(len(allTimeRecords.FastestGoals), limit)],
+		"winning_streaks":    allTimeRecords.WinningStreaks[:min(len(allTimeRecords.WinningStreaks), limit)],
+		"unbeaten_streaks":   allTimeRecords.UnbeatenStreaks[:min(len(allTimeRecords.UnbeatenStreaks), limit)],
+		"most_titles":        mostTitles[:min(len(mostTitles), limit)],
+		"career_goals":       top(goals),
+		"career_assists":     top(assists),
+		"career_appearances": top(appearances),
+		"matches_recorded":   allTimeRecords.MatchesRecorded,
+		"seasons_completed":  len(seasonHistory),
+		"timestamp":          time.Now(),
+	})
+}
+
+// getPlayerCareer serves a player's season-by-season record and career totals
+func getPlayerCareer(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		http.Error(w, "Invalid player ID", http.StatusBadRequest)
+		return
+	}
+
+	mutex.RLock()
+	defer mutex.RUnlock()
+
+	player, exists := players[id]
+	if !exists {
+		player, exists = retiredPlayers[id]
+	}
+	if !exists {
+		http.Error(w, "Player not found", http.StatusNotFound)
+		return
+	}
+
+	seasons := playerCareer(player)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"player_id":      player.ID,
+		"player_name":    player.Name,
+		"position":       player.Position,
+		"age":            player.Age,
+		"team_id":        player.TeamID,
+		"team_name":      getTeamFullName(player.TeamID),
+		"retired_season": player.RetiredSeason,
+		"seasons":        seasons,
+		"totals":         careerTotals(seasons),
+		"count":          len(seasons),
+		"timestamp":      time.Now(),
+	})
+}
+
+func generateSeasonAwardsNews(record *SeasonHistory) {
+	var parts []string
+	for _, league := range record.Leagues {
+		part := fmt.Sprintf("%s won the %s", league.Champion.Name, league.League)
+		if len(league.GoldenBoot) > 0 {
+			part += fmt.Sprintf(", where %s took the golden boot with %.0f goals", league.GoldenBoot[0].PlayerName, league.GoldenBoot[0].Value)
+		}
+		parts = append(parts, part+".")
+	}
+	if award := record.BestYoungPlayer; award != nil {
+		parts = append(parts, fmt.Sprintf("%s (%s) is young player of the season with an average rating of %.2f.",
+			award.PlayerName, award.TeamName, award.Value))
+	}
+	if award := record.BestGoalkeeper; award != nil {
+		parts = append(parts, fmt.Sprintf("%s (%s) kept the most clean sheets (%.0f).", award.PlayerName, award.TeamName, award.Value))
+	}
+	if award := record.ManagerOfSeason; award != nil {
+		parts = append(parts, fmt.Sprintf("%s of %s is manager of the season after a rating gain of %+.1f.",
+			award.Manager, award.TeamName, award.RatingGain))
+	}
+	if len(parts) == 0 {
+		return
+	}
+
+	addNewsEntry(NewsSeasonEnd, fmt.Sprintf("Season %d awards", record.Season), strings.Join(parts, " "), 0)
+}
+
+func getNews(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	newsType := query.Get("type")
+
+	matchID := 0
+	if value := query.Get("match_id"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			http.Error(w, "Invalid match ID", http.StatusBadRequest)
+			return
+		}
+		matchID = parsed
+	}
+
+	teamID := 0
+	if value := query.Get("team_id"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			http.Error(w, "Invalid team ID", http.StatusBadRequest)
+			return
+		}
+		teamID = parsed
+	}
+
+	options, err := parseListOptions(r, "-id")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	mutex.RLock()
+	defer mutex.RUnlock()
+
+	filtered := []*NewsEntry{}
+	for _, entry := range newsEntries {
+		if matchID != 0 && entry.MatchID != matchID {
+			continue
+		}
+		if newsType != "" && entry.Type != newsType {
+			continue
+		}
+		if teamID != 0 {
+			found := false
+			for _, id := range entry.TeamIDs {
+				if id == teamID {
+					found = true
+					break
+				}
+			}
+			if !found {
+				continue
+			}
+		}
+		filtered = append(filtered, entry)
+	}
+
+	writeListResponse(w, r, filtered, options, "id", nil)
+}
+
+func updateTeamStats(teamID, points, wins, draws, losses int, match *Match) {
+	team := teams[teamID]
+	if team == nil {
+		return
+	}
+
+	// Determine result for form tracking
+	result := FormLoss
+	if wins > 0 {
+		result = FormWin
+	} else if draws > 0 {
+		result = FormDraw
+	}
+
+	// Properly determine if it's a home game
+	isHome := match.HomeTeam.ID == teamID
+
+	// Update team form with correct home/away status
+	updateTeamForm(team, result, isHome)
+
+	// Update team statistics in league table
+	for league, table := range leagueTables {
+		for _, teamEntry := range table {
+			if teamEntry.Team.ID == teamID {
+				teamEntry.Points += points
+				teamEntry.Won += wins
+				teamEntry.Drawn += draws
+				teamEntry.Lost += losses
+				teamEntry.Played = teamEntry.Won + teamEntry.Drawn + teamEntry.Lost
+
+				// Update goals for and against based on match result
+				if isHome {
+					teamEntry.GoalsFor += match.HomeScore
+					teamEntry.GoalsAgainst += match.AwayScore
+				} else {
+					teamEntry.GoalsFor += match.AwayScore
+					teamEntry.GoalsAgainst += match.HomeScore
+				}
+				teamEntry.GoalDiff = teamEntry.GoalsFor - teamEntry.GoalsAgainst
+
+				// Update the team reference with form data
+				teamEntry.Team = *team
+
+				teamEntry.LastUpdate = time.Now()
+
+				logDebug("📋 %s: %d points (%d played, %d won, %d drawn, %d lost, %d GF, %d GA, %d GD) - Form: %v",
+					teamEntry.Team.ShortName, teamEntry.Points, teamEntry.Played,
+					teamEntry.Won, teamEntry.Drawn, teamEntry.Lost, teamEntry.GoalsFor,
+					teamEntry.GoalsAgainst, teamEntry.GoalDiff, team.Form)
+				break
+			}
+		}
+
+		// Resort league table by points
+		sortLeagueTable(leagueTables[league])
+	}
+}
+
+func sortLeagueTable(table []*LeagueTable) {
+	// Simple bubble sort by points, then goal difference
+	for i := 0; i < len(table)-1; i++ {
+		for j := 0; j < len(table)-i-1; j++ {
+			if table[j].Points < table[j+1].Points ||
+				(table[j].Points == table[j+1].Points && table[j].GoalDiff < table[j+1].GoalDiff) {
+				table[j], table[j+1] = table[j+1], table[j]
+			}
+		}
+	}
+
+	// Update positions
+	for i, team := range table {
+		team.Position = i + 1
+	}
+}
+
+func getSeasonSchedule(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	league := vars["league"]
+
+	mutex.RLock()
+	schedules, exists := seasonSchedules[league]
+	if !exists {
+		mutex.RUnlock()
+		http.Error(w, "League not found", http.StatusNotFound)
 		return
 	}
 
-	err = tmpl.Execute(w, templateData)
+	// Filter by matchday if specified
+	matchdayStr := r.URL.Query().Get("matchday")
+	if matchdayStr != "" {
+		matchday, err := strconv.Atoi(matchdayStr)
+		if err == nil {
+			filteredSchedules := getScheduledMatches(league, matchday)
+			mutex.RUnlock()
+
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"league":    league,
+				"matchday":  matchday,
+				"schedules": filteredSchedules,
+				"count":     len(filteredSchedules),
+				"timestamp": time.Now(),
+			})
+			return
+		}
+	}
+
+	// Return all schedules for the league
+	mutex.RUnlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"league":    league,
+		"schedules": schedules,
+		"count":     len(schedules),
+		"timestamp": time.Now(),
+	})
+}
+
+func getTeamForm(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
 	if err != nil {
-		http.Error(w, "Template execution error", http.StatusInternalServerError)
+		http.Error(w, "Invalid team ID", http.StatusBadRequest)
 		return
 	}
-}
 
-func min(a, b int) int {
-	if a < b {
-		return a
+	mutex.RLock()
+	team, exists := teams[id]
+	mutex.RUnlock()
+
+	if !exists {
+		http.Error(w, "Team not found", http.StatusNotFound)
+		return
 	}
-	return b
-}
 
-func max(a, b int) int {
-	if a > b {
-		return a
+	formData := map[string]interface{}{
+		"team_id":     team.ID,
+		"team_name":   team.Name,
+		"short_name":  team.ShortName,
+		"league":      team.League,
+		"form":        team.Form,
+		"form_points": team.FormPoints,
+		"timestamp":   time.Now(),
 	}
-	return b
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(formData)
 }
 
-func shouldEndSeason() bool {
-	// Check if all matches in all leagues are finished
-	for _, league := range []string{LeaguePremier, LeagueCommunityLeague} {
-		if schedules, exists := seasonSchedules[league]; exists {
-			for _, schedule := range schedules {
-				if !schedule.IsPlayed {
-					return false
-				}
+func getLeagueForm(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	league := vars["league"]
+
+	mutex.RLock()
+	var leagueTeams []*TeamInfo
+	for _, team := range teams {
+		if team.League == league {
+			leagueTeams = append(leagueTeams, team)
+		}
+	}
+	mutex.RUnlock()
+
+	if len(leagueTeams) == 0 {
+		http.Error(w, "League not found", http.StatusNotFound)
+		return
+	}
+
+	// Sort teams by form points (descending)
+	for i := 0; i < len(leagueTeams)-1; i++ {
+		for j := 0; j < len(leagueTeams)-i-1; j++ {
+			if leagueTeams[j].FormPoints < leagueTeams[j+1].FormPoints {
+				leagueTeams[j], leagueTeams[j+1] = leagueTeams[j+1], leagueTeams[j]
 			}
 		}
 	}
-	return true
+
+	formTable := make([]map[string]interface{}, len(leagueTeams))
+	for i, team := range leagueTeams {
+		formTable[i] = map[string]interface{}{
+			"position":    i + 1,
+			"team_id":     team.ID,
+			"team_name":   team.Name,
+			"short_name":  team.ShortName,
+			"form":        team.Form,
+			"form_points": team.FormPoints,
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"league":     league,
+		"form_table": formTable,
+		"count":      len(formTable),
+		"timestamp":  time.Now(),
+	})
 }
 
-func startNewSeason() {
-	logInfo("🎬 Starting new season %d...", currentSeason+1)
-	currentSeason++
-	currentMatchweek = 1
+func getMatchdaySchedule(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	matchdayStr := vars["matchday"]
 
-	// Clear finished matches at the start of new season
-	mutex.Lock()
-	finishedMatches = make(map[int]*Match)
-	mutex.Unlock()
+	matchday, err := strconv.Atoi(matchdayStr)
+	if err != nil {
+		http.Error(w, "Invalid matchday", http.StatusBadRequest)
+		return
+	}
+
+	mutex.RLock()
+	allMatches := make(map[string][]*SeasonSchedule)
+
+	// Get matches for all leagues
+	for league := range leagueConfigs {
+		matches := getScheduledMatches(league, matchday)
+		if len(matches) > 0 {
+			allMatches[league] = matches
+		}
+	}
+	mutex.RUnlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"matchday":  matchday,
+		"matches":   allMatches,
+		"timestamp": time.Now(),
+	})
+}
+
+// Get all fixtures for all leagues - allows viewing the complete season schedule upfront
+func getAllFixtures(w http.ResponseWriter, r *http.Request) {
+	mutex.RLock()
+	defer mutex.RUnlock()
+
+	allFixtures := make(map[string][]*SeasonSchedule)
+	totalFixtures := 0
+
+	// Get all fixtures for each league
+	for league, schedules := range seasonSchedules {
+		allFixtures[league] = schedules
+		totalFixtures += len(schedules)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"fixtures":       allFixtures,
+		"total_fixtures": totalFixtures,
+		"current_season": currentSeason,
+		"timestamp":      time.Now(),
+	})
+}
+
+// Get all fixtures for a specific league
+func getLeagueFixtures(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	league := vars["league"]
+
+	mutex.RLock()
+	schedules, exists := seasonSchedules[league]
+	mutex.RUnlock()
+
+	if !exists {
+		http.Error(w, "League not found", http.StatusNotFound)
+		return
+	}
+
+	// Optional filtering
+	statusFilter := r.URL.Query().Get("status") // "played", "unplayed", "all"
+	matchdayStr := r.URL.Query().Get("matchday")
+
+	var filteredSchedules []*SeasonSchedule
+
+	for _, schedule := range schedules {
+		// Filter by status if requested
+		if statusFilter != "" && statusFilter != "all" {
+			if statusFilter == "played" && !schedule.IsPlayed {
+				continue
+			}
+			if statusFilter == "unplayed" && schedule.IsPlayed {
+				continue
+			}
+		}
 
-	// Reset other season-related data
-	// ... existing code ...
-}
+		// Filter by matchday if requested
+		if matchdayStr != "" {
+			matchday, err := strconv.Atoi(matchdayStr)
+			if err == nil && schedule.Matchday != matchday {
+				continue
+			}
+		}
 
-func batchUpdatePlayerStats() {
-	// Batch update player statistics to reduce lock contention
-	mutex.Lock()
-	defer mutex.Unlock()
+		filteredSchedules = append(filteredSchedules, schedule)
+	}
 
-	playersUpdated := 0
-	for _, player := range players {
-		if player.SeasonStats.MatchesPlayed > 0 {
-			player.SeasonStats.AverageRating = player.SeasonStats.TotalRating / float64(player.SeasonStats.MatchesPlayed)
-			playersUpdated++
+	// Count played and unplayed
+	playedCount := 0
+	unplayedCount := 0
+	for _, schedule := range schedules {
+		if schedule.IsPlayed {
+			playedCount++
+		} else {
+			unplayedCount++
 		}
 	}
 
-	if playersUpdated > 0 {
-		log.Printf("📈 Updated stats for %d players", playersUpdated)
-	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"league":         league,
+		"fixtures":       filteredSchedules,
+		"total_fixtures": len(schedules),
+		"played_count":   playedCount,
+		"unplayed_count": unplayedCount,
+		"filtered_count": len(filteredSchedules),
+		"current_season": currentSeason,
+		"timestamp":      time.Now(),
+	})
 }
 
-func batchUpdateTeamStats() {
-	// Update team statistics in batches
-	updateGlobalStats()
+// Fantasy league - squads scored from live match events
+func fantasyPositionGroup(position string) string {
+	switch position {
+	case PosGK:
+		return "GK"
+	case PosCB, PosLB, PosRB:
+		return "DEF"
+	case PosCDM, PosCM, PosCAM:
+		return "MID"
+	default:
+		return "FWD"
+	}
 }
 
-func endSeason() {
-	log.Printf("🏆 Season %d ended! Calculating final standings...", currentSeason)
-
-	// Calculate season winners and stats
-	seasonWinner := calculateSeasonWinner()
-	topScorer := findTopScorer()
-	topAssists := findTopAssists()
-	mostFouls := findMostFouls()
-	playerOfSeason := findPlayerOfSeason()
-
-	// Store season history
-	seasonRecord := SeasonHistory{
-		Season:         currentSeason,
-		TopScorer:      *topScorer,
-		TopAssists:     *topAssists,
-		MostFouls:      *mostFouls,
-		PlayerOfSeason: *playerOfSeason,
-		Champion:       *seasonWinner,
-		TotalGoals:     calculateTotalSeasonGoals(),
-		TotalMatches:   SeasonMatches,
-		EndDate:        time.Now(),
+// Validate a squad against the budget, formation and club limits and return its cost
+func validateFantasySquad(playerIDs []int) (int, error) {
+	if len(playerIDs) != FantasySquadSize {
+		return 0, fmt.Errorf("squad must contain exactly %d players", FantasySquadSize)
 	}
 
-	seasonHistory = append(seasonHistory, seasonRecord)
-	if len(seasonHistory) > MaxSeasonHistory {
-		seasonHistory = seasonHistory[1:]
+	cost := 0
+	seen := make(map[int]bool)
+	groups := make(map[string]int)
+	clubs := make(map[int]int)
+	for _, playerID := range playerIDs {
+		player := players[playerID]
+		if player == nil {
+			return 0, fmt.Errorf("player %d not found", playerID)
+		}
+		if seen[playerID] {
+			return 0, fmt.Errorf("player %d selected more than once", playerID)
+		}
+		seen[playerID] = true
+		cost += player.MarketValue
+		groups[fantasyPositionGroup(player.Position)]++
+		clubs[player.TeamID]++
+		if clubs[player.TeamID] > FantasyMaxPerClub {
+			return 0, fmt.Errorf("maximum %d players allowed from %s", FantasyMaxPerClub, getTeamName(player.TeamID))
+		}
 	}
 
-	log.Printf("🥇 Season %d Champions: %s", currentSeason, seasonWinner.Name)
-	log.Printf("⚽ Top Scorer: %s (%d goals)", topScorer.Name, topScorer.SeasonStats.GoalsThisSeason)
-	log.Printf("🅰️  Top Assists: %s (%d assists)", topAssists.Name, topAssists.SeasonStats.AssistsThisSeason)
-
-	// Reset for new season
-	resetForNewSeason()
+	if groups["GK"] != 1 || groups["DEF"] < 3 || groups["DEF"] > 5 ||
+		groups["MID"] < 2 || groups["MID"] > 5 || groups["FWD"] < 1 || groups["FWD"] > 3 {
+		return 0, fmt.Errorf("invalid formation: need 1 GK, 3-5 DEF, 2-5 MID and 1-3 FWD (got %d/%d/%d/%d)",
+			groups["GK"], groups["DEF"], groups["MID"], groups["FWD"])
+	}
+	if cost > FantasyBudget {
+		return 0, fmt.Errorf("squad costs %d, budget is %d", cost, FantasyBudget)
+	}
 
-	currentSeason++
-	currentMatchweek = 1
+	return cost, nil
 }
 
-func calculateSeasonWinner() *TeamInfo {
-	// Get the team at the top of Premier League table (or default to first team)
-	if table, exists := leagueTables[LeaguePremier]; exists && len(table) > 0 {
-		return &table[0].Team
+// Players are locked while their club is on the pitch
+func isTeamPlaying(teamID int) bool {
+	for _, match := range matches {
+		if (match.Status == StatusLive || match.Status == StatusHalftime || match.Status == StatusVARCheck) &&
+			(match.HomeTeam.ID == teamID || match.AwayTeam.ID == teamID) {
+			return true
+		}
 	}
+	return false
+}
 
-	// Fallback to any team
-	for _, team := range teams {
-		return team
+func recordFantasyEvent(matchID int, player *Player, eventType string) {
+	if player == nil {
+		return
 	}
-
-	return &TeamInfo{Name: "Unknown"}
+	if fantasyMatchPoints[matchID] == nil {
+		fantasyMatchPoints[matchID] = make(map[int]int)
+	}
+	fantasyMatchPoints[matchID][player.ID] += fantasyEventPoints(player, eventType)
 }
 
-func findTopScorer() *Player {
-	var topScorer *Player
-	maxGoals := 0
-
-	for _, player := range players {
-		if player.SeasonStats.GoalsThisSeason > maxGoals {
-			maxGoals = player.SeasonStats.GoalsThisSeason
-			topScorer = player
-		}
+// undoFantasyEvent takes back the points of an event cancelled by VAR
+func undoFantasyEvent(matchID int, player *Player, eventType string) {
+	if player == nil || fantasyMatchPoints[matchID] == nil {
+		return
 	}
+	fantasyMatchPoints[matchID][player.ID] -= fantasyEventPoints(player, eventType)
+}
 
-	if topScorer == nil {
-		for _, player := range players {
-			return player
+func fantasyEventPoints(player *Player, eventType string) int {
+	points := 0
+	switch eventType {
+	case "goal":
+		switch fantasyPositionGroup(player.Position) {
+		case "GK", "DEF":
+			points = 6
+		case "MID":
+			points = 5
+		default:
+			points = 4
 		}
+	case "assist":
+		points = 3
+	case "yellow":
+		points = -1
+	case "red":
+		points = -3
 	}
-
-	return topScorer
+	return points
 }
 
-func findTopAssists() *Player {
-	var topAssists *Player
-	maxAssists := 0
-
-	for _, player := range players {
-		if player.SeasonStats.AssistsThisSeason > maxAssists {
-			maxAssists = player.SeasonStats.AssistsThisSeason
-			topAssists = player
-		}
+// Add appearance, clean sheet and rating points, then credit every fantasy squad
+func settleFantasyMatch(matchID int, match *Match) {
+	matchPoints := fantasyMatchPoints[matchID]
+	if matchPoints == nil {
+		matchPoints = make(map[int]int)
 	}
 
-	if topAssists == nil {
-		for _, player := range players {
-			return player
+	for playerID := range matchPlayerStats[matchID] {
+		player := players[playerID]
+		if player == nil {
+			continue
 		}
-	}
 
-	return topAssists
-}
+		conceded := match.AwayScore
+		if player.TeamID == match.AwayTeam.ID {
+			conceded = match.HomeScore
+		}
 
-func findMostFouls() *Player {
-	var mostFouls *Player
-	maxFouls := 0
+		points := 2 // Appearance
+		group := fantasyPositionGroup(player.Position)
+		if conceded == 0 {
+			switch group {
+			case "GK", "DEF":
+				points += 4
+			case "MID":
+				points++
+			}
+		} else if group == "GK" || group == "DEF" {
+			points -= conceded / 2
+		}
 
-	for _, player := range players {
-		totalFouls := player.SeasonStats.YellowCardsThisSeason + player.SeasonStats.RedCardsThisSeason*2
-		if totalFouls > maxFouls {
-			maxFouls = totalFouls
-			mostFouls = player
+		rating := match.PlayerRatings[playerID]
+		if rating >= 8.0 {
+			points += 3
+		} else if rating >= 7.5 {
+			points += 2
+		} else if rating >= 7.0 {
+			points++
 		}
+
+		matchPoints[playerID] += points
 	}
 
-	if mostFouls == nil {
-		for _, player := range players {
-			return player
+	for _, team := range fantasyTeams {
+		gameweek := getFantasyGameweek(team, match.Season, match.MatchweekNum)
+		for _, playerID := range team.PlayerIDs {
+			points, played := matchPoints[playerID]
+			if !played {
+				continue
+			}
+			if playerID == gameweek.CaptainID {
+				points *= 2
+			}
+			gameweek.PlayerPoints[playerID] += points
+			gameweek.Points += points
+			team.TotalPoints += points
+			team.LastUpdate = time.Now()
 		}
 	}
 
-	return mostFouls
+	delete(fantasyMatchPoints, matchID)
 }
 
-func findPlayerOfSeason() *Player {
-	var playerOfSeason *Player
-	maxRating := 0.0
-
-	for _, player := range players {
-		if player.SeasonStats.AverageRating > maxRating && player.SeasonStats.MatchesPlayed >= 10 {
-			maxRating = player.SeasonStats.AverageRating
-			playerOfSeason = player
+func getFantasyGameweek(team *FantasyTeam, season, gameweek int) *FantasyGameweek {
+	for _, gw := range team.Gameweeks {
+		if gw.Season == season && gw.Gameweek == gameweek {
+			return gw
 		}
 	}
 
-	if playerOfSeason == nil {
-		for _, player := range players {
-			return player
-		}
+	gw := &FantasyGameweek{
+		Season:       season,
+		Gameweek:     gameweek,
+		CaptainID:    team.CaptainID,
+		PlayerPoints: make(map[int]int),
 	}
+	team.Gameweeks = append(team.Gameweeks, gw)
+	return gw
+}
 
-	return playerOfSeason
+// changeFantasyCaptain sets a squad's captain. The current gameweek follows unless its
+// captain has already been credited for a match in it
+func changeFantasyCaptain(team *FantasyTeam, captainID int) {
+	team.CaptainID = captainID
+	gameweek := getFantasyGameweek(team, currentSeason, currentMatchweek)
+	if _, settled := gameweek.PlayerPoints[gameweek.CaptainID]; !settled {
+		gameweek.CaptainID = captainID
+	}
 }
 
-func calculateTotalSeasonGoals() int {
-	total := 0
-	for _, player := range players {
-		total += player.SeasonStats.GoalsThisSeason
+// Free transfers are granted once per gameweek
+func refreshFantasyTransfers(team *FantasyTeam) {
+	window := fmt.Sprintf("%d-%d", currentSeason, currentMatchweek)
+	if team.TransferWindow != window {
+		team.TransferWindow = window
+		team.FreeTransfers = FantasyFreeTransfers
 	}
-	return total
 }
 
-func resetForNewSeason() {
-	log.Printf("🔄 Resetting for season %d...", currentSeason+1)
+func getFantasyTeamFromRequest(w http.ResponseWriter, r *http.Request) *FantasyTeam {
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		http.Error(w, "Invalid fantasy team ID", http.StatusBadRequest)
+		return nil
+	}
 
-	// Reset season stats for all players
-	playersReset := 0
-	for _, player := range players {
-		// Move season stats to cumulative stats
-		player.Goals += player.SeasonStats.GoalsThisSeason
-		player.Assists += player.SeasonStats.AssistsThisSeason
-		player.YellowCards += player.SeasonStats.YellowCardsThisSeason
-		player.RedCards += player.SeasonStats.RedCardsThisSeason
+	team := fantasyTeams[id]
+	if team == nil {
+		http.Error(w, "Fantasy team not found", http.StatusNotFound)
+		return nil
+	}
+	return team
+}
 
-		// Reset season stats
-		player.SeasonStats = PlayerSeasonStats{}
-		player.CurrentRating = 6.0
-		playersReset++
+func createFantasyTeam(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		Name      string `json:"name"`
+		Manager   string `json:"manager"`
+		PlayerIDs []int  `json:"player_ids"`
+		CaptainID int    `json:"captain_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
 	}
 
-	// Reset league tables
-	initializeLeagueTables()
+	request.Name = strings.TrimSpace(request.Name)
+	if request.Name == "" {
+		http.Error(w, "Team name required", http.StatusBadRequest)
+		return
+	}
 
-	log.Printf("📊 Reset complete: %d players reset, league tables reinitialized", playersReset)
-}
+	lockMutex("fantasy_create")
+	defer mutex.Unlock()
 
-func updateTeamStats(teamID, points, wins, draws, losses int, match *Match) {
-	team := teams[teamID]
-	if team == nil {
+	if len(fantasyTeams) >= MaxFantasyTeams {
+		http.Error(w, "Fantasy league is full", http.StatusServiceUnavailable)
 		return
 	}
 
-	// Determine result for form tracking
-	result := FormLoss
-	if wins > 0 {
-		result = FormWin
-	} else if draws > 0 {
-		result = FormDraw
+	cost, err := validateFantasySquad(request.PlayerIDs)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
 	}
 
-	// Properly determine if it's a home game
-	isHome := match.HomeTeam.ID == teamID
+	captainValid := false
+	for _, playerID := range request.PlayerIDs {
+		if playerID == request.CaptainID {
+			captainValid = true
+		}
+	}
+	if !captainValid {
+		http.Error(w, "Captain must be in the squad", http.StatusBadRequest)
+		return
+	}
 
-	// Update team form with correct home/away status
-	updateTeamForm(team, result, isHome)
+	fantasyTeamCounter++
+	team := &FantasyTeam{
+		ID:         fantasyTeamCounter,
+		Name:       request.Name,
+		Manager:    request.Manager,
+		PlayerIDs:  append([]int{}, request.PlayerIDs...),
+		CaptainID:  request.CaptainID,
+		SquadValue: cost,
+		Bank:       FantasyBudget - cost,
+		Transfers:  []*FantasyTransfer{},
+		Gameweeks:  []*FantasyGameweek{},
+		CreatedAt:  time.Now(),
+		LastUpdate: time.Now(),
+	}
+	refreshFantasyTransfers(team)
+	fantasyTeams[team.ID] = team
 
-	// Update team statistics in league table
-	for league, table := range leagueTables {
-		for _, teamEntry := range table {
-			if teamEntry.Team.ID == teamID {
-				teamEntry.Points += points
-				teamEntry.Won += wins
-				teamEntry.Drawn += draws
-				teamEntry.Lost += losses
-				teamEntry.Played = teamEntry.Won + teamEntry.Drawn + teamEntry.Lost
+	logInfo("🎮 Fantasy team registered: %s (ID: %d, value: %d)", team.Name, team.ID, cost)
 
-				// Update goals for and against based on match result
-				if isHome {
-					teamEntry.GoalsFor += match.HomeScore
-					teamEntry.GoalsAgainst += match.AwayScore
-				} else {
-					teamEntry.GoalsFor += match.AwayScore
-					teamEntry.GoalsAgainst += match.HomeScore
-				}
-				teamEntry.GoalDiff = teamEntry.GoalsFor - teamEntry.GoalsAgainst
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(team)
+}
 
-				// Update the team reference with form data
-				teamEntry.Team = *team
+func getFantasyTeam(w http.ResponseWriter, r *http.Request) {
+	mutex.RLock()
+	defer mutex.RUnlock()
 
-				teamEntry.LastUpdate = time.Now()
+	team := getFantasyTeamFromRequest(w, r)
+	if team == nil {
+		return
+	}
 
-				log.Printf("📋 %s: %d points (%d played, %d won, %d drawn, %d lost, %d GF, %d GA, %d GD) - Form: %v",
-					teamEntry.Team.ShortName, teamEntry.Points, teamEntry.Played,
-					teamEntry.Won, teamEntry.Drawn, teamEntry.Lost, teamEntry.GoalsFor,
-					teamEntry.GoalsAgainst, teamEntry.GoalDiff, team.Form)
-				break
-			}
+	squad := make([]*Player, 0, len(team.PlayerIDs))
+	for _, playerID := range team.PlayerIDs {
+		if player := players[playerID]; player != nil {
+			squad = append(squad, player)
 		}
-
-		// Resort league table by points
-		sortLeagueTable(leagueTables[league])
 	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"team":      team,
+		"squad":     squad,
+		"timestamp": time.Now(),
+	})
 }
 
-func sortLeagueTable(table []*LeagueTable) {
-	// Simple bubble sort by points, then goal difference
-	for i := 0; i < len(table)-1; i++ {
-		for j := 0; j < len(table)-i-1; j++ {
-			if table[j].Points < table[j+1].Points ||
-				(table[j].Points == table[j+1].Points && table[j].GoalDiff < table[j+1].GoalDiff) {
-				table[j], table[j+1] = table[j+1], table[j]
-			}
-		}
+func makeFantasyTransfer(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		PlayerOut int `json:"player_out"`
+		PlayerIn  int `json:"player_in"`
+		CaptainID int `json:"captain_id,omitempty"` // Required when transferring out the captain
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
 	}
 
-	// Update positions
-	for i, team := range table {
-		team.Position = i + 1
+	lockMutex("fantasy_transfer")
+	defer mutex.Unlock()
+
+	team := getFantasyTeamFromRequest(w, r)
+	if team == nil {
+		return
 	}
-}
 
-func getSeasonSchedule(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	league := vars["league"]
+	outIndex := -1
+	for i, playerID := range team.PlayerIDs {
+		if playerID == request.PlayerOut {
+			outIndex = i
+		}
+	}
+	if outIndex == -1 {
+		http.Error(w, "Outgoing player is not in the squad", http.StatusBadRequest)
+		return
+	}
 
-	mutex.RLock()
-	schedules, exists := seasonSchedules[league]
-	if !exists {
-		mutex.RUnlock()
-		http.Error(w, "League not found", http.StatusNotFound)
+	playerOut := players[request.PlayerOut]
+	playerIn := players[request.PlayerIn]
+	if playerIn == nil {
+		http.Error(w, "Incoming player not found", http.StatusBadRequest)
+		return
+	}
+	if (playerOut != nil && isTeamPlaying(playerOut.TeamID)) || isTeamPlaying(playerIn.TeamID) {
+		http.Error(w, "Transfers are locked while a player's match is in progress", http.StatusConflict)
 		return
 	}
 
-	// Filter by matchday if specified
-	matchdayStr := r.URL.Query().Get("matchday")
-	if matchdayStr != "" {
-		matchday, err := strconv.Atoi(matchdayStr)
-		if err == nil {
-			filteredSchedules := getScheduledMatches(league, matchday)
-			mutex.RUnlock()
+	newSquad := append([]int{}, team.PlayerIDs...)
+	newSquad[outIndex] = request.PlayerIn
+	cost, err := validateFantasySquad(newSquad)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 
-			w.Header().Set("Content-Type", "application/json")
-			json.NewEncoder(w).Encode(map[string]interface{}{
-				"league":    league,
-				"matchday":  matchday,
-				"schedules": filteredSchedules,
-				"count":     len(filteredSchedules),
-				"timestamp": time.Now(),
-			})
+	captainID := team.CaptainID
+	if request.PlayerOut == team.CaptainID {
+		if request.CaptainID == 0 || request.CaptainID == request.PlayerOut {
+			http.Error(w, "A new captain is required when transferring out the captain", http.StatusBadRequest)
 			return
 		}
+		captainID = request.CaptainID
+	}
+	captainValid := false
+	for _, playerID := range newSquad {
+		if playerID == captainID {
+			captainValid = true
+		}
+	}
+	if !captainValid {
+		http.Error(w, "Captain must be in the squad", http.StatusBadRequest)
+		return
 	}
 
-	// Return all schedules for the league
-	mutex.RUnlock()
+	// Extra transfers cost points in the current gameweek
+	refreshFantasyTransfers(team)
+	pointsCost := 0
+	if team.FreeTransfers > 0 {
+		team.FreeTransfers--
+	} else {
+		pointsCost = FantasyTransferCost
+		gameweek := getFantasyGameweek(team, currentSeason, currentMatchweek)
+		gameweek.TransferCost += pointsCost
+		gameweek.Points -= pointsCost
+		team.TotalPoints -= pointsCost
+	}
+
+	transfer := &FantasyTransfer{
+		PlayerOut: request.PlayerOut,
+		PlayerIn:  request.PlayerIn,
+		Season:    currentSeason,
+		Gameweek:  currentMatchweek,
+		Cost:      pointsCost,
+		Timestamp: time.Now(),
+	}
+	team.PlayerIDs = newSquad
+	changeFantasyCaptain(team, captainID)
+	team.SquadValue = cost
+	team.Bank = FantasyBudget - cost
+	team.Transfers = append(team.Transfers, transfer)
+	team.LastUpdate = time.Now()
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]interface{}{
-		"league":    league,
-		"schedules": schedules,
-		"count":     len(schedules),
+		"team":      team,
+		"transfer":  transfer,
 		"timestamp": time.Now(),
 	})
 }
 
-func getTeamForm(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	id, err := strconv.Atoi(vars["id"])
-	if err != nil {
-		http.Error(w, "Invalid team ID", http.StatusBadRequest)
+func setFantasyCaptain(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		CaptainID int `json:"captain_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
 		return
 	}
 
+	lockMutex("fantasy_captain")
+	defer mutex.Unlock()
+
+	team := getFantasyTeamFromRequest(w, r)
+	if team == nil {
+		return
+	}
+
+	captainValid := false
+	for _, playerID := range team.PlayerIDs {
+		if playerID == request.CaptainID {
+			captainValid = true
+		}
+	}
+	if !captainValid {
+		http.Error(w, "Captain must be in the squad", http.StatusBadRequest)
+		return
+	}
+	previous, captain := players[team.CaptainID], players[request.CaptainID]
+	if (previous != nil && isTeamPlaying(previous.TeamID)) || (captain != nil && isTeamPlaying(captain.TeamID)) {
+		http.Error(w, "The captain cannot be changed while either player's match is in progress", http.StatusConflict)
+		return
+	}
+
+	changeFantasyCaptain(team, request.CaptainID)
+	team.LastUpdate = time.Now()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(team)
+}
+
+func getFantasyGameweeks(w http.ResponseWriter, r *http.Request) {
 	mutex.RLock()
-	team, exists := teams[id]
-	mutex.RUnlock()
+	defer mutex.RUnlock()
 
-	if !exists {
-		http.Error(w, "Team not found", http.StatusNotFound)
+	team := getFantasyTeamFromRequest(w, r)
+	if team == nil {
 		return
 	}
 
-	formData := map[string]interface{}{
-		"team_id":     team.ID,
-		"team_name":   team.Name,
-		"short_name":  team.ShortName,
-		"league":      team.League,
-		"form":        team.Form,
-		"form_points": team.FormPoints,
-		"timestamp":   time.Now(),
+	gameweeks := make([]*FantasyGameweek, 0, len(team.Gameweeks))
+	gameweekStr := r.URL.Query().Get("gameweek")
+	for _, gw := range team.Gameweeks {
+		if gameweekStr != "" {
+			gameweek, err := strconv.Atoi(gameweekStr)
+			if err == nil && (gw.Gameweek != gameweek || gw.Season != currentSeason) {
+				continue
+			}
+		}
+		gameweeks = append(gameweeks, gw)
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(formData)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"team_id":      team.ID,
+		"total_points": team.TotalPoints,
+		"gameweeks":    gameweeks,
+		"count":        len(gameweeks),
+		"timestamp":    time.Now(),
+	})
 }
 
-func getLeagueForm(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	league := vars["league"]
+func getFantasyLeaderboard(w http.ResponseWriter, r *http.Request) {
+	gameweekStr := r.URL.Query().Get("gameweek")
+	gameweek, _ := strconv.Atoi(gameweekStr)
+	limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
+	if err != nil || limit < 1 {
+		limit = 50
+	}
+	limit = min(limit, 100)
 
 	mutex.RLock()
-	var leagueTeams []*TeamInfo
-	for _, team := range teams {
-		if team.League == league {
-			leagueTeams = append(leagueTeams, team)
+	type leaderboardEntry struct {
+		Rank    int    `json:"rank"`
+		TeamID  int    `json:"team_id"`
+		Name    string `json:"name"`
+		Manager string `json:"manager"`
+		Points  int    `json:"points"`
+	}
+	entries := make([]*leaderboardEntry, 0, len(fantasyTeams))
+	for _, team := range fantasyTeams {
+		points := team.TotalPoints
+		if gameweek > 0 {
+			points = 0
+			for _, gw := range team.Gameweeks {
+				if gw.Season == currentSeason && gw.Gameweek == gameweek {
+					points = gw.Points
+				}
+			}
 		}
+		entries = append(entries, &leaderboardEntry{
+			TeamID:  team.ID,
+			Name:    team.Name,
+			Manager: team.Manager,
+			Points:  points,
+		})
 	}
 	mutex.RUnlock()
 
-	if len(leagueTeams) == 0 {
-		http.Error(w, "League not found", http.StatusNotFound)
-		return
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Points != entries[j].Points {
+			return entries[i].Points > entries[j].Points
+		}
+		return entries[i].TeamID < entries[j].TeamID
+	})
+	for i, entry := range entries {
+		entry.Rank = i + 1
 	}
+	total := len(entries)
+	entries = entries[:min(limit, total)]
 
-	// Sort teams by form points (descending)
-	for i := 0; i < len(leagueTeams)-1; i++ {
-		for j := 0; j < len(leagueTeams)-i-1; j++ {
-			if leagueTeams[j].FormPoints < leagueTeams[j+1].FormPoints {
-				leagueTeams[j], leagueTeams[j+1] = leagueTeams[j+1], leagueTeams[j]
+	response := map[string]interface{}{
+		"leaderboard": entries,
+		"count":       len(entries),
+		"total_teams": total,
+		"timestamp":   time.Now(),
+	}
+	if gameweek > 0 {
+		response["gameweek"] = gameweek
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// Prediction game - score predictions locked at kickoff
+func findFixture(fixtureID int) *SeasonSchedule {
+	for _, schedules := range seasonSchedules {
+		for _, schedule := range schedules {
+			if schedule.ID == fixtureID {
+				return schedule
 			}
 		}
 	}
+	return nil
+}
 
-	formTable := make([]map[string]interface{}, len(leagueTeams))
-	for i, team := range leagueTeams {
-		formTable[i] = map[string]interface{}{
-			"position":    i + 1,
-			"team_id":     team.ID,
-			"team_name":   team.Name,
-			"short_name":  team.ShortName,
-			"form":        team.Form,
-			"form_points": team.FormPoints,
+func lockPredictions(schedule *SeasonSchedule, matchID int) {
+	for _, prediction := range predictions[schedule.ID] {
+		prediction.Status = PredictionLocked
+		prediction.MatchID = matchID
+	}
+	if count := len(predictions[schedule.ID]); count > 0 {
+		logInfo("🔒 Locked %d predictions for %s vs %s", count, schedule.HomeTeam.ShortName, schedule.AwayTeam.ShortName)
+	}
+}
+
+func scorePredictions(match *Match) {
+	var fixture *SeasonSchedule
+	for _, schedule := range seasonSchedules[match.Competition] {
+		if schedule.MatchID == match.ID {
+			fixture = schedule
+			break
 		}
 	}
+	if fixture == nil {
+		return
+	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"league":     league,
-		"form_table": formTable,
-		"count":      len(formTable),
-		"timestamp":  time.Now(),
-	})
+	now := time.Now()
+	actualResult := match.HomeScore - match.AwayScore
+	for _, prediction := range predictions[fixture.ID] {
+		predictedResult := prediction.HomeScore - prediction.AwayScore
+
+		switch {
+		case prediction.HomeScore == match.HomeScore && prediction.AwayScore == match.AwayScore:
+			prediction.Outcome = "EXACT"
+			prediction.Points = PredictionExactPoints
+		case (actualResult > 0 && predictedResult > 0) || (actualResult < 0 && predictedResult < 0) ||
+			(actualResult == 0 && predictedResult == 0):
+			prediction.Outcome = "RESULT"
+			prediction.Points = PredictionResultPoints
+		default:
+			prediction.Outcome = "MISS"
+			prediction.Points = 0
+		}
+
+		prediction.Status = PredictionScored
+		prediction.ScoredAt = &now
+	}
 }
 
-func getMatchdaySchedule(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	matchdayStr := vars["matchday"]
+func submitPrediction(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		User      string `json:"user"`
+		FixtureID int    `json:"fixture_id"`
+		HomeScore int    `json:"home_score"`
+		AwayScore int    `json:"away_score"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
 
-	matchday, err := strconv.Atoi(matchdayStr)
-	if err != nil {
-		http.Error(w, "Invalid matchday", http.StatusBadRequest)
+	request.User = strings.TrimSpace(request.User)
+	if request.User == "" || len(request.User) > 32 {
+		http.Error(w, "User name required (max 32 characters)", http.StatusBadRequest)
+		return
+	}
+	if request.HomeScore < 0 || request.AwayScore < 0 ||
+		request.HomeScore > MaxPredictionScore || request.AwayScore > MaxPredictionScore {
+		http.Error(w, fmt.Sprintf("Scores must be between 0 and %d", MaxPredictionScore), http.StatusBadRequest)
 		return
 	}
 
-	mutex.RLock()
-	allMatches := make(map[string][]*SeasonSchedule)
+	lockMutex("prediction_submit")
+	defer mutex.Unlock()
 
-	// Get matches for all leagues
-	for league := range leagueConfigs {
-		matches := getScheduledMatches(league, matchday)
-		if len(matches) > 0 {
-			allMatches[league] = matches
+	fixture := findFixture(request.FixtureID)
+	if fixture == nil {
+		http.Error(w, "Fixture not found", http.StatusNotFound)
+		return
+	}
+	if fixture.IsPlayed {
+		http.Error(w, "Predictions are locked for this fixture", http.StatusConflict)
+		return
+	}
+
+	if predictions[fixture.ID] == nil {
+		predictions[fixture.ID] = make(map[string]*Prediction)
+	}
+
+	status := http.StatusOK
+	prediction := predictions[fixture.ID][request.User]
+	if prediction == nil {
+		predictionCounter++
+		prediction = &Prediction{
+			ID:          predictionCounter,
+			User:        request.User,
+			FixtureID:   fixture.ID,
+			Season:      currentSeason,
+			Matchday:    fixture.Matchday,
+			League:      fixture.League,
+			HomeTeam:    fixture.HomeTeam.Name,
+			AwayTeam:    fixture.AwayTeam.Name,
+			Status:      PredictionOpen,
+			SubmittedAt: time.Now(),
+		}
+		predictions[fixture.ID][request.User] = prediction
+		status = http.StatusCreated
+	}
+	prediction.HomeScore = request.HomeScore
+	prediction.AwayScore = request.AwayScore
+	prediction.UpdatedAt = time.Now()
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(prediction)
+}
+
+func getPredictions(w http.ResponseWriter, r *http.Request) {
+	user := r.URL.Query().Get("user")
+	status := r.URL.Query().Get("status")
+	fixtureID, _ := strconv.Atoi(r.URL.Query().Get("fixture_id"))
+	matchday, _ := strconv.Atoi(r.URL.Query().Get("matchday"))
+
+	mutex.RLock()
+	predictionList := []*Prediction{}
+	for id, fixturePredictions := range predictions {
+		if fixtureID > 0 && id != fixtureID {
+			continue
+		}
+		for _, prediction := range fixturePredictions {
+			if user != "" && prediction.User != user {
+				continue
+			}
+			if status != "" && prediction.Status != strings.ToUpper(status) {
+				continue
+			}
+			if matchday > 0 && prediction.Matchday != matchday {
+				continue
+			}
+			predictionList = append(predictionList, prediction)
 		}
 	}
 	mutex.RUnlock()
 
+	sort.Slice(predictionList, func(i, j int) bool {
+		return predictionList[i].ID < predictionList[j].ID
+	})
+
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]interface{}{
-		"matchday":  matchday,
-		"matches":   allMatches,
-		"timestamp": time.Now(),
+		"predictions": predictionList,
+		"count":       len(predictionList),
+		"timestamp":   time.Now(),
 	})
 }
 
-// Get all fixtures for all leagues - allows viewing the complete season schedule upfront
-func getAllFixtures(w http.ResponseWriter, r *http.Request) {
-	mutex.RLock()
-	defer mutex.RUnlock()
+type predictionStanding struct {
+	Rank        int    `json:"rank"`
+	User        string `json:"user"`
+	Points      int    `json:"points"`
+	Exact       int    `json:"exact_scores"`
+	Results     int    `json:"correct_results"`
+	Predictions int    `json:"predictions"`
+}
 
-	allFixtures := make(map[string][]*SeasonSchedule)
-	totalFixtures := 0
+// Aggregate scored predictions per user, optionally for one season/matchday
+func buildPredictionStandings(season, matchday int) []*predictionStanding {
+	byUser := make(map[string]*predictionStanding)
+	for _, fixturePredictions := range predictions {
+		for _, prediction := range fixturePredictions {
+			if prediction.Status != PredictionScored {
+				continue
+			}
+			if (season > 0 && prediction.Season != season) || (matchday > 0 && prediction.Matchday != matchday) {
+				continue
+			}
 
-	// Get all fixtures for each league
-	for league, schedules := range seasonSchedules {
-		allFixtures[league] = schedules
-		totalFixtures += len(schedules)
+			standing := byUser[prediction.User]
+			if standing == nil {
+				standing = &predictionStanding{User: prediction.User}
+				byUser[prediction.User] = standing
+			}
+			standing.Points += prediction.Points
+			standing.Predictions++
+			switch prediction.Outcome {
+			case "EXACT":
+				standing.Exact++
+			case "RESULT":
+				standing.Results++
+			}
+		}
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"fixtures":       allFixtures,
-		"total_fixtures": totalFixtures,
-		"current_season": currentSeason,
-		"timestamp":      time.Now(),
+	standings := make([]*predictionStanding, 0, len(byUser))
+	for _, standing := range byUser {
+		standings = append(standings, standing)
+	}
+	sort.Slice(standings, func(i, j int) bool {
+		if standings[i].Points != standings[j].Points {
+			return standings[i].Points > standings[j].Points
+		}
+		if standings[i].Exact != standings[j].Exact {
+			return standings[i].Exact > standings[j].Exact
+		}
+		return standings[i].User < standings[j].User
 	})
+	for i, standing := range standings {
+		standing.Rank = i + 1
+	}
+	return standings
 }
 
-// Get all fixtures for a specific league
-func getLeagueFixtures(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	league := vars["league"]
+func getPredictionLeaderboard(w http.ResponseWriter, r *http.Request) {
+	matchday, _ := strconv.Atoi(r.URL.Query().Get("matchday"))
+	limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
+	if err != nil || limit < 1 {
+		limit = 50
+	}
+	limit = min(limit, 100)
 
 	mutex.RLock()
-	schedules, exists := seasonSchedules[league]
+	season := currentSeason
+	if seasonStr := r.URL.Query().Get("season"); seasonStr != "" {
+		if parsed, err := strconv.Atoi(seasonStr); err == nil {
+			season = parsed
+		}
+	}
+	standings := buildPredictionStandings(season, matchday)
 	mutex.RUnlock()
 
-	if !exists {
-		http.Error(w, "League not found", http.StatusNotFound)
-		return
+	total := len(standings)
+	standings = standings[:min(limit, total)]
+
+	response := map[string]interface{}{
+		"season":      season,
+		"leaderboard": standings,
+		"count":       len(standings),
+		"total_users": total,
+		"timestamp":   time.Now(),
+	}
+	if matchday > 0 {
+		response["matchday"] = matchday
 	}
 
-	// Optional filtering
-	statusFilter := r.URL.Query().Get("status") // "played", "unplayed", "all"
-	matchdayStr := r.URL.Query().Get("matchday")
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
 
-	var filteredSchedules []*SeasonSchedule
+func getPredictionUser(w http.ResponseWriter, r *http.Request) {
+	user := mux.Vars(r)["user"]
 
-	for _, schedule := range schedules {
-		// Filter by status if requested
-		if statusFilter != "" && statusFilter != "all" {
-			if statusFilter == "played" && !schedule.IsPlayed {
-				continue
-			}
-			if statusFilter == "unplayed" && schedule.IsPlayed {
-				continue
-			}
+	mutex.RLock()
+	var seasonStanding *predictionStanding
+	for _, standing := range buildPredictionStandings(currentSeason, 0) {
+		if standing.User == user {
+			seasonStanding = standing
 		}
+	}
 
-		// Filter by matchday if requested
-		if matchdayStr != "" {
-			matchday, err := strconv.Atoi(matchdayStr)
-			if err == nil && schedule.Matchday != matchday {
-				continue
+	matchdayPoints := make(map[int]int)
+	userPredictions := []*Prediction{}
+	for _, fixturePredictions := range predictions {
+		if prediction := fixturePredictions[user]; prediction != nil {
+			userPredictions = append(userPredictions, prediction)
+			if prediction.Season == currentSeason && prediction.Status == PredictionScored {
+				matchdayPoints[prediction.Matchday] += prediction.Points
 			}
 		}
-
-		filteredSchedules = append(filteredSchedules, schedule)
 	}
+	mutex.RUnlock()
 
-	// Count played and unplayed
-	playedCount := 0
-	unplayedCount := 0
-	for _, schedule := range schedules {
-		if schedule.IsPlayed {
-			playedCount++
-		} else {
-			unplayedCount++
-		}
+	if len(userPredictions) == 0 {
+		http.Error(w, "User has no predictions", http.StatusNotFound)
+		return
 	}
 
+	sort.Slice(userPredictions, func(i, j int) bool {
+		return userPredictions[i].ID < userPredictions[j].ID
+	})
+
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]interface{}{
-		"league":         league,
-		"fixtures":       filteredSchedules,
-		"total_fixtures": len(schedules),
-		"played_count":   playedCount,
-		"unplayed_count": unplayedCount,
-		"filtered_count": len(filteredSchedules),
-		"current_season": currentSeason,
-		"timestamp":      time.Now(),
+		"user":            user,
+		"season_standing": seasonStanding,
+		"matchday_points": matchdayPoints,
+		"predictions":     userPredictions,
+		"count":           len(userPredictions),
+		"timestamp":       time.Now(),
 	})
 }
 
@@ -3375,6 +10779,7 @@ func setBallEvent(matchID int, eventType string, x, y float64, possessorID int)
 		ball.EventStarted = time.Now()
 		ball.Speed = 0
 		ball.Timestamp = time.Now()
+		ball.passerID, ball.passTargetID = 0, 0 // Any pass in flight is dead
 
 		// Reposition players for the event
 		repositionPlayersForEvent(matchID, eventType, x, y)
@@ -3408,8 +10813,8 @@ func repositionForKickoff(matchID int, match *Match) {
 	homePositions := getFormationPositions(match.HomeFormation, true)
 	awayPositions := getFormationPositions(match.AwayFormation, false)
 
-	homePlayers := getPlayersFromTeam(match.HomeTeam.ID)[:11]
-	awayPlayers := getPlayersFromTeam(match.AwayTeam.ID)[:11]
+	homePlayers := getMatchLineup(matchID, match.HomeTeam.ID)
+	awayPlayers := getMatchLineup(matchID, match.AwayTeam.ID)
 
 	// Position home team
 	for i, player := range homePlayers {
@@ -3451,7 +10856,7 @@ func repositionForCorner(matchID int, match *Match, ballX, ballY float64) {
 }
 
 func repositionTeamForAttackingCorner(matchID int, teamID int, ballX, ballY float64) {
-	players := getPlayersFromTeam(teamID)[:11]
+	players := getMatchLineup(matchID, teamID)
 	goalX := FieldWidth
 	if ballX < FieldWidth/2 {
 		goalX = 0
@@ -3497,7 +10902,7 @@ func repositionTeamForAttackingCorner(matchID int, teamID int, ballX, ballY floa
 }
 
 func repositionTeamForDefendingCorner(matchID int, teamID int, ballX, ballY float64) {
-	players := getPlayersFromTeam(teamID)[:11]
+	players := getMatchLineup(matchID, teamID)
 	goalX := 0.0
 	if ballX < FieldWidth/2 {
 		goalX = FieldWidth
@@ -3568,7 +10973,7 @@ func repositionForFreekick(matchID int, match *Match, ballX, ballY float64) {
 	}
 
 	// Position attacking team
-	attackingPlayers := getPlayersFromTeam(attackingTeamID)[:11]
+	attackingPlayers := getMatchLineup(matchID, attackingTeamID)
 	for i, player := range attackingPlayers {
 		var x, y float64
 
@@ -3597,7 +11002,7 @@ func repositionForFreekick(matchID int, match *Match, ballX, ballY float64) {
 	}
 
 	// Position defending team (wall + coverage)
-	defendingPlayers := getPlayersFromTeam(defendingTeamID)[:11]
+	defendingPlayers := getMatchLineup(matchID, defendingTeamID)
 	wallDistance := 9.15 // FIFA regulation 10 yards
 
 	for i, player := range defendingPlayers {
@@ -3637,7 +11042,7 @@ func repositionForFreekick(matchID int, match *Match, ballX, ballY float64) {
 
 func repositionForThrowIn(matchID int, match *Match, ballX, ballY float64) {
 	// Simple repositioning - players spread out along the line
-	allPlayers := append(getPlayersFromTeam(match.HomeTeam.ID)[:11], getPlayersFromTeam(match.AwayTeam.ID)[:11]...)
+	allPlayers := append(getMatchLineup(matchID, match.HomeTeam.ID), getMatchLineup(matchID, match.AwayTeam.ID)...)
 
 	for i, player := range allPlayers {
 		x := ballX - 10 + rand.Float64()*20
@@ -3654,7 +11059,7 @@ func repositionForThrowIn(matchID int, match *Match, ballX, ballY float64) {
 
 func repositionForPenalty(matchID int, match *Match, ballX, ballY float64) {
 	// Position all players outside penalty area except penalty taker and goalkeeper
-	allPlayers := append(getPlayersFromTeam(match.HomeTeam.ID)[:11], getPlayersFromTeam(match.AwayTeam.ID)[:11]...)
+	allPlayers := append(getMatchLineup(matchID, match.HomeTeam.ID), getMatchLineup(matchID, match.AwayTeam.ID)...)
 
 	for i, player := range allPlayers {
 		var x, y float64
@@ -3691,7 +11096,7 @@ func repositionForPenalty(matchID int, match *Match, ballX, ballY float64) {
 
 func repositionForGoalkick(matchID int, match *Match, ballX, ballY float64) {
 	// Players spread out to receive goal kick
-	allPlayers := append(getPlayersFromTeam(match.HomeTeam.ID)[:11], getPlayersFromTeam(match.AwayTeam.ID)[:11]...)
+	allPlayers := append(getMatchLineup(matchID, match.HomeTeam.ID), getMatchLineup(matchID, match.AwayTeam.ID)...)
 
 	for _, player := range allPlayers {
 		var x, y float64
@@ -3742,6 +11147,11 @@ func updateBallPhysics(matchID int, ball *BallPosition) {
 }
 
 func updateBallInPlay(matchID int, ball *BallPosition) {
+	match := matches[matchID]
+	if match == nil {
+		return
+	}
+
 	if ball.PossessorID > 0 {
 		// Ball follows player with possession
 		if location, exists := playerLocations[matchID][ball.PossessorID]; exists {
@@ -3755,15 +11165,20 @@ func updateBallInPlay(matchID int, ball *BallPosition) {
 		}
 	} else {
 		// Ball moves with physics when loose
-		ball.Speed *= 0.92 // Friction
+		// Friction, heavier on a wet pitch
+		ball.Speed *= 0.92 - 0.04*rainIntensity(match.Weather)
+		if ball.Speed > 5 {
+			// Wind pushes fast balls (shots, clearances, long passes) off line
+			ball.Direction += (rand.Float64() - 0.5) * windStrength(match) * 0.4
+		}
 		ball.X += math.Cos(ball.Direction) * ball.Speed
 		ball.Y += math.Sin(ball.Direction) * ball.Speed
 
 		// Check if any player can pick up the ball
 		if ball.Speed < 1.0 {
+			// LastTouchID still holds whoever played the ball last
 			nearestPlayer := findNearestPlayerToBall(matchID, ball)
 			if nearestPlayer != nil {
-				ball.LastTouchID = ball.PossessorID
 				ball.PossessorID = nearestPlayer.ID
 			}
 		}
@@ -3777,6 +11192,8 @@ func updateBallInPlay(matchID int, ball *BallPosition) {
 	checkBallOutOfBounds(matchID, ball)
 }
 
+// simulatePass plays the ball towards a random teammate and records the attempt. The ball
+// stays loose until someone picks it up; a teammate completes the pass
 func simulatePass(matchID int, ball *BallPosition) {
 	if ball.PossessorID == 0 {
 		return
@@ -3800,7 +11217,14 @@ func simulatePass(matchID int, ball *BallPosition) {
 		ball.Direction = math.Atan2(location.Y-ball.Y, location.X-ball.X)
 		ball.Speed = 8.0 + rand.Float64()*4.0
 		ball.LastTouchID = ball.PossessorID
+		ball.passerID = ball.PossessorID
+		ball.passTargetID = target.ID
 		ball.PossessorID = 0 // Ball is in the air
+
+		if line := matchPlayerLine(matchID, possessor); line != nil {
+			line.Passes++
+		}
+		recordPassAttempt(matchID, possessor, target)
 	}
 }
 
@@ -3875,16 +11299,33 @@ func handleKickoffBallEvent(matchID int, ball *BallPosition) {
 		// Find center midfielder to take kickoff
 		match := matches[matchID]
 		if match != nil {
-			teamPlayers := getPlayersFromTeam(match.HomeTeam.ID)
-			for _, player := range teamPlayers {
-				if player.Position == PosCM {
-					ball.PossessorID = player.ID
-					ball.EventType = BallEventPlay
-					break
-				}
+			teamPlayers := getMatchLineup(matchID, match.HomeTeam.ID)
+			if player := setPieceTaker(teamPlayers, PosCM); player != nil {
+				ball.PossessorID = player.ID
+				ball.EventType = BallEventPlay
+			}
+		}
+	}
+}
+
+// setPieceTaker prefers the listed positions in order, falling back to any outfielder on the pitch
+func setPieceTaker(lineup []*Player, positions ...string) *Player {
+	for _, position := range positions {
+		for _, player := range lineup {
+			if player.Position == position {
+				return player
 			}
 		}
 	}
+	for _, player := range lineup {
+		if player.Position != PosGK {
+			return player
+		}
+	}
+	if len(lineup) > 0 {
+		return lineup[0]
+	}
+	return nil
 }
 
 func handleFreekickBallEvent(matchID int, ball *BallPosition) {
@@ -3916,18 +11357,15 @@ func handleCornerBallEvent(matchID int, ball *BallPosition) {
 				teamID = match.AwayTeam.ID
 			}
 
-			teamPlayers := getPlayersFromTeam(teamID)
-			for _, player := range teamPlayers {
-				if player.Position == PosLW || player.Position == PosRW || player.Position == PosCM {
-					ball.PossessorID = player.ID
-					ball.EventType = BallEventPlay
+			teamPlayers := getMatchLineup(matchID, teamID)
+			if player := setPieceTaker(teamPlayers, PosLW, PosRW, PosCM); player != nil {
+				ball.PossessorID = player.ID
+				ball.EventType = BallEventPlay
 
-					// Aim toward goal area
-					goalY := FieldHeight / 2
-					ball.Direction = math.Atan2(goalY-ball.Y, (FieldWidth/2)-ball.X)
-					ball.Speed = 8.0 + rand.Float64()*4.0
-					break
-				}
+				// Aim toward goal area
+				goalY := FieldHeight / 2
+				ball.Direction = math.Atan2(goalY-ball.Y, (FieldWidth/2)-ball.X)
+				ball.Speed = 8.0 + rand.Float64()*4.0
 			}
 		}
 	}
@@ -3958,22 +11396,28 @@ func handlePenaltyBallEvent(matchID int, ball *BallPosition) {
 				teamID = match.AwayTeam.ID
 			}
 
-			teamPlayers := getPlayersFromTeam(teamID)
-			for _, player := range teamPlayers {
-				if player.Position == PosST {
-					ball.PossessorID = player.ID
-					ball.EventType = BallEventPlay
+			teamPlayers := getMatchLineup(matchID, teamID)
+			if player := setPieceTaker(teamPlayers, PosST); player != nil {
+				ball.PossessorID = player.ID
+				ball.EventType = BallEventPlay
 
-					// High chance of goal on penalty
-					if rand.Float64() < 0.8 {
-						// Simulate goal
-						handleGoalEvent(matchID, match)
-					} else {
-						// Miss - ball goes to keeper
-						ball.Direction = math.Atan2((FieldHeight/2)-ball.Y, 0-ball.X)
-						ball.Speed = 10.0
-					}
-					break
+				// High chance of goal on penalty, always credited to the taker
+				if rand.Float64() < 0.8 {
+					scoreGoal(matchID, match, player, ball).Detail = "penalty"
+					confirmGoal(matchID, match)
+				} else {
+					recordShot(matchID, match, player, false)
+					// Miss - ball goes to keeper
+					ball.Direction = math.Atan2((FieldHeight/2)-ball.Y, 0-ball.X)
+					ball.Speed = 10.0
+					recordTimelineEvent(match, &TimelineEvent{
+						Type:       EventPenalty,
+						TeamID:     teamID,
+						PlayerID:   player.ID,
+						PlayerName: player.Name,
+						Detail:     "missed",
+						Position:   ballPitchPosition(ball),
+					})
 				}
 			}
 		}
@@ -3994,17 +11438,14 @@ func handleGoalkickBallEvent(matchID int, ball *BallPosition) {
 				teamID = match.AwayTeam.ID
 			}
 
-			teamPlayers := getPlayersFromTeam(teamID)
-			for _, player := range teamPlayers {
-				if player.Position == PosGK {
-					ball.PossessorID = player.ID
-					ball.EventType = BallEventPlay
+			teamPlayers := getMatchLineup(matchID, teamID)
+			if player := setPieceTaker(teamPlayers, PosGK); player != nil {
+				ball.PossessorID = player.ID
+				ball.EventType = BallEventPlay
 
-					// Long kick upfield
-					ball.Direction = math.Atan2(0, FieldWidth-ball.X)
-					ball.Speed = 12.0 + rand.Float64()*8.0
-					break
-				}
+				// Long kick upfield
+				ball.Direction = math.Atan2(0, FieldWidth-ball.X)
+				ball.Speed = 12.0 + rand.Float64()*8.0
 			}
 		}
 	}
@@ -4157,6 +11598,14 @@ func main() {
 	// Apply application middleware
 	router.Use(applicationMiddleware)
 
+	// Request counts and latency for /metrics. Router middleware skips requests no route
+	// matches, so those are counted by wrapping the fallback handlers
+	router.Use(metricsMiddleware)
+	router.NotFoundHandler = metricsMiddleware(http.NotFoundHandler())
+	router.MethodNotAllowedHandler = metricsMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}))
+
 	// Home page route
 	router.HandleFunc("/", serveHomepage).Methods("GET")
 
@@ -4166,6 +11615,9 @@ func main() {
 	// Tables route
 	router.HandleFunc("/tables", getTableData).Methods("GET")
 
+	// Prometheus metrics
+	router.HandleFunc("/metrics", getMetrics).Methods("GET")
+
 	// Downloadable API schema file
 	router.PathPrefix("/api-schema.txt").Handler(http.StripPrefix("/", http.FileServer(http.Dir("."))))
 
@@ -4186,40 +11638,105 @@ func main() {
 	apiRouter.HandleFunc("/matches/{id:[0-9]+}/momentum", getMatchMomentum).Methods("GET")
 	apiRouter.HandleFunc("/matches/{id:[0-9]+}/probabilities", getMatchProbabilities).Methods("GET")
 	apiRouter.HandleFunc("/matches/{id:[0-9]+}/availability", getMatchAvailability).Methods("GET")
+	apiRouter.HandleFunc("/matches/{id:[0-9]+}/odds", getMatchOdds).Methods("GET")
+	apiRouter.HandleFunc("/matches/{id:[0-9]+}/timeline", getMatchTimeline).Methods("GET")
+	apiRouter.HandleFunc("/matches/{id:[0-9]+}/player-stats", getMatchPlayerStats).Methods("GET")
+	apiRouter.HandleFunc("/matches/{id:[0-9]+}/heatmap", getMatchHeatmap).Methods("GET")
+	apiRouter.HandleFunc("/matches/{id:[0-9]+}/average-positions", getMatchAveragePositions).Methods("GET")
+	apiRouter.HandleFunc("/matches/{id:[0-9]+}/trails", getMatchTrails).Methods("GET")
+	apiRouter.HandleFunc("/matches/{id:[0-9]+}/pass-network", getMatchPassNetwork).Methods("GET")
+	apiRouter.HandleFunc("/matches/{id:[0-9]+}/tactics", getMatchTacticsHistory).Methods("GET")
+	apiRouter.HandleFunc("/matches/{id:[0-9]+}/var", getMatchVARReviews).Methods("GET")
+	apiRouter.HandleFunc("/referees", getReferees).Methods("GET")
 
 	// Player endpoints
 	apiRouter.HandleFunc("/players", getAllPlayers).Methods("GET")
 	apiRouter.HandleFunc("/players/{id:[0-9]+}", getPlayer).Methods("GET")
+	apiRouter.HandleFunc("/players/{id:[0-9]+}/matches", getPlayerMatches).Methods("GET")
+	apiRouter.HandleFunc("/players/{id:[0-9]+}/career", getPlayerCareer).Methods("GET")
 
 	// Team endpoints
 	apiRouter.HandleFunc("/teams", getAllTeams).Methods("GET")
 	apiRouter.HandleFunc("/teams/{id:[0-9]+}", getTeam).Methods("GET")
 	apiRouter.HandleFunc("/teams/{id:[0-9]+}/form", getTeamForm).Methods("GET")
+	apiRouter.HandleFunc("/teams/{id:[0-9]+}/finances", getTeamFinances).Methods("GET")
+	apiRouter.HandleFunc("/teams/{id:[0-9]+}/ratings", getTeamRatings).Methods("GET")
+	apiRouter.HandleFunc("/teams/{id:[0-9]+}/rating-history", getTeamRatingHistory).Methods("GET")
+	apiRouter.HandleFunc("/teams/{id:[0-9]+}/stats", getTeamStats).Methods("GET")
+	apiRouter.HandleFunc("/rankings", getRankings).Methods("GET")
+
+	// Transfer endpoints
+	apiRouter.HandleFunc("/transfers", getTransfers).Methods("GET")
+
+	// News endpoints
+	apiRouter.HandleFunc("/news", getNews).Methods("GET")
 
 	// League endpoints
 	apiRouter.HandleFunc("/leagues/{league}/table", getLeagueTable).Methods("GET")
 	apiRouter.HandleFunc("/leagues/{league}/form", getLeagueForm).Methods("GET")
+	apiRouter.HandleFunc("/leagues/{league}/stats", getLeagueStats).Methods("GET")
 	apiRouter.HandleFunc("/leagues/{league}/schedule", getSeasonSchedule).Methods("GET")
 
+	// Log endpoints
+	apiRouter.HandleFunc("/logs", getLogs).Methods("GET")
+
 	// Season endpoints
 	apiRouter.HandleFunc("/seasons/current", getSeasonStats).Methods("GET")
 	apiRouter.HandleFunc("/seasons/history", getSeasonHistory).Methods("GET")
+	apiRouter.HandleFunc("/seasons/{season:[0-9]+}", getSeason).Methods("GET")
+	apiRouter.HandleFunc("/records", getRecords).Methods("GET")
 	apiRouter.HandleFunc("/seasons/current/matchdays/{matchday:[0-9]+}", getMatchdaySchedule).Methods("GET")
 
 	// Fixture endpoints - view all season fixtures upfront
 	apiRouter.HandleFunc("/fixtures", getAllFixtures).Methods("GET")
 	apiRouter.HandleFunc("/fixtures/{league}", getLeagueFixtures).Methods("GET")
 
+	// Fantasy endpoints
+	apiRouter.HandleFunc("/fantasy/teams", createFantasyTeam).Methods("POST", "OPTIONS")
+	apiRouter.HandleFunc("/fantasy/teams/{id:[0-9]+}", getFantasyTeam).Methods("GET")
+	apiRouter.HandleFunc("/fantasy/teams/{id:[0-9]+}/transfers", makeFantasyTransfer).Methods("POST", "OPTIONS")
+	apiRouter.HandleFunc("/fantasy/teams/{id:[0-9]+}/captain", setFantasyCaptain).Methods("PUT", "OPTIONS")
+	apiRouter.HandleFunc("/fantasy/teams/{id:[0-9]+}/gameweeks", getFantasyGameweeks).Methods("GET")
+	apiRouter.HandleFunc("/fantasy/leaderboard", getFantasyLeaderboard).Methods("GET")
+
+	// Prediction game endpoints
+	apiRouter.HandleFunc("/predictions", submitPrediction).Methods("POST", "OPTIONS")
+	apiRouter.HandleFunc("/predictions", getPredictions).Methods("GET")
+	apiRouter.HandleFunc("/predictions/leaderboard", getPredictionLeaderboard).Methods("GET")
+	apiRouter.HandleFunc("/predictions/users/{user}", getPredictionUser).Methods("GET")
+
 	// Print startup information
 	fmt.Printf("🚀 MatchPulse API v%s starting on port %s\n", version, port)
 	fmt.Printf("📚 API Documentation: %s/\n", baseURL)
 	fmt.Printf("🏥 Health Check: %s/api/v1/health\n", baseURL)
+	fmt.Printf("📉 Prometheus Metrics: %s/metrics\n", baseURL)
+	fmt.Printf("📜 Logs: %s/api/v1/logs?level=INFO\n", baseURL)
 	fmt.Printf("⚽ Live Matches: %s/api/v1/matches\n", baseURL)
 	fmt.Printf("📊 Match Details: %s/api/v1/matches/1\n", baseURL)
 	fmt.Printf("⚡ Match Momentum: %s/api/v1/matches/1/momentum\n", baseURL)
 	fmt.Printf("🎲 Match Probabilities: %s/api/v1/matches/1/probabilities\n", baseURL)
 	fmt.Printf("👥 Player Availability: %s/api/v1/matches/1/availability\n", baseURL)
+	fmt.Printf("💰 Match Odds: %s/api/v1/matches/1/odds\n", baseURL)
+	fmt.Printf("🕒 Match Timeline: %s/api/v1/matches/1/timeline\n", baseURL)
+	fmt.Printf("📋 Match Player Stats: %s/api/v1/matches/1/player-stats\n", baseURL)
+	fmt.Printf("🔥 Match Heatmap: %s/api/v1/matches/1/heatmap\n", baseURL)
+	fmt.Printf("🕸️  Pass Network: %s/api/v1/matches/1/pass-network\n", baseURL)
+	fmt.Printf("🧠 Match Tactics: %s/api/v1/matches/1/tactics\n", baseURL)
+	fmt.Printf("📺 VAR Reviews: %s/api/v1/matches/1/var\n", baseURL)
+	fmt.Printf("🧑‍⚖️ Referees: %s/api/v1/referees\n", baseURL)
+	fmt.Printf("🗒️  Player Game Log: %s/api/v1/players/1/matches\n", baseURL)
 	fmt.Printf("🏆 Season History: %s/api/v1/seasons/history\n", baseURL)
+	fmt.Printf("📚 Archived Season: %s/api/v1/seasons/1\n", baseURL)
+	fmt.Printf("🏛️ All-Time Records: %s/api/v1/records\n", baseURL)
+	fmt.Printf("🗂️ Player Career: %s/api/v1/players/1/career\n", baseURL)
+	fmt.Printf("💼 Transfers: %s/api/v1/transfers\n", baseURL)
+	fmt.Printf("📰 News Feed: %s/api/v1/news\n", baseURL)
+	fmt.Printf("💷 Club Finances: %s/api/v1/teams/1/finances\n", baseURL)
+	fmt.Printf("📐 Team Ratings: %s/api/v1/teams/1/ratings\n", baseURL)
+	fmt.Printf("🏅 Power Rankings: %s/api/v1/rankings\n", baseURL)
+	fmt.Printf("📈 Rating History: %s/api/v1/teams/1/rating-history\n", baseURL)
+	fmt.Printf("📊 Team Stats: %s/api/v1/teams/1/stats\n", baseURL)
+	fmt.Printf("🧮 League Stats: %s/api/v1/leagues/Premier%%20League/stats\n", baseURL)
 	fmt.Printf("📈 Current Season: %s/api/v1/seasons/current\n", baseURL)
 	fmt.Printf("🏅 League Table: %s/api/v1/leagues/Premier%%20League/table\n", baseURL)
 	fmt.Printf("📅 All Fixtures: %s/api/v1/fixtures\n", baseURL)
@@ -4266,14 +11783,34 @@ func finishMatch(matchID int, match *Match) {
 	// Calculate player ratings based on performance
 	calculatePlayerRatings(match)
 
+	// Close all betting markets
+	closeMatchOdds(matchID, match)
+
+	// Credit fantasy squads with this match's points
+	settleFantasyMatch(matchID, match)
+
+	// Score predictions for this fixture
+	scorePredictions(match)
+
+	// Gate receipts, broadcast fees and wages
+	settleMatchFinances(matchID, match)
+
+	// Carry the result into both sides' Elo ratings
+	updateEloRatings(match)
+
+	// Check the all-time records
+	updateRecords(matchID, match)
+
 	// Add final whistle commentary
 	addLiveCommentary(matchID, match.Minute,
 		fmt.Sprintf("Full time! %s %d - %d %s",
 			match.HomeTeam.Name, match.HomeScore,
 			match.AwayScore, match.AwayTeam.Name),
 		EventCommentary, nil)
+	recordTimelineEvent(match, &TimelineEvent{Type: EventFulltime})
+	generateFulltimeNews(matchID, match)
 
-	log.Printf("🏁 Match %d finished: %s %d-%d %s",
+	logWithFields(LevelInfo, matchLogFields(match, "engine"), "🏁 Match %d finished: %s %d-%d %s",
 		matchID, match.HomeTeam.ShortName, match.HomeScore,
 		match.AwayScore, match.AwayTeam.ShortName)
 }
@@ -4299,7 +11836,9 @@ func handleCornerEvent(matchID int, match *Match) {
 	// Recalculate probabilities as corners can lead to goals
 	recalculateMatchProbabilities(matchID, match)
 
-	log.Printf("⚽ Corner kick for %s in match %d", teamName, matchID)
+	recordTimelineEvent(match, &TimelineEvent{Type: EventCorner, TeamID: teamID})
+
+	logWithFields(LevelDebug, matchLogFields(match, "events"), "⚽ Corner kick for %s in match %d", teamName, matchID)
 	addLiveCommentary(matchID, match.Minute,
 		fmt.Sprintf("Corner kick for %s", teamName),
 		EventCorner, nil)
@@ -4326,8 +11865,8 @@ func handleFoulEvent(matchID int, match *Match) {
 		return
 	}
 
-	// Determine foul severity based on context
-	severity := determineFoulSeverity(fouler, ball, foulContext)
+	// Determine foul severity based on context and the referee
+	severity := determineFoulSeverity(match, fouler, ball, foulContext)
 
 	// Apply foul consequences
 	applyFoulConsequences(matchID, match, fouler, ball, severity, foulContext)
@@ -4452,32 +11991,41 @@ func selectFouler(candidates []*Player, ball *BallPosition, context FoulContext)
 	return candidates[len(candidates)-1]
 }
 
-func determineFoulSeverity(fouler *Player, ball *BallPosition, context FoulContext) string {
-	baseSeverity := "yellow"
+// determineFoulSeverity returns "none", "yellow" or "red"; stricter referees card more often
+// and a big home crowd sways them towards leniency for the home side
+func determineFoulSeverity(match *Match, fouler *Player, ball *BallPosition, context FoulContext) string {
+	strictness := refereeStrictness(match)
+	bias := crowdCardBias(match, fouler.TeamID)
+	redScale := (0.6 + 0.8*strictness) * bias
+
+	baseSeverity := "none"
+	if rand.Float64() < refereeJudgement(match, (0.3+0.5*strictness)*bias) {
+		baseSeverity = "yellow"
+	}
 
 	// Penalty area fouls more severe
 	if context.IsInPenaltyArea {
-		if rand.Float64() < 0.3 {
+		if rand.Float64() < refereeJudgement(match, 0.3*redScale) {
 			baseSeverity = "red"
 		}
 	}
 
 	// Goalkeeper handling outside penalty area
 	if fouler.Position == PosGK && !context.IsInPenaltyArea && ball.Speed > 5.0 {
-		if rand.Float64() < 0.6 {
+		if rand.Float64() < refereeJudgement(match, 0.6*redScale) {
 			baseSeverity = "red"
 		}
 	}
 
 	// Last man fouls
 	if context.IsNearGoal && fouler.Position == PosCB {
-		if rand.Float64() < 0.4 {
+		if rand.Float64() < refereeJudgement(match, 0.4*redScale) {
 			baseSeverity = "red"
 		}
 	}
 
 	// Dangerous play
-	if context.IsDangerousPlay && rand.Float64() < 0.25 {
+	if context.IsDangerousPlay && rand.Float64() < refereeJudgement(match, 0.25*redScale) {
 		baseSeverity = "red"
 	}
 
@@ -4492,11 +12040,27 @@ func applyFoulConsequences(matchID int, match *Match, fouler *Player, ball *Ball
 		matchStats[matchID].AwayFouls++
 	}
 
+	if line := matchPlayerLine(matchID, fouler); line != nil {
+		line.Fouls++
+	}
+
+	reason := foulReason(context)
+	position := ballPitchPosition(ball)
+	recordTimelineEvent(match, &TimelineEvent{
+		Type:       EventFoul,
+		TeamID:     fouler.TeamID,
+		PlayerID:   fouler.ID,
+		PlayerName: fouler.Name,
+		Reason:     reason,
+		Position:   position,
+	})
+
 	// Apply card
 	if severity == "yellow" {
 		fouler.YellowCards++
 		fouler.SeasonStats.YellowCardsThisSeason++
-		fouler.CurrentRating -= 0.3
+		recordCard(matchID, match, fouler, severity)
+		recordFantasyEvent(matchID, fouler, "yellow")
 
 		if fouler.TeamID == match.HomeTeam.ID {
 			matchStats[matchID].HomeYellowCards++
@@ -4504,7 +12068,17 @@ func applyFoulConsequences(matchID int, match *Match, fouler *Player, ball *Ball
 			matchStats[matchID].AwayYellowCards++
 		}
 
-		logInfo("🟨 Yellow card for %s (foul)", fouler.Name)
+		recordTimelineEvent(match, &TimelineEvent{
+			Type:       EventCard,
+			TeamID:     fouler.TeamID,
+			PlayerID:   fouler.ID,
+			PlayerName: fouler.Name,
+			Card:       "yellow",
+			Reason:     reason,
+			Position:   position,
+		})
+
+		logWithFields(LevelInfo, matchLogFields(match, "events"), "🟨 Yellow card for %s (foul)", fouler.Name)
 		addLiveCommentary(matchID, match.Minute,
 			fmt.Sprintf("Yellow card! %s commits a foul", fouler.Name),
 			EventCard, fouler)
@@ -4512,7 +12086,9 @@ func applyFoulConsequences(matchID int, match *Match, fouler *Player, ball *Ball
 	} else if severity == "red" {
 		fouler.RedCards++
 		fouler.SeasonStats.RedCardsThisSeason++
-		fouler.CurrentRating -= 1.5
+		setPlayerUnavailable(matchID, fouler.ID, PlayerRedCard, match.Minute, "Red card for serious foul play")
+		recordCard(matchID, match, fouler, severity)
+		recordFantasyEvent(matchID, fouler, "red")
 
 		if fouler.TeamID == match.HomeTeam.ID {
 			matchStats[matchID].HomeRedCards++
@@ -4520,10 +12096,29 @@ func applyFoulConsequences(matchID int, match *Match, fouler *Player, ball *Ball
 			matchStats[matchID].AwayRedCards++
 		}
 
-		logInfo("🟥 Red card for %s (serious foul)", fouler.Name)
+		redCardEvent := &TimelineEvent{
+			Type:       EventCard,
+			TeamID:     fouler.TeamID,
+			PlayerID:   fouler.ID,
+			PlayerName: fouler.Name,
+			Card:       "red",
+			Reason:     "Serious foul play",
+			Position:   position,
+		}
+		recordTimelineEvent(match, redCardEvent)
+		defer reviewRedCard(match, redCardEvent)
+
+		logWithFields(LevelInfo, matchLogFields(match, "events"), "🟥 Red card for %s (serious foul)", fouler.Name)
 		addLiveCommentary(matchID, match.Minute,
 			fmt.Sprintf("RED CARD! %s sent off for serious foul play!", fouler.Name),
 			EventCard, fouler)
+		redCardEvent.newsID = generateRedCardNews(matchID, match, fouler).ID
+	}
+
+	// The restart goes to the fouled side
+	awardedTo := match.HomeTeam.ID
+	if fouler.TeamID == match.HomeTeam.ID {
+		awardedTo = match.AwayTeam.ID
 	}
 
 	// Determine restart type
@@ -4534,11 +12129,24 @@ func applyFoulConsequences(matchID int, match *Match, fouler *Player, ball *Ball
 			penaltyX = FieldWidth - 11.0
 		}
 		setBallEvent(matchID, BallEventPenalty, penaltyX, FieldHeight/2, 0)
+		suspendMatchOdds(matchID, "PENALTY", OddsPenaltySuspensionSeconds)
 		addLiveCommentary(matchID, match.Minute, "PENALTY!", EventPenalty, nil)
+		penaltyEvent := &TimelineEvent{
+			Type:     EventPenalty,
+			TeamID:   awardedTo,
+			Detail:   "awarded",
+			Position: &PitchPosition{X: penaltyX, Y: FieldHeight / 2},
+		}
+		recordTimelineEvent(match, penaltyEvent)
+		if referee := matchReferee(match); referee != nil {
+			referee.PenaltiesAwarded++
+		}
+		reviewPenalty(match, penaltyEvent)
 	} else {
 		// Free kick
 		setBallEvent(matchID, BallEventFreekick, ball.X, ball.Y, 0)
 		addLiveCommentary(matchID, match.Minute, "Free kick awarded", EventFreekick, nil)
+		recordTimelineEvent(match, &TimelineEvent{Type: EventFreekick, TeamID: awardedTo, Position: position})
 	}
 }
 
@@ -4564,12 +12172,13 @@ func generateGenericCommentary(matchID int, match *Match) {
 		text = strings.ReplaceAll(text, "{team}", team)
 	}
 
-	log.Printf("💬 Commentary for match %d: %s", matchID, text)
+	logWithFields(LevelDebug, matchLogFields(match, "commentary"), "💬 Commentary for match %d: %s", matchID, text)
 	addLiveCommentary(matchID, match.Minute, text, EventCommentary, nil)
 }
 
 func addLiveCommentary(matchID, minute int, text, eventType string, player *Player) {
 	commentaryCounter++
+	recordEventMetric(eventType)
 
 	commentary := &LiveCommentary{
 		ID:         commentaryCounter,
@@ -4587,13 +12196,14 @@ func addLiveCommentary(matchID, minute int, text, eventType string, player *Play
 		liveCommentary[matchID] = []*LiveCommentary{}
 	}
 
-	liveCommentary[matchID] = append([]*LiveCommentary{commentary}, liveCommentary[matchID]...)
+	liveCommentary[matchID] = append(liveCommentary[matchID], commentary)
 
-	if len(liveCommentary[matchID]) > 30 {
-		liveCommentary[matchID] = liveCommentary[matchID][:30]
+	// Drop the oldest entries once the per-match cap is reached
+	if overflow := len(liveCommentary[matchID]) - commentaryCap; overflow > 0 {
+		liveCommentary[matchID] = append([]*LiveCommentary(nil), liveCommentary[matchID][overflow:]...)
 	}
 
-	log.Printf("📝 Commentary added for match %d: %s", matchID, text)
+	logWithFields(LevelDebug, LogFields{MatchID: matchID, Component: "commentary"}, "📝 Commentary added for match %d: %s", matchID, text)
 }
 
 func generateAudioText(text, eventType string) string {
@@ -4655,8 +12265,8 @@ func updatePlayerLocationsForMatch(matchID int) {
 	}
 
 	// Get starting 11 from each team
-	homePlayers := getPlayersFromTeam(match.HomeTeam.ID)[:11]
-	awayPlayers := getPlayersFromTeam(match.AwayTeam.ID)[:11]
+	homePlayers := getMatchLineup(matchID, match.HomeTeam.ID)
+	awayPlayers := getMatchLineup(matchID, match.AwayTeam.ID)
 
 	// Update positions based on tactics and ball position
 	updateTeamWithTactics(matchID, homePlayers, true, homePossession, tactics.HomeOffensive, tactics.HomeDefensive, ball, match)
@@ -4664,6 +12274,8 @@ func updatePlayerLocationsForMatch(matchID int) {
 
 	// Update ball position
 	updateBallPhysics(matchID, ball)
+	trackBallTouch(matchID, ball)
+	recordTrackingFrame(matchID, match, ball)
 }
 
 func updateTeamWithTactics(matchID int, teamPlayers []*Player, isHome, hasPossession bool,
@@ -4701,6 +12313,14 @@ func updateTeamWithTactics(matchID int, teamPlayers []*Player, isHome, hasPosses
 		x = math.Max(0, math.Min(FieldWidth, x))
 		y = math.Max(0, math.Min(FieldHeight, y))
 
+		if previous := playerLocations[matchID][player.ID]; previous != nil {
+			if line := matchPlayerLine(matchID, player); line != nil {
+				moved := distance(previous.X, previous.Y, x, y)
+				line.Distance = math.Round((line.Distance+moved)*10) / 10
+				drainStamina(line, player, moved, match)
+			}
+		}
+
 		playerLocations[matchID][player.ID] = &PlayerLocation{
 			PlayerID:  player.ID,
 			X:         x,
@@ -4730,6 +12350,12 @@ func calculateOffensivePosition(player *Player, basePos FormationPosition, tacti
 			x += 15
 		}
 
+	case TacticPressing:
+		// Whole team squeezes up to win the ball back high
+		if player.Position != PosGK {
+			x += 8
+		}
+
 	case TacticWingPlay:
 		// Wingers stay wide
 		if player.Position == PosLW {
@@ -4772,101 +12398,22 @@ func distance(x1, y1, x2, y2 float64) float64 {
 	return math.Sqrt(math.Pow(x2-x1, 2) + math.Pow(y2-y1, 2))
 }
 
+// calculatePlayerRatings settles the final rating of every starter
 func calculatePlayerRatings(match *Match) {
 	if match.PlayerRatings == nil {
 		match.PlayerRatings = make(map[int]float64)
 	}
 
-	homePlayers := getPlayersFromTeam(match.HomeTeam.ID)
-	awayPlayers := getPlayersFromTeam(match.AwayTeam.ID)
-
-	for _, player := range homePlayers {
-		rating := calculateIndividualRating(player, match, true)
-		match.PlayerRatings[player.ID] = rating
-		updatePlayerSeasonStats(player, rating, 90)
-	}
-
-	for _, player := range awayPlayers {
-		rating := calculateIndividualRating(player, match, false)
-		match.PlayerRatings[player.ID] = rating
-		updatePlayerSeasonStats(player, rating, 90)
-	}
-}
-
-func calculateIndividualRating(player *Player, match *Match, isHome bool) float64 {
-	baseRating := 6.0
-
-	// Performance during match (accumulated from events)
-	performanceBonus := player.CurrentRating - 6.0
-	baseRating += math.Max(-2.5, math.Min(4.0, performanceBonus))
-
-	// Position-specific bonuses
-	switch player.Position {
-	case PosGK:
-		// GK gets bonus for saves, penalty for goals conceded
-		oppScore := match.AwayScore
-		if !isHome {
-			oppScore = match.HomeScore
-		}
-		if oppScore == 0 {
-			baseRating += 1.0 // Clean sheet bonus
-		} else if oppScore >= 3 {
-			baseRating -= 0.5 // Heavy defeat penalty
-		}
-
-	case PosST, PosLW, PosRW:
-		// Attackers get bonus for goals and shots
-		if player.SeasonStats.GoalsThisSeason > 0 && rand.Float64() < 0.3 {
-			baseRating += 0.8 // Goal bonus
-		}
-
-	case PosCB, PosLB, PosRB:
-		// Defenders get clean sheet bonus
-		oppScore := match.AwayScore
-		if !isHome {
-			oppScore = match.HomeScore
-		}
-		if oppScore == 0 {
-			baseRating += 0.7
-		} else if oppScore >= 2 {
-			baseRating -= 0.4
-		}
-
-	case PosCM, PosCAM, PosCDM:
-		// Midfielders get bonus for assists and all-round play
-		if player.SeasonStats.AssistsThisSeason > 0 && rand.Float64() < 0.2 {
-			baseRating += 0.5 // Assist bonus
+	refreshMatchPlayerStats(match.ID, match)
+	for playerID, line := range matchPlayerStats[match.ID] {
+		player := players[playerID]
+		if player == nil {
+			continue
 		}
+		match.PlayerRatings[playerID] = line.Rating
+		updatePlayerSeasonStats(player, line.Rating, line.Minutes)
+		player.CurrentRating = 6.0
 	}
-
-	// Team result impact
-	teamScore := match.HomeScore
-	oppScore := match.AwayScore
-	if !isHome {
-		teamScore = match.AwayScore
-		oppScore = match.HomeScore
-	}
-
-	if teamScore > oppScore {
-		baseRating += 0.4 // Win bonus
-	} else if teamScore < oppScore {
-		baseRating -= 0.4 // Loss penalty
-	} else {
-		baseRating += 0.1 // Small draw bonus
-	}
-
-	// Card penalties
-	if player.YellowCards > 0 {
-		baseRating -= 0.3
-	}
-	if player.RedCards > 0 {
-		baseRating -= 1.5
-	}
-
-	// Reset for next match
-	player.CurrentRating = 6.0
-
-	return math.Max(3.0, math.Min(10.0, baseRating))
 }
 
 func updatePlayerSeasonStats(player *Player, rating float64, minutesPlayed int) {
@@ -4965,7 +12512,7 @@ func generateSeasonSchedule(league string) {
 	leagueTeams := getTeamsByLeague(league)
 
 	if len(leagueTeams) != config.TeamCount {
-		log.Printf("❌ League %s has %d teams, expected %d", league, len(leagueTeams), config.TeamCount)
+		logError("❌ League %s has %d teams, expected %d", league, len(leagueTeams), config.TeamCount)
 		return
 	}
 
@@ -4980,7 +12527,9 @@ func generateSeasonSchedule(league string) {
 	for round := 0; round < totalRounds; round++ {
 		roundMatches := generateRoundMatches(leagueTeams, round)
 		for _, match := range roundMatches {
+			fixtureCounter++
 			schedule := &SeasonSchedule{
+				ID:          fixtureCounter,
 				Matchday:    matchday,
 				League:      league,
 				HomeTeam:    match.Home,
@@ -4998,7 +12547,9 @@ func generateSeasonSchedule(league string) {
 		roundMatches := generateRoundMatches(leagueTeams, round)
 		for _, match := range roundMatches {
 			// Reverse home and away for return leg
+			fixtureCounter++
 			schedule := &SeasonSchedule{
+				ID:          fixtureCounter,
 				Matchday:    matchday,
 				League:      league,
 				HomeTeam:    match.Away, // Swapped
@@ -5012,7 +12563,7 @@ func generateSeasonSchedule(league string) {
 	}
 
 	seasonSchedules[league] = schedules
-	log.Printf("📅 Generated complete season schedule for %s: %d matches across %d matchdays (%d teams)",
+	logWithFields(LevelInfo, LogFields{League: league, Component: "scheduler"}, "📅 Generated complete season schedule for %s: %d matches across %d matchdays (%d teams)",
 		league, len(schedules), matchday-1, len(leagueTeams))
 }
 
@@ -5028,7 +12579,7 @@ func generateRoundMatches(teams []*TeamInfo, round int) []RoundMatch {
 	n := len(teams)
 
 	if n%2 != 0 {
-		log.Printf("⚠️ Odd number of teams (%d), scheduling may be unbalanced", n)
+		logWarn("⚠️ Odd number of teams (%d), scheduling may be unbalanced", n)
 		return matches
 	}
 
@@ -5059,12 +12610,12 @@ func logFixtureSummary() {
 	totalFixtures := 0
 	for league, schedules := range seasonSchedules {
 		totalFixtures += len(schedules)
-		log.Printf("📅 %s: %d fixtures generated (Matchdays 1-%d)",
+		logWithFields(LevelInfo, LogFields{League: league, Component: "scheduler"}, "📅 %s: %d fixtures generated (Matchdays 1-%d)",
 			league, len(schedules), len(schedules)/(len(getTeamsByLeague(league))/2))
 	}
-	log.Printf("🏆 Total season fixtures: %d matches across all leagues", totalFixtures)
-	log.Printf("🔍 View all fixtures: GET /api/v1/fixtures")
-	log.Printf("🔍 View league fixtures: GET /api/v1/fixtures/{league}")
+	logWithFields(LevelInfo, LogFields{Component: "scheduler"}, "🏆 Total season fixtures: %d matches across all leagues", totalFixtures)
+	logDebug("🔍 View all fixtures: GET /api/v1/fixtures")
+	logDebug("🔍 View league fixtures: GET /api/v1/fixtures/{league}")
 }
 
 func getScheduledMatches(league string, matchday int) []*SeasonSchedule {
@@ -5219,50 +12770,155 @@ func getTeamMatchesPlayed(teamID int) int {
 	return matchesPlayed
 }
 
-// Form calculation functions
-func calculateMatchProbabilities(homeTeam, awayTeam *TeamInfo) (homeWin, draw, awayWin float64) {
-	// Calculate base team strengths
-	homeStrength := calculateTeamStrength(homeTeam)
-	awayStrength := calculateTeamStrength(awayTeam)
+// derbyName returns the rivalry name when two teams contest a derby
+func derbyName(teamA, teamB int) string {
+	for _, derby := range derbies {
+		if (derby.TeamA == teamA && derby.TeamB == teamB) || (derby.TeamA == teamB && derby.TeamB == teamA) {
+			return derby.Name
+		}
+	}
+	return ""
+}
+
+// tableStanding returns a team's league position and table size, or 0 before it has played
+func tableStanding(team *TeamInfo) (position, size int) {
+	table := leagueTables[team.League]
+	for i, entry := range table {
+		if entry.Team.ID == team.ID && entry.Played > 0 {
+			return i + 1, len(table)
+		}
+	}
+	return 0, len(table)
+}
+
+// calculateAttendance fills the home stadium according to form, table position and rivalry
+func calculateAttendance(home, away *TeamInfo, derby string) (attendance int, occupancy float64) {
+	occupancy = 0.55
+	if home.League == LeaguePremier {
+		occupancy = 0.65
+	}
+
+	// Winning sides and title challengers sell more tickets
+	occupancy += float64(home.FormPoints) / 15.0 * 0.15
+	if position, size := tableStanding(home); position > 0 && size > 1 {
+		occupancy += (1 - float64(position-1)/float64(size-1)) * 0.1
+	}
+
+	// A high-flying visitor is an attraction too
+	if position, size := tableStanding(away); position > 0 && size > 1 {
+		occupancy += (1 - float64(position-1)/float64(size-1)) * 0.05
+	}
 
-	// Home advantage factor (1.1 multiplier)
-	homeStrength *= 1.1
+	if derby != "" {
+		occupancy += DerbyAttendanceBoost
+	}
 
-	// Calculate total strength
-	totalStrength := homeStrength + awayStrength
+	occupancy += (rand.Float64() - 0.5) * 0.1
+	occupancy = math.Max(0.3, math.Min(1.0, occupancy))
 
-	// Base probabilities
-	homeWin = homeStrength / totalStrength
-	awayWin = awayStrength / totalStrength
-	draw = 0.3 * (1.0 - math.Abs(homeWin-awayWin)) // More likely to draw if teams are evenly matched
+	return int(float64(home.Capacity) * occupancy), occupancy
+}
 
-	// Normalize probabilities
-	total := homeWin + draw + awayWin
-	homeWin /= total
-	draw /= total
-	awayWin /= total
+// crowdFactor rates the atmosphere from 0 to 1: full, large stadiums and derbies are loudest
+func crowdFactor(capacity int, occupancy float64, derby string) float64 {
+	largest := 0
+	for _, team := range teams {
+		if team.Capacity > largest {
+			largest = team.Capacity
+		}
+	}
+	size := 1.0
+	if largest > 0 {
+		size = float64(capacity) / float64(largest)
+	}
+
+	factor := occupancy * (0.5 + 0.5*size)
+	if derby != "" {
+		factor += 0.1
+	}
+	return math.Round(math.Min(1.0, factor)*100) / 100
+}
+
+// recordHomeAttendance adds a gate to the home team's season averages
+func recordHomeAttendance(team *TeamInfo, attendance int) {
+	team.homeGates++
+	team.totalAttendance += attendance
+	team.AverageAttendance = team.totalAttendance / team.homeGates
+	if team.Capacity > 0 {
+		team.AverageOccupancy = math.Round(float64(team.AverageAttendance)/float64(team.Capacity)*1000) / 10
+	}
+}
 
-	return homeWin, draw, awayWin
+// homeAdvantage is the home strength multiplier for a crowd factor (1.04 to 1.16)
+func homeAdvantage(crowdFactor float64) float64 {
+	return BaseHomeAdvantage + CrowdHomeAdvantage*crowdFactor
 }
 
-func calculateAttackStrength(team *TeamInfo) float64 {
-	// Base strength (0.4 to ensure minimum reasonable strength)
-	baseStrength := 0.4
+// crowdCardBias scales a card chance for a player's team; referees lean towards the home side
+func crowdCardBias(match *Match, teamID int) float64 {
+	if teamID == match.HomeTeam.ID {
+		return 1 - CrowdCardBias*match.CrowdFactor
+	}
+	return 1 + CrowdCardBias*match.CrowdFactor
+}
 
-	// Form strength (0-15 points possible, scaled to 0.0-0.3)
-	formStrength := float64(team.FormPoints) / 15.0 * 0.3
+// applyCrowdInfluence lifts a flagging home side towards the crowd's momentum level. Caller must hold mutex
+func applyCrowdInfluence(matchID int, match *Match) {
+	momentum := matchMomentum[matchID]
+	if momentum == nil || match.CrowdFactor <= 0 {
+		return
+	}
 
-	// Calculate final strength (0.4 to 0.7)
-	strength := baseStrength + formStrength
+	// The crowd gets louder when the home side is chasing the game late on
+	level := match.CrowdFactor * 0.3
+	trailingLate := match.HomeScore < match.AwayScore && match.Minute >= 70
+	if trailingLate {
+		level *= 2
+	}
+	if momentum.HomeTeamMomentum < level {
+		momentum.HomeTeamMomentum += (level - momentum.HomeTeamMomentum) * CrowdMomentumPull
+	}
 
-	// Ensure strength is within bounds
-	if strength < 0.4 {
-		strength = 0.4
-	} else if strength > 0.7 {
-		strength = 0.7
+	if trailingLate && match.CrowdFactor >= 0.6 && rand.Float64() < 0.02 {
+		addLiveCommentary(matchID, match.Minute, fmt.Sprintf("The %d fans at %s are roaring %s forward!",
+			match.Attendance, match.Venue, match.HomeTeam.Name), EventCommentary, nil)
 	}
+}
+
+// calculateMatchProbabilities blends the lineups' and Elo expected scores on neutral ground,
+// applies the crowd's home advantage once, and splits the result into home win, draw and
+// away win. Caller must hold mutex
+func calculateMatchProbabilities(matchID int, homeTeam, awayTeam *TeamInfo, crowdFactor float64) (homeWin, draw, awayWin float64) {
+	// Calculate base team strengths
+	homeStrength := calculateTeamStrength(matchID, homeTeam)
+	awayStrength := calculateTeamStrength(matchID, awayTeam)
+
+	// Both expectations are on neutral ground; the crowd supplies the only home advantage
+	lineupExpected := homeStrength / (homeStrength + awayStrength)
+	eloExpectedScore := eloWinExpectancy(eloRating(homeTeam) - eloRating(awayTeam))
+
+	// Blend in the Elo expectation, which carries results across seasons
+	expected := (1-EloProbabilityWeight)*lineupExpected + EloProbabilityWeight*eloExpectedScore
+	return outcomeProbabilities(withHomeAdvantage(expected, homeAdvantage(crowdFactor)))
+}
+
+// withHomeAdvantage scales the home side's strength in an expected score by a home multiplier
+func withHomeAdvantage(expected, advantage float64) float64 {
+	home := expected * advantage
+	return home / (home + 1 - expected)
+}
+
+// outcomeProbabilities splits an expected score (win 1, draw 0.5) into win, draw and loss
+// chances. Evenly matched sides draw most often
+func outcomeProbabilities(expected float64) (win, draw, loss float64) {
+	expected = math.Max(0, math.Min(1, expected))
+	draw = MaxDrawProbability * (1 - math.Abs(2*expected-1))
+	return expected - draw/2, draw, 1 - expected - draw/2
+}
 
-	return strength
+// calculateAttackStrength rates a team's attack on the 0.4-0.7 strength scale. Caller must hold mutex
+func calculateAttackStrength(matchID int, team *TeamInfo) float64 {
+	return calculateTeamRatings(matchID, team).AttackStrength
 }
 
 func updateTeamForm(team *TeamInfo, result string, isHome bool) {
@@ -5283,29 +12939,133 @@ func updateTeamForm(team *TeamInfo, result string, isHome bool) {
 		}
 	}
 
-	logInfo("Updated team form: %s - Form: %v, Points: %d",
+	logDebug("Updated team form: %s - Form: %v, Points: %d",
 		team.ShortName, team.Form, team.FormPoints)
 }
 
-// Calculate team strength based on player ratings and form
-func calculateTeamStrength(team *TeamInfo) float64 {
-	// Base strength (0.4 to ensure minimum reasonable strength)
-	baseStrength := 0.4
+// calculateTeamStrength rates a whole team on the 0.4-0.7 strength scale. Caller must hold mutex
+func calculateTeamStrength(matchID int, team *TeamInfo) float64 {
+	return calculateTeamRatings(matchID, team).Strength
+}
+
+// Line weights (attack, midfield, defence) by position group
+var lineWeights = map[string][3]float64{
+	"GK":  {0, 0, 1.0},
+	"DEF": {0.15, 0.3, 1.0},
+	"MID": {0.5, 1.0, 0.4},
+	"FWD": {1.0, 0.3, 0.1},
+}
+
+// lineScores rates a player's contribution to attack, midfield and defence
+func lineScores(c PlayerCharacteristics) [3]float64 {
+	return [3]float64{
+		0.45*float64(c.Shooting) + 0.25*float64(c.Speed) + 0.15*float64(c.Passing) + 0.15*float64(c.Mentality),
+		0.45*float64(c.Passing) + 0.2*float64(c.Mentality) + 0.15*float64(c.Physicality) + 0.1*float64(c.Defending) + 0.1*float64(c.Speed),
+		0.5*float64(c.Defending) + 0.25*float64(c.Physicality) + 0.15*float64(c.Mentality) + 0.1*float64(c.Speed),
+	}
+}
+
+// calculateTeamRatings rates the XI a team fielded in a match, live or finished, in the
+// formation it played, or its strongest 4-4-2 when matchID has no lineup. Missing players
+// (red cards, injuries) weaken their lines and tired players count for less. Caller must hold mutex
+func calculateTeamRatings(matchID int, team *TeamInfo) *TeamRatings {
+	ratings := &TeamRatings{TeamID: team.ID, TeamName: team.Name, Source: "squad", Formation: Formation442}
+
+	lineup, inMatch := matchLineups[matchID][team.ID]
+	if inMatch {
+		ratings.MatchID = matchID
+		ratings.Source = "match"
+		if match := findMatch(matchID); match != nil && match.AwayTeam.ID == team.ID {
+			ratings.Formation = match.AwayFormation
+		} else if match != nil {
+			ratings.Formation = match.HomeFormation
+		}
+	} else {
+		lineup = selectLineup(team.ID, Formation442)
+	}
+
+	var scored, total [3]float64
+	staminaSum, onPitch := 0.0, 0
+	for _, playerID := range lineup {
+		player := players[playerID]
+		if player == nil {
+			continue
+		}
+		// Substituted players were replaced in their slot, so they no longer count
+		if availability := playerAvailability[matchID][playerID]; inMatch && availability != nil && availability.Status == PlayerSubstituted {
+			continue
+		}
+		weights := lineWeights[fantasyPositionGroup(player.Position)]
+		line := &PlayerLineRating{
+			PlayerID:  player.ID,
+			Name:      player.Name,
+			Position:  player.Position,
+			Overall:   player.Characteristics.Overall,
+			Stamina:   100,
+			Available: true,
+			Status:    PlayerAvailable,
+		}
+		ratings.Players = append(ratings.Players, line)
+
+		if inMatch {
+			line.Stamina = math.Round(playerStamina(matchID, player)*10) / 10
+			if availability := playerAvailability[matchID][player.ID]; availability != nil && availability.Status != PlayerAvailable {
+				line.Available = false
+				line.Status = availability.Status
+				switch availability.Status {
+				case PlayerRedCard:
+					ratings.RedCards++
+				case PlayerInjured:
+					ratings.Injuries++
+				}
+			}
+		}
+
+		for i := range total {
+			total[i] += weights[i]
+		}
+		if !line.Available {
+			continue
+		}
 
-	// Form strength (0-15 points possible, scaled to 0.0-0.3)
-	formStrength := float64(team.FormPoints) / 15.0 * 0.3
+		fatigue := 0.8 + 0.2*line.Stamina/100
+		scores := lineScores(player.Characteristics)
+		for i := range scored {
+			scored[i] += scores[i] * fatigue * weights[i]
+		}
+		staminaSum += line.Stamina
+		onPitch++
+	}
 
-	// Calculate final strength (0.4 to 0.7)
-	strength := baseStrength + formStrength
+	// Average over the players on the pitch, then dock each line for its missing weight
+	var values [3]float64
+	for i := range values {
+		present := total[i]
+		for _, line := range ratings.Players {
+			if !line.Available {
+				present -= lineWeights[fantasyPositionGroup(line.Position)][i]
+			}
+		}
+		if present > 0 {
+			values[i] = scored[i] / present * (1 - MissingLinePenalty*(1-present/total[i]))
+		}
+	}
 
-	// Ensure strength is within bounds
-	if strength < 0.4 {
-		strength = 0.4
-	} else if strength > 0.7 {
-		strength = 0.7
+	round := func(value float64) float64 { return math.Round(value*10) / 10 }
+	ratings.Attack, ratings.Midfield, ratings.Defence = round(values[0]), round(values[1]), round(values[2])
+	ratings.Overall = round(0.35*values[0] + 0.3*values[1] + 0.35*values[2])
+	ratings.Strength = ratingStrength(ratings.Overall, team)
+	ratings.AttackStrength = ratingStrength(ratings.Attack, team)
+	if onPitch > 0 {
+		ratings.AverageStamina = round(staminaSum / float64(onPitch))
 	}
+	return ratings
+}
 
-	return strength
+// ratingStrength maps a line rating onto the 0.4-0.7 strength scale, nudged by recent form
+func ratingStrength(rating float64, team *TeamInfo) float64 {
+	strength := 0.4 + (rating-RatingFloor)/(RatingCeiling-RatingFloor)*0.25 + float64(team.FormPoints)/15.0*0.05
+	return math.Round(math.Max(0.4, math.Min(0.7, strength))*1000) / 1000
 }
 
 func getTableData(w http.ResponseWriter, r *http.Request) {
@@ -5704,15 +13464,142 @@ func getActiveClass(isActive bool) string {
 	return ""
 }
 
+// Structured logging with levels, captured in a ring buffer for /api/v1/logs
+type LogFields struct {
+	MatchID   int
+	League    string
+	Component string
+}
+
+func matchLogFields(match *Match, component string) LogFields {
+	return LogFields{MatchID: match.ID, League: match.Competition, Component: component}
+}
+
+func logDebug(format string, args ...interface{}) {
+	logWithFields(LevelDebug, LogFields{}, format, args...)
+}
+
 func logInfo(format string, args ...interface{}) {
+	logWithFields(LevelInfo, LogFields{}, format, args...)
+}
+
+func logWarn(format string, args ...interface{}) {
+	logWithFields(LevelWarn, LogFields{}, format, args...)
+}
+
+func logError(format string, args ...interface{}) {
+	logWithFields(LevelError, LogFields{}, format, args...)
+}
+
+func logWithFields(level string, fields LogFields, format string, args ...interface{}) {
+	if logLevelRank[level] < logLevelRank[logLevel] {
+		return
+	}
+
 	message := fmt.Sprintf(format, args...)
-	log.Printf(message)          // Console output
-	addLogEntry("INFO", message) // Capture for API
+	log.Printf("%-5s %s", level, message) // Console output
+	addLogEntry(level, message, fields)   // Capture for API
+}
+
+func addLogEntry(level, message string, fields LogFields) {
+	logMutex.Lock()
+	defer logMutex.Unlock()
+
+	// Keep only the last MaxLogEntries entries
+	logCounter++
+	logEntries = append(logEntries, &LogEntry{
+		ID:        logCounter,
+		Level:     level,
+		Message:   message,
+		MatchID:   fields.MatchID,
+		League:    fields.League,
+		Component: fields.Component,
+		Timestamp: time.Now(),
+	})
+	if len(logEntries) > MaxLogEntries {
+		logEntries = logEntries[1:]
+	}
+}
+
+func loadLogLevel() {
+	if value := strings.ToUpper(os.Getenv("LOG_LEVEL")); value != "" {
+		if _, valid := logLevelRank[value]; valid {
+			logLevel = value
+		}
+	}
 }
 
-func addLogEntry(level, message string) {
-	// Store in logEntries slice for /api/v1/logs endpoint
-	// Keep only last 1000 entries as specified
+func getLogs(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	component := query.Get("component")
+	league := query.Get("league")
+
+	minLevel := strings.ToUpper(query.Get("level"))
+	if minLevel != "" {
+		if _, valid := logLevelRank[minLevel]; !valid {
+			http.Error(w, "Invalid level (use DEBUG, INFO, WARN or ERROR)", http.StatusBadRequest)
+			return
+		}
+	}
+
+	var since time.Time
+	if value := query.Get("since"); value != "" {
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			http.Error(w, "Invalid since (use RFC 3339)", http.StatusBadRequest)
+			return
+		}
+		since = parsed
+	}
+
+	matchID := 0
+	if value := query.Get("match_id"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			http.Error(w, "Invalid match ID", http.StatusBadRequest)
+			return
+		}
+		matchID = parsed
+	}
+
+	limit := 100
+	if value := query.Get("limit"); value != "" {
+		if parsed, err := strconv.Atoi(value); err == nil && parsed > 0 {
+			limit = min(parsed, MaxLogEntries)
+		}
+	}
+
+	logMutex.Lock()
+	var entries []*LogEntry
+	// Newest first
+	for i := len(logEntries) - 1; i >= 0 && len(entries) < limit; i-- {
+		entry := logEntries[i]
+		if minLevel != "" && logLevelRank[entry.Level] < logLevelRank[minLevel] {
+			continue
+		}
+		if component != "" && entry.Component != component {
+			continue
+		}
+		if league != "" && entry.League != league {
+			continue
+		}
+		if matchID != 0 && entry.MatchID != matchID {
+			continue
+		}
+		if !since.IsZero() && !entry.Timestamp.After(since) {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	logMutex.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"logs":      entries,
+		"count":     len(entries),
+		"log_level": logLevel,
+		"timestamp": time.Now(),
+	})
 }
 
 func generateMatchesTable(page, itemsPerPage int) (string, int) {
@@ -5755,7 +13642,7 @@ func generateMatchesTable(page, itemsPerPage int) (string, int) {
 		for _, match := range matchList[start:end] {
 			statusClass := ""
 			switch match.Status {
-			case StatusLive:
+			case StatusLive, StatusVARCheck:
 				statusClass = "status-live"
 			case StatusFinished:
 				statusClass = "status-finished"
@@ -0,0 +1,1717 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+func TestCalculateOddsPrice(t *testing.T) {
+	tests := []struct {
+		name        string
+		probability float64
+		margin      float64
+		decimal     float64
+		fractional  string
+		american    string
+	}{
+		{"evens with margin", 0.5, 0.05, 1.90, "7/8", "-111"},
+		{"fair price", 0.25, 0, 4.00, "3/1", "+300"},
+		{"outsider with margin", 0.25, 0.05, 3.81, "11/4", "+281"},
+		{"impossible outcome is capped", 0, 0.05, 1000, "999/1", "+99900"},
+		{"certain outcome is floored", 1, 0.05, 1.01, "1/100", "-10000"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			price := calculateOddsPrice(tt.probability, tt.margin)
+			if price.Decimal != tt.decimal {
+				t.Errorf("decimal = %v, want %v", price.Decimal, tt.decimal)
+			}
+			if price.Fractional != tt.fractional {
+				t.Errorf("fractional = %v, want %v", price.Fractional, tt.fractional)
+			}
+			if price.American != tt.american {
+				t.Errorf("american = %v, want %v", price.American, tt.american)
+			}
+		})
+	}
+}
+
+func TestCalculateOddsPriceOverround(t *testing.T) {
+	// A fair three-way market priced with the margin should book close to 1+margin
+	margin := 0.05
+	overround := 0.0
+	for _, probability := range []float64{0.45, 0.3, 0.25} {
+		overround += 1 / calculateOddsPrice(probability, margin).Decimal
+	}
+	if overround < 1+margin-0.01 || overround > 1+margin+0.01 {
+		t.Errorf("overround = %.4f, want about %.2f", overround, 1+margin)
+	}
+}
+
+func TestNegotiateTransfer(t *testing.T) {
+	defer func(seed int64) { transferSeed = seed }(transferSeed)
+
+	player := &Player{ID: 42, Name: "Test Player", Position: PosST, TeamID: 1, MarketValue: 40}
+	tests := []struct {
+		name       string
+		budget     int
+		squadSize  int
+		alwaysFail bool
+	}{
+		{"no budget is always rejected", 0, MaxSquadSize, true},
+		{"budget below market value is always rejected", 30, MaxSquadSize, true},
+		{"healthy squad", 1000, MaxSquadSize, false},
+		{"thin squad", 1000, MinSquadSize, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			completed := 0
+			for seed := int64(1); seed <= 200; seed++ {
+				transferSeed = seed
+				transfer := negotiateTransfer(1, player, 2, tt.budget, tt.squadSize)
+
+				again := negotiateTransfer(1, player, 2, tt.budget, tt.squadSize)
+				if again.Status != transfer.Status || again.Fee != transfer.Fee || again.Rounds != transfer.Rounds {
+					t.Fatalf("seed %d: negotiation is not reproducible", seed)
+				}
+				if transfer.Rounds < 1 || transfer.Rounds > MaxNegotiationRounds {
+					t.Fatalf("seed %d: rounds = %d", seed, transfer.Rounds)
+				}
+				if transfer.Status != TransferCompleted {
+					continue
+				}
+
+				completed++
+				if tt.alwaysFail {
+					t.Fatalf("seed %d: completed for %d with a budget of %d", seed, transfer.Fee, tt.budget)
+				}
+				if transfer.Fee > tt.budget || float64(transfer.Fee) < 0.99*float64(player.MarketValue) {
+					t.Errorf("seed %d: fee %d outside [%.0f, %d]", seed, transfer.Fee, 0.99*float64(player.MarketValue), tt.budget)
+				}
+				if transfer.Rounds == 1 {
+					t.Errorf("seed %d: opening bid should never meet the asking price", seed)
+				}
+			}
+			if !tt.alwaysFail && completed == 0 {
+				t.Errorf("no transfer completed in 200 negotiations")
+			}
+		})
+	}
+}
+
+func TestNegotiateTransferThinSquadCostsMore(t *testing.T) {
+	defer func(seed int64) { transferSeed = seed }(transferSeed)
+
+	// With the same seed a thin squad's asking price is 20% higher throughout
+	player := &Player{ID: 42, Name: "Test Player", Position: PosST, TeamID: 1, MarketValue: 40}
+	for seed := int64(1); seed <= 200; seed++ {
+		transferSeed = seed
+		healthy := negotiateTransfer(1, player, 2, 1000, MaxSquadSize)
+		thin := negotiateTransfer(1, player, 2, 1000, MinSquadSize)
+		if thin.Status != TransferCompleted {
+			continue
+		}
+		if healthy.Status != TransferCompleted || healthy.Rounds > thin.Rounds || healthy.Fee > thin.Fee {
+			t.Errorf("seed %d: thin squad sold in %d rounds for %d, healthy squad %s in %d rounds for %d",
+				seed, thin.Rounds, thin.Fee, healthy.Status, healthy.Rounds, healthy.Fee)
+		}
+	}
+}
+
+func TestOrdinal(t *testing.T) {
+	tests := map[int]string{1: "1st", 2: "2nd", 3: "3rd", 4: "4th", 11: "11th", 12: "12th", 13: "13th", 21: "21st", 22: "22nd", 101: "101st", 111: "111th"}
+	for n, want := range tests {
+		if got := ordinal(n); got != want {
+			t.Errorf("ordinal(%d) = %q, want %q", n, got, want)
+		}
+	}
+}
+
+func TestParseListOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		want    listOptions
+		wantErr bool
+	}{
+		{"defaults", "", listOptions{page: 1, limit: DefaultPageLimit, sort: []string{"id"}}, false},
+		{"page and limit", "page=3&limit=25", listOptions{page: 3, limit: 25, sort: []string{"id"}}, false},
+		{"limit is capped", "limit=5000", listOptions{page: 1, limit: MaxPageLimit, sort: []string{"id"}}, false},
+		{"sort and fields are trimmed", "sort=-goals,%20name,&fields=id,%20name", listOptions{page: 1, limit: DefaultPageLimit,
+			sort: []string{"-goals", "name"}, fields: []string{"id", "name"}}, false},
+		{"cursor", "cursor=MTI", listOptions{page: 1, limit: DefaultPageLimit, cursor: "MTI", sort: []string{"id"}}, false},
+		{"zero page", "page=0", listOptions{}, true},
+		{"text page", "page=two", listOptions{}, true},
+		{"negative limit", "limit=-1", listOptions{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			options, err := parseListOptions(httptest.NewRequest("GET", "/items?"+tt.query, nil), "id")
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got %+v", options)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(*options, tt.want) {
+				t.Errorf("options = %+v, want %+v", *options, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteListResponse(t *testing.T) {
+	type stats struct {
+		Goals int `json:"goals"`
+	}
+	type item struct {
+		ID     int    `json:"id"`
+		Name   string `json:"name"`
+		Note   string `json:"note,omitempty"`
+		Hidden int    `json:"-"`
+		Stats  stats  `json:"stats"`
+	}
+	items := []*item{
+		{ID: 1, Name: "Cole", Stats: stats{Goals: 2}},
+		{ID: 2, Name: "adams", Note: "captain", Stats: stats{Goals: 5}},
+		{ID: 3, Name: "Baker", Stats: stats{Goals: 5}},
+	}
+
+	tests := []struct {
+		name      string
+		query     string
+		wantIDs   []float64
+		wantTotal float64
+		wantNext  bool
+		wantKeys  []string
+		wantCode  int
+	}{
+		{"default sort by id", "", []float64{1, 2, 3}, 3, false, []string{"id", "name", "note", "stats"}, 200},
+		{"descending with tie break", "sort=-stats.goals,name", []float64{2, 3, 1}, 3, false, nil, 200},
+		{"case-insensitive names", "sort=name", []float64{2, 3, 1}, 3, false, nil, 200},
+		{"missing values sort last", "sort=note", []float64{2, 1, 3}, 3, false, nil, 200},
+		{"second page", "limit=2&page=2", []float64{3}, 3, false, nil, 200},
+		{"first page has next", "limit=2", []float64{1, 2}, 3, true, nil, 200},
+		{"page past the end", "page=9", []float64{}, 3, false, nil, 200},
+		{"sparse fields", "fields=id,stats.goals", []float64{1, 2, 3}, 3, false, []string{"id", "stats"}, 200},
+		{"unknown sort field", "sort=rating", nil, 0, false, nil, 400},
+		{"bad cursor", "cursor=!!", nil, 0, false, nil, 400},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := httptest.NewRequest("GET", "/items?"+tt.query, nil)
+			options, err := parseListOptions(request, "id")
+			if err != nil {
+				t.Fatal(err)
+			}
+			recorder := httptest.NewRecorder()
+			writeListResponse(recorder, request, items, options, "id", nil)
+			if recorder.Code != tt.wantCode {
+				t.Fatalf("status = %d, want %d (%s)", recorder.Code, tt.wantCode, recorder.Body.String())
+			}
+			if tt.wantCode != 200 {
+				return
+			}
+
+			var response struct {
+				Data  []map[string]interface{} `json:"data"`
+				Meta  map[string]interface{}   `json:"meta"`
+				Links map[string]string        `json:"links"`
+			}
+			if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+				t.Fatal(err)
+			}
+			ids := []float64{}
+			for _, entry := range response.Data {
+				ids = append(ids, entry["id"].(float64))
+			}
+			if !reflect.DeepEqual(ids, tt.wantIDs) {
+				t.Errorf("ids = %v, want %v", ids, tt.wantIDs)
+			}
+			if response.Meta["total"] != tt.wantTotal {
+				t.Errorf("total = %v, want %v", response.Meta["total"], tt.wantTotal)
+			}
+			if _, hasNext := response.Links["next"]; hasNext != tt.wantNext {
+				t.Errorf("next link = %v, want %v", hasNext, tt.wantNext)
+			}
+			if tt.wantKeys != nil && len(response.Data) > 1 {
+				var keys []string
+				for key := range response.Data[1] {
+					keys = append(keys, key)
+				}
+				sort.Strings(keys)
+				if !reflect.DeepEqual(keys, tt.wantKeys) {
+					t.Errorf("keys = %v, want %v", keys, tt.wantKeys)
+				}
+			}
+		})
+	}
+}
+
+func TestWriteListResponseCursor(t *testing.T) {
+	items := []map[string]interface{}{{"id": 1}, {"id": 2}, {"id": 3}}
+	first := httptest.NewRequest("GET", "/items?limit=2", nil)
+	options, _ := parseListOptions(first, "-id")
+	recorder := httptest.NewRecorder()
+	writeListResponse(recorder, first, items, options, "id", nil)
+
+	var page struct {
+		Meta map[string]interface{} `json:"meta"`
+	}
+	json.Unmarshal(recorder.Body.Bytes(), &page)
+	cursor, _ := page.Meta["next_cursor"].(string)
+	if cursor == "" {
+		t.Fatal("expected a next cursor")
+	}
+
+	next := httptest.NewRequest("GET", "/items?limit=2&cursor="+cursor, nil)
+	options, _ = parseListOptions(next, "-id")
+	recorder = httptest.NewRecorder()
+	writeListResponse(recorder, next, items, options, "id", nil)
+	var rest struct {
+		Data []map[string]interface{} `json:"data"`
+	}
+	json.Unmarshal(recorder.Body.Bytes(), &rest)
+	if len(rest.Data) != 1 || rest.Data[0]["id"] != float64(1) {
+		t.Errorf("page after cursor = %v, want id 1", rest.Data)
+	}
+}
+
+func TestRecordPassCompletion(t *testing.T) {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	squad := getPlayersFromTeam(1)
+	if len(squad) < 3 {
+		t.Fatal("team 1 has too few players")
+	}
+	passer, target, receiver := squad[0], squad[1], squad[2]
+	const matchID = -1
+	defer delete(matchPasses, matchID)
+
+	edge := func(to *Player) PassEdge {
+		if e := matchPasses[matchID][passKey{from: passer.ID, to: to.ID}]; e != nil {
+			return *e
+		}
+		return PassEdge{}
+	}
+
+	tests := []struct {
+		name             string
+		attempt          bool
+		to               *Player
+		intended, actual [2]int // passes, completed
+	}{
+		{"reaches the intended teammate", true, target, [2]int{1, 1}, [2]int{1, 1}},
+		{"another teammate takes it", true, receiver, [2]int{0, 0}, [2]int{1, 1}},
+		{"attempt was never recorded", false, target, [2]int{1, 1}, [2]int{1, 1}},
+		{"attempt was never recorded, another teammate takes it", false, receiver, [2]int{0, 0}, [2]int{1, 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			delete(matchPasses, matchID)
+			if tt.attempt {
+				recordPassAttempt(matchID, passer, target)
+			}
+			recordPassCompletion(matchID, passer.ID, target.ID, tt.to.ID)
+
+			intended, actual := edge(target), edge(tt.to)
+			if got := [2]int{intended.Passes, intended.Completed}; got != tt.intended {
+				t.Errorf("intended edge = %v, want %v", got, tt.intended)
+			}
+			if got := [2]int{actual.Passes, actual.Completed}; got != tt.actual {
+				t.Errorf("actual edge = %v, want %v", got, tt.actual)
+			}
+		})
+	}
+}
+
+func TestResolveGoalChanceWind(t *testing.T) {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	var striker *Player
+	for _, player := range getPlayersFromTeam(1) {
+		if player.Position == PosST {
+			striker = player
+			break
+		}
+	}
+	if striker == nil {
+		t.Fatal("team 1 has no striker")
+	}
+
+	// A sandbox match, removed again before the engine can see it
+	const matchID = -2
+	match := &Match{ID: matchID, HomeTeam: *teams[1], AwayTeam: *teams[2], Status: StatusLive}
+	matches[matchID] = match
+	playerLocations[matchID] = map[int]*PlayerLocation{striker.ID: {PlayerID: striker.ID, X: 88, Y: FieldHeight / 2}}
+	defer func() {
+		delete(matches, matchID)
+		delete(playerLocations, matchID)
+	}()
+
+	tests := []struct {
+		name      string
+		possessor int
+	}{
+		{"shot by the player on the ball", striker.ID},
+		{"loose ball finished by the nearest attacker", 0},
+	}
+
+	const trials = 4000
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			goals := make(map[int]int)
+			for _, wind := range []int{0, int(MaxWindSpeed)} {
+				match.WindSpeed = wind
+				for i := 0; i < trials; i++ {
+					ball := &BallPosition{X: 88, Y: FieldHeight / 2, PossessorID: tt.possessor}
+					scorer, shooter, _ := resolveGoalChance(matchID, match, ball)
+					if scorer != nil && shooter != nil {
+						t.Fatal("a chance cannot be both scored and missed")
+					}
+					if scorer == nil && shooter == nil {
+						t.Fatal("the striker should always get the chance")
+					}
+					if scorer != nil {
+						goals[wind]++
+					}
+				}
+			}
+
+			calm, windy := goals[0], goals[int(MaxWindSpeed)]
+			if float64(windy) > 0.85*float64(calm) {
+				t.Errorf("goals in calm = %d, in strong wind = %d; want clearly fewer in the wind", calm, windy)
+			}
+		})
+	}
+}
+
+// hasNewsEntry reports whether an article is still published
+func hasNewsEntry(id int) bool {
+	for _, entry := range newsEntries {
+		if entry.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// sandboxVARMatch sets up a live match with stat lines for both sides and returns its cleanup
+func sandboxVARMatch(t *testing.T, matchID int) (*Match, func()) {
+	t.Helper()
+	var referee *Referee
+	for _, candidate := range referees {
+		referee = candidate
+		break
+	}
+	if referee == nil {
+		t.Fatal("no referees loaded")
+	}
+
+	match := &Match{
+		ID: matchID, HomeTeam: *teams[1], AwayTeam: *teams[2], Status: StatusLive, Minute: 60,
+		Referee: RefereeInfo{ID: referee.ID, Name: referee.Name},
+	}
+	matches[matchID] = match
+	matchStats[matchID] = &MatchStats{}
+	matchPlayerStats[matchID] = make(map[int]*MatchPlayerStats)
+
+	// Restore every player and the referee the match could touch
+	snapshot := make(map[int]Player)
+	for _, teamID := range []int{1, 2} {
+		for _, player := range getPlayersFromTeam(teamID) {
+			snapshot[player.ID] = *player
+			matchPlayerStats[matchID][player.ID] = &MatchPlayerStats{PlayerID: player.ID, TeamID: teamID}
+		}
+	}
+	refereeBefore := *referee
+	newsBefore := append([]*NewsEntry(nil), newsEntries...)
+
+	return match, func() {
+		for id, player := range snapshot {
+			*players[id] = player
+		}
+		*referee = refereeBefore
+		newsEntries = newsBefore
+		delete(matches, matchID)
+		delete(matchStats, matchID)
+		delete(matchPlayerStats, matchID)
+		delete(matchTimelines, matchID)
+		delete(matchScorers, matchID)
+		delete(fantasyMatchPoints, matchID)
+		delete(matchMomentum, matchID)
+		delete(dynamicProbabilities, matchID)
+		delete(ballPositions, matchID)
+		delete(liveCommentary, matchID)
+		delete(playerAvailability, matchID)
+		delete(playerLocations, matchID)
+	}
+}
+
+func TestOverturnGoal(t *testing.T) {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	var striker *Player
+	for _, player := range getPlayersFromTeam(1) {
+		if player.Position == PosST {
+			striker = player
+			break
+		}
+	}
+	if striker == nil {
+		t.Fatal("team 1 has no striker")
+	}
+
+	tests := []struct {
+		name     string
+		goals    int // Goals scored before the last one is overturned
+		hatTrick bool
+	}{
+		{"only goal of the match", 1, false},
+		{"second goal", 2, false},
+		{"hat-trick goal retracts the article", 3, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			const matchID = -3
+			match, cleanup := sandboxVARMatch(t, matchID)
+			defer cleanup()
+
+			goalsBefore, seasonBefore := striker.Goals, striker.SeasonStats.GoalsThisSeason
+			var last *TimelineEvent
+			for i := 0; i < tt.goals; i++ {
+				last = scoreGoal(matchID, match, striker, &BallPosition{X: 95, Y: FieldHeight / 2, PossessorID: striker.ID})
+			}
+			if (last.newsID != 0) != tt.hatTrick {
+				t.Fatalf("goal %d news = %d, want hat-trick article %v", tt.goals, last.newsID, tt.hatTrick)
+			}
+			newsID := last.newsID
+
+			overturnGoal(match, &VARReview{
+				Decision: VARDecisionGoal, TeamID: striker.TeamID, PlayerID: striker.ID, EventID: last.ID,
+			})
+
+			standing := tt.goals - 1
+			if match.HomeScore != standing {
+				t.Errorf("home score = %d, want %d", match.HomeScore, standing)
+			}
+			if got := striker.Goals - goalsBefore; got != standing {
+				t.Errorf("career goals added = %d, want %d", got, standing)
+			}
+			if got := striker.SeasonStats.GoalsThisSeason - seasonBefore; got != standing {
+				t.Errorf("season goals added = %d, want %d", got, standing)
+			}
+			if got := matchPlayerStats[matchID][striker.ID].Goals; got != standing {
+				t.Errorf("stat line goals = %d, want %d", got, standing)
+			}
+			if got := matchScorers[matchID][striker.ID]; got != standing {
+				t.Errorf("match scorers = %d, want %d", got, standing)
+			}
+			if want := standing * fantasyEventPoints(striker, "goal"); fantasyMatchPoints[matchID][striker.ID] != want {
+				t.Errorf("fantasy points = %d, want %d", fantasyMatchPoints[matchID][striker.ID], want)
+			}
+			if tt.hatTrick && hasNewsEntry(newsID) {
+				t.Error("hat-trick article survived the overturned goal")
+			}
+		})
+	}
+}
+
+func TestOverturnRedCard(t *testing.T) {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	const matchID = -3
+	match, cleanup := sandboxVARMatch(t, matchID)
+	defer cleanup()
+
+	player := getPlayersFromTeam(2)[0]
+	referee := matchReferee(match)
+	redCardsBefore, refereeRedsBefore := player.RedCards, referee.RedCards
+
+	// The bookkeeping handleCardEvent does for a red card
+	player.RedCards++
+	player.SeasonStats.RedCardsThisSeason++
+	recordCard(matchID, match, player, "red")
+	recordFantasyEvent(matchID, player, "red")
+	setPlayerUnavailable(matchID, player.ID, PlayerRedCard, match.Minute, "Violent conduct")
+	matchStats[matchID].AwayRedCards++
+	news := generateRedCardNews(matchID, match, player)
+	event := &TimelineEvent{Type: EventCard, TeamID: player.TeamID, PlayerID: player.ID, Card: "red", newsID: news.ID}
+	recordTimelineEvent(match, event)
+
+	overturnRedCard(match, &VARReview{
+		Decision: VARDecisionRedCard, TeamID: player.TeamID, PlayerID: player.ID, EventID: event.ID,
+	})
+
+	tests := []struct {
+		name      string
+		got, want int
+	}{
+		{"player red cards", player.RedCards, redCardsBefore},
+		{"referee red cards", referee.RedCards, refereeRedsBefore},
+		{"stat line red cards", matchPlayerStats[matchID][player.ID].RedCards, 0},
+		{"stat line sent off minute", matchPlayerStats[matchID][player.ID].SentOffMinute, 0},
+		{"team red cards", matchStats[matchID].AwayRedCards, 0},
+		{"fantasy points", fantasyMatchPoints[matchID][player.ID], 0},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s = %d, want %d", tt.name, tt.got, tt.want)
+		}
+	}
+	if hasNewsEntry(news.ID) {
+		t.Error("red card article survived the overturned decision")
+	}
+	if _, unavailable := playerAvailability[matchID][player.ID]; unavailable {
+		t.Error("player is still marked as sent off")
+	}
+}
+
+func TestEloWinExpectancy(t *testing.T) {
+	tests := []struct {
+		name string
+		diff float64
+		want float64
+	}{
+		{"evenly rated", 0, 0.5},
+		{"200 points stronger", 200, 1 / (1 + math.Pow(10, -0.5))},
+		{"400 points stronger", 400, 10.0 / 11},
+		{"400 points weaker", -400, 1.0 / 11},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := eloWinExpectancy(tt.diff); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("eloWinExpectancy(%v) = %v, want %v", tt.diff, got, tt.want)
+			}
+		})
+	}
+
+	// Rating updates include home advantage
+	if got, want := eloExpected(1500, 1500), eloWinExpectancy(EloHomeAdvantage); got != want {
+		t.Errorf("eloExpected(1500, 1500) = %v, want %v", got, want)
+	}
+}
+
+func TestEloMarginMultiplier(t *testing.T) {
+	tests := []struct {
+		margin int
+		want   float64
+	}{
+		{0, 1},
+		{1, 1},
+		{2, 1.5},
+		{3, 1.75},
+		{4, 1.875},
+		{6, 2.125},
+	}
+	for _, tt := range tests {
+		if got := eloMarginMultiplier(tt.margin); got != tt.want {
+			t.Errorf("eloMarginMultiplier(%d) = %v, want %v", tt.margin, got, tt.want)
+		}
+	}
+}
+
+func TestOutcomeProbabilities(t *testing.T) {
+	tests := []struct {
+		name            string
+		expected        float64
+		win, draw, loss float64
+	}{
+		{"evenly matched", 0.5, 0.35, 0.3, 0.35},
+		{"certain winner", 1, 1, 0, 0},
+		{"certain loser", 0, 0, 0, 1},
+		{"400 Elo points stronger", 10.0 / 11, 10.0/11 - 0.3/11, 0.6 / 11, 1.0/11 - 0.3/11},
+		{"out of range is clamped", 1.2, 1, 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			win, draw, loss := outcomeProbabilities(tt.expected)
+			if math.Abs(win-tt.win) > 1e-9 || math.Abs(draw-tt.draw) > 1e-9 || math.Abs(loss-tt.loss) > 1e-9 {
+				t.Errorf("outcomeProbabilities(%v) = %v/%v/%v, want %v/%v/%v", tt.expected, win, draw, loss, tt.win, tt.draw, tt.loss)
+			}
+			// The split keeps the expected score it came from
+			if score := win + draw/2; math.Abs(score-math.Min(tt.expected, 1)) > 1e-9 {
+				t.Errorf("expected score = %v, want %v", score, tt.expected)
+			}
+		})
+	}
+}
+
+func TestCalculateMatchProbabilities(t *testing.T) {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	home, away := teams[1], teams[2]
+	homeRating, awayRating := eloRatings[home.ID], eloRatings[away.ID]
+	defer func() {
+		eloRatings[home.ID], eloRatings[away.ID] = homeRating, awayRating
+	}()
+
+	tests := []struct {
+		name    string
+		homeElo float64
+		awayElo float64
+		crowd   float64
+	}{
+		{"even ratings, empty stadium", 1500, 1500, 0},
+		{"even ratings, full crowd", 1500, 1500, 1},
+		{"much weaker home side", 1300, 1700, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			eloRatings[home.ID] = &EloRating{TeamID: home.ID, Rating: tt.homeElo}
+			eloRatings[away.ID] = &EloRating{TeamID: away.ID, Rating: tt.awayElo}
+
+			homeWin, draw, awayWin := calculateMatchProbabilities(0, home, away, tt.crowd)
+			if math.Abs(homeWin+draw+awayWin-1) > 1e-9 {
+				t.Errorf("probabilities sum to %v", homeWin+draw+awayWin)
+			}
+
+			// Rebuild the price from its parts: home advantage is applied exactly once
+			homeStrength, awayStrength := calculateTeamStrength(0, home), calculateTeamStrength(0, away)
+			expected := (1-EloProbabilityWeight)*homeStrength/(homeStrength+awayStrength) +
+				EloProbabilityWeight*eloWinExpectancy(tt.homeElo-tt.awayElo)
+			wantHome, wantDraw, wantAway := outcomeProbabilities(withHomeAdvantage(expected, homeAdvantage(tt.crowd)))
+			if math.Abs(homeWin-wantHome) > 1e-9 || math.Abs(draw-wantDraw) > 1e-9 || math.Abs(awayWin-wantAway) > 1e-9 {
+				t.Errorf("got %v/%v/%v, want %v/%v/%v", homeWin, draw, awayWin, wantHome, wantDraw, wantAway)
+			}
+		})
+	}
+}
+
+func TestWithHomeAdvantage(t *testing.T) {
+	tests := []struct {
+		expected, advantage, want float64
+	}{
+		{0.5, 1, 0.5},
+		{0.5, 1.16, 1.16 / 2.16},
+		{0.25, 1.5, 0.375 / 1.125},
+		{1, 1.16, 1},
+		{0, 1.16, 0},
+	}
+	for _, tt := range tests {
+		if got := withHomeAdvantage(tt.expected, tt.advantage); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("withHomeAdvantage(%v, %v) = %v, want %v", tt.expected, tt.advantage, got, tt.want)
+		}
+	}
+}
+
+// recordValues lists match IDs and values in record order, e.g. "1:3"
+func recordValues(list []MatchRecord) []string {
+	values := make([]string, len(list))
+	for i, record := range list {
+		values[i] = strconv.Itoa(record.MatchID) + ":" + strconv.Itoa(record.Value)
+	}
+	return values
+}
+
+func TestInsertMatchRecord(t *testing.T) {
+	full := make([]MatchRecord, MaxRecordEntries)
+	for i := range full {
+		full[i] = MatchRecord{MatchID: 100 + i, Value: 10 - i}
+	}
+
+	tests := []struct {
+		name   string
+		list   []MatchRecord
+		record MatchRecord
+		want   []string
+		broken bool
+	}{
+		{"first entry is not a broken record", nil, MatchRecord{MatchID: 1, Value: 3}, []string{"1:3"}, false},
+		{"new best", []MatchRecord{{MatchID: 1, Value: 3}}, MatchRecord{MatchID: 2, Value: 4}, []string{"2:4", "1:3"}, true},
+		{"tie keeps the older record ahead", []MatchRecord{{MatchID: 1, Value: 3}}, MatchRecord{MatchID: 2, Value: 3}, []string{"1:3", "2:3"}, false},
+		{"goal in the leading match is not announced again",
+			[]MatchRecord{{MatchID: 1, Value: 4}, {MatchID: 2, Value: 3}}, MatchRecord{MatchID: 1, Value: 5}, []string{"1:5", "2:3"}, false},
+		{"live match replaces its earlier entry",
+			[]MatchRecord{{MatchID: 2, Value: 4}, {MatchID: 1, Value: 3}}, MatchRecord{MatchID: 1, Value: 5}, []string{"1:5", "2:4"}, true},
+		{"shrinking margin moves the match down",
+			[]MatchRecord{{MatchID: 1, Value: 4}, {MatchID: 2, Value: 3}}, MatchRecord{MatchID: 1, Value: 2}, []string{"2:3", "1:2"}, false},
+		{"level score removes the match",
+			[]MatchRecord{{MatchID: 1, Value: 1}, {MatchID: 2, Value: 3}}, MatchRecord{MatchID: 1, Value: 0}, []string{"2:3"}, false},
+		{"full list drops the lowest", full, MatchRecord{MatchID: 1, Value: 5},
+			[]string{"100:10", "101:9", "102:8", "103:7", "104:6", "105:5", "1:5", "106:4", "107:3", "108:2"}, false},
+		{"full list ignores a lower value", full, MatchRecord{MatchID: 1, Value: 1},
+			[]string{"100:10", "101:9", "102:8", "103:7", "104:6", "105:5", "106:4", "107:3", "108:2", "109:1"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			list, broken := insertMatchRecord(append([]MatchRecord(nil), tt.list...), tt.record)
+			if got := recordValues(list); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("records = %v, want %v", got, tt.want)
+			}
+			if broken != tt.broken {
+				t.Errorf("broken = %v, want %v", broken, tt.broken)
+			}
+		})
+	}
+}
+
+func TestRecordStreak(t *testing.T) {
+	team := &TeamInfo{ID: 1, Name: "Home FC"}
+	other := StreakRecord{TeamID: 2, Length: 5, StartMatchID: 10}
+
+	tests := []struct {
+		name         string
+		list         []StreakRecord
+		length       int
+		startMatchID int
+		want         []int // Lengths in record order
+		broken       bool
+	}{
+		{"too short to count", nil, MinRecordStreak - 1, 20, nil, false},
+		{"first run is not a broken record", nil, MinRecordStreak, 20, []int{MinRecordStreak}, false},
+		{"shorter run ranks below", []StreakRecord{other}, 4, 20, []int{5, 4}, false},
+		{"equalling the record is not breaking it", []StreakRecord{other}, 5, 20, []int{5, 5}, false},
+		{"passing the record", []StreakRecord{other, {TeamID: 1, Length: 5, StartMatchID: 20}}, 6, 20, []int{6, 5}, true},
+		{"record holder extending its own run", []StreakRecord{{TeamID: 1, Length: 6, StartMatchID: 20}, other}, 7, 20, []int{7, 5}, false},
+		{"a new run of the same team is a separate entry", []StreakRecord{{TeamID: 1, Length: 4, StartMatchID: 5}}, 3, 20, []int{4, 3}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			list, broken := recordStreak(append([]StreakRecord(nil), tt.list...), team, tt.length, tt.startMatchID, 1, 30)
+			var lengths []int
+			for _, record := range list {
+				lengths = append(lengths, record.Length)
+			}
+			if !reflect.DeepEqual(lengths, tt.want) {
+				t.Errorf("lengths = %v, want %v", lengths, tt.want)
+			}
+			if broken != tt.broken {
+				t.Errorf("broken = %v, want %v", broken, tt.broken)
+			}
+		})
+	}
+}
+
+func TestGoalInterval(t *testing.T) {
+	tests := []struct {
+		minute int
+		want   int
+	}{
+		{0, 0},
+		{1, 0},
+		{15, 0},
+		{16, 1},
+		{30, 1},
+		{31, 2},
+		{45, 2},
+		{46, 3},
+		{60, 3},
+		{61, 4},
+		{75, 4},
+		{76, 5},
+		{90, 5},
+		{94, 5}, // Stoppage time counts in the last window
+	}
+	for _, tt := range tests {
+		if got := goalInterval(tt.minute); got != tt.want {
+			t.Errorf("goalInterval(%d) = %d, want %d", tt.minute, got, tt.want)
+		}
+	}
+}
+
+// fantasyForwards returns two forwards of a team for fantasy squads
+func fantasyForwards(t *testing.T, teamID int) (*Player, *Player) {
+	t.Helper()
+	var forwards []*Player
+	for _, player := range getPlayersFromTeam(teamID) {
+		if fantasyPositionGroup(player.Position) == "FWD" {
+			forwards = append(forwards, player)
+		}
+	}
+	if len(forwards) < 2 {
+		t.Fatalf("team %d has %d forwards, need 2", teamID, len(forwards))
+	}
+	return forwards[0], forwards[1]
+}
+
+func TestSetFantasyCaptainLockedDuringMatch(t *testing.T) {
+	mutex.Lock()
+	captain, other := fantasyForwards(t, 1)
+	const teamID, matchID = -1, -4
+	team := &FantasyTeam{ID: teamID, PlayerIDs: []int{captain.ID, other.ID}, CaptainID: captain.ID}
+	fantasyTeams[teamID] = team
+	matches[matchID] = &Match{ID: matchID, HomeTeam: *teams[1], AwayTeam: *teams[2], Status: StatusLive}
+	mutex.Unlock()
+	defer func() {
+		mutex.Lock()
+		delete(fantasyTeams, teamID)
+		delete(matches, matchID)
+		mutex.Unlock()
+	}()
+
+	request := httptest.NewRequest("PUT", "/api/v1/fantasy/teams/-1/captain", strings.NewReader(`{"captain_id": `+strconv.Itoa(other.ID)+`}`))
+	request = mux.SetURLVars(request, map[string]string{"id": strconv.Itoa(teamID)})
+	recorder := httptest.NewRecorder()
+	setFantasyCaptain(recorder, request)
+
+	if recorder.Code != http.StatusConflict {
+		t.Errorf("status = %d, want %d", recorder.Code, http.StatusConflict)
+	}
+	mutex.RLock()
+	defer mutex.RUnlock()
+	if team.CaptainID != captain.ID {
+		t.Errorf("captain = %d, want %d unchanged", team.CaptainID, captain.ID)
+	}
+}
+
+func TestSettleFantasyMatchCaptain(t *testing.T) {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	first, second := fantasyForwards(t, 1)
+	const matchID, gameweekNum = -5, 1
+
+	tests := []struct {
+		name        string
+		settled     bool // The gameweek captain was credited before the change
+		wantCaptain *Player
+	}{
+		{"change before the captain plays moves the gameweek captain", false, second},
+		{"change after the captain played applies next gameweek", true, first},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			savedTeams, savedWeek := fantasyTeams, currentMatchweek
+			team := &FantasyTeam{ID: 1, PlayerIDs: []int{first.ID, second.ID}, CaptainID: first.ID}
+			fantasyTeams = map[int]*FantasyTeam{team.ID: team}
+			currentMatchweek = gameweekNum
+			match := &Match{ID: matchID, Season: currentSeason, MatchweekNum: gameweekNum, HomeTeam: *teams[1], AwayTeam: *teams[2], AwayScore: 1}
+			defer func() {
+				fantasyTeams, currentMatchweek = savedTeams, savedWeek
+				delete(fantasyMatchPoints, matchID)
+				delete(matchPlayerStats, matchID)
+			}()
+
+			gameweek := getFantasyGameweek(team, currentSeason, gameweekNum)
+			if tt.settled {
+				gameweek.PlayerPoints[first.ID] = 0
+			}
+			changeFantasyCaptain(team, second.ID)
+			if gameweek.CaptainID != tt.wantCaptain.ID {
+				t.Fatalf("gameweek captain = %d, want %d", gameweek.CaptainID, tt.wantCaptain.ID)
+			}
+
+			// Both forwards score once: 4 for the goal and 2 for appearing
+			matchPlayerStats[matchID] = map[int]*MatchPlayerStats{first.ID: {PlayerID: first.ID}, second.ID: {PlayerID: second.ID}}
+			fantasyMatchPoints[matchID] = map[int]int{first.ID: 4, second.ID: 4}
+			settleFantasyMatch(matchID, match)
+
+			for _, player := range []*Player{first, second} {
+				want := 6
+				if player == tt.wantCaptain {
+					want = 12
+				}
+				if got := gameweek.PlayerPoints[player.ID]; got != want {
+					t.Errorf("player %d points = %d, want %d", player.ID, got, want)
+				}
+			}
+			if team.TotalPoints != 18 {
+				t.Errorf("total points = %d, want 18", team.TotalPoints)
+			}
+		})
+	}
+}
+
+func TestAwardPrizeMoney(t *testing.T) {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	savedTables, savedFinances := leagueTables, clubFinances
+	defer func() {
+		leagueTables, clubFinances = savedTables, savedFinances
+	}()
+
+	// Three clubs share the pool 3:2:1
+	table := []*LeagueTable{{Team: *teams[1], Position: 1}, {Team: *teams[2], Position: 2}, {Team: *teams[3], Position: 3}}
+	leagueTables = map[string][]*LeagueTable{LeaguePremier: table}
+	clubFinances = make(map[int]*ClubFinances)
+	for _, entry := range table {
+		clubFinances[entry.Team.ID] = &ClubFinances{TeamID: entry.Team.ID, Balance: 100}
+	}
+	awardPrizeMoney()
+
+	tests := []struct {
+		teamID      int
+		prize       float64
+		description string
+	}{
+		{1, 200, "Prize money for finishing 1st in the Premier League"},
+		{2, 133.33, "Prize money for finishing 2nd in the Premier League"},
+		{3, 66.67, "Prize money for finishing 3rd in the Premier League"},
+	}
+	for _, tt := range tests {
+		finances := clubFinances[tt.teamID]
+		if got := finances.Balance - 100; math.Abs(got-tt.prize) > 1e-9 {
+			t.Errorf("team %d balance change = %v, want %v", tt.teamID, got, tt.prize)
+		}
+		if len(finances.Ledger) != 1 || finances.Ledger[0].Description != tt.description {
+			t.Errorf("team %d ledger = %+v, want one entry %q", tt.teamID, finances.Ledger, tt.description)
+		} else if finances.Ledger[0].Type != FinancePrizeMoney {
+			t.Errorf("team %d transaction type = %q, want %q", tt.teamID, finances.Ledger[0].Type, FinancePrizeMoney)
+		}
+		if got := getSeasonFinance(finances, currentSeason).PrizeMoney; math.Abs(got-tt.prize) > 1e-9 {
+			t.Errorf("team %d season prize money = %v, want %v", tt.teamID, got, tt.prize)
+		}
+	}
+}
+
+func TestGetMatchCommentarySince(t *testing.T) {
+	const matchID = -6
+	mutex.Lock()
+	matches[matchID] = &Match{ID: matchID, HomeTeam: *teams[1], AwayTeam: *teams[2], Status: StatusLive}
+	for id := 1; id <= 10; id++ {
+		liveCommentary[matchID] = append(liveCommentary[matchID], &LiveCommentary{ID: id, MatchID: matchID, EventType: EventCommentary})
+	}
+	mutex.Unlock()
+	defer func() {
+		mutex.Lock()
+		delete(matches, matchID)
+		delete(liveCommentary, matchID)
+		mutex.Unlock()
+	}()
+
+	tests := []struct {
+		name    string
+		query   string
+		want    []int
+		hasMore bool
+	}{
+		{"newest first by default", "limit=3", []int{10, 9, 8}, true},
+		{"oldest first", "limit=3&order=asc", []int{1, 2, 3}, true},
+		{"since pages forward from the given ID", "since=3&limit=4", []int{4, 5, 6, 7}, true},
+		{"since with desc lists the same page newest first", "since=3&limit=4&order=desc", []int{7, 6, 5, 4}, true},
+		{"last page after since", "since=7&limit=4", []int{8, 9, 10}, false},
+		{"nothing after the latest entry", "since=10", []int{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := httptest.NewRequest("GET", "/api/v1/matches/-6/commentary?"+tt.query, nil)
+			request = mux.SetURLVars(request, map[string]string{"id": strconv.Itoa(matchID)})
+			recorder := httptest.NewRecorder()
+			getMatchCommentary(recorder, request)
+			if recorder.Code != http.StatusOK {
+				t.Fatalf("status = %d: %s", recorder.Code, recorder.Body.String())
+			}
+
+			var body struct {
+				Commentary []struct {
+					ID int `json:"id"`
+				} `json:"commentary"`
+				HasMore  bool `json:"has_more"`
+				LatestID int  `json:"latest_id"`
+			}
+			if err := json.Unmarshal(recorder.Body.Bytes(), &body); err != nil {
+				t.Fatal(err)
+			}
+			ids := []int{}
+			for _, entry := range body.Commentary {
+				ids = append(ids, entry.ID)
+			}
+			if !reflect.DeepEqual(ids, tt.want) {
+				t.Errorf("ids = %v, want %v", ids, tt.want)
+			}
+			if body.HasMore != tt.hasMore {
+				t.Errorf("has_more = %v, want %v", body.HasMore, tt.hasMore)
+			}
+			if body.LatestID != 10 {
+				t.Errorf("latest_id = %d, want 10", body.LatestID)
+			}
+		})
+	}
+}
+
+func TestGetSeason(t *testing.T) {
+	const archived = -1
+	mutex.Lock()
+	seasonArchives[archived] = &SeasonArchive{
+		Standings: map[string][]LeagueTable{
+			LeaguePremier:         {{Position: 1, Team: *teams[1]}},
+			LeagueCommunityLeague: {{Position: 1, Team: *teams[3]}},
+		},
+		Results: []SeasonResult{
+			{MatchID: 1, League: LeaguePremier, HomeTeamID: 1, AwayTeamID: 2},
+			{MatchID: 2, League: LeagueCommunityLeague, HomeTeamID: 3, AwayTeamID: 4},
+			{MatchID: 3, League: LeaguePremier, HomeTeamID: 2, AwayTeamID: 1},
+		},
+	}
+	current := currentSeason
+	mutex.Unlock()
+	defer func() {
+		mutex.Lock()
+		delete(seasonArchives, archived)
+		mutex.Unlock()
+	}()
+
+	tests := []struct {
+		name      string
+		season    int
+		league    string
+		status    int
+		error     string
+		leagues   []string
+		resultIDs []int
+	}{
+		{"unknown season", 9999, "", http.StatusNotFound, "Season not found", nil, nil},
+		{"current season is not archived yet", current, "", http.StatusNotFound, "Season still in progress", nil, nil},
+		{"archived season, every league", archived, "", http.StatusOK, "",
+			[]string{LeagueCommunityLeague, LeaguePremier}, []int{1, 2, 3}},
+		{"league filter", archived, LeaguePremier, http.StatusOK, "", []string{LeaguePremier}, []int{1, 3}},
+		{"unknown league", archived, "Sunday League", http.StatusNotFound, "League not found", nil, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target := "/api/v1/seasons/" + strconv.Itoa(tt.season)
+			if tt.league != "" {
+				target += "?" + url.Values{"league": {tt.league}}.Encode()
+			}
+			request := mux.SetURLVars(httptest.NewRequest("GET", target, nil), map[string]string{"season": strconv.Itoa(tt.season)})
+			recorder := httptest.NewRecorder()
+			getSeason(recorder, request)
+
+			if recorder.Code != tt.status {
+				t.Fatalf("status = %d, want %d", recorder.Code, tt.status)
+			}
+			if tt.status != http.StatusOK {
+				if got := strings.TrimSpace(recorder.Body.String()); got != tt.error {
+					t.Errorf("error = %q, want %q", got, tt.error)
+				}
+				return
+			}
+
+			var body struct {
+				Standings map[string][]LeagueTable `json:"standings"`
+				Results   []SeasonResult           `json:"results"`
+				Count     int                      `json:"count"`
+			}
+			if err := json.Unmarshal(recorder.Body.Bytes(), &body); err != nil {
+				t.Fatal(err)
+			}
+			leagues := make([]string, 0, len(body.Standings))
+			for league := range body.Standings {
+				leagues = append(leagues, league)
+			}
+			sort.Strings(leagues)
+			if !reflect.DeepEqual(leagues, tt.leagues) {
+				t.Errorf("standings leagues = %v, want %v", leagues, tt.leagues)
+			}
+			ids := make([]int, 0, len(body.Results))
+			for _, result := range body.Results {
+				ids = append(ids, result.MatchID)
+			}
+			if !reflect.DeepEqual(ids, tt.resultIDs) || body.Count != len(tt.resultIDs) {
+				t.Errorf("results = %v (count %d), want %v", ids, body.Count, tt.resultIDs)
+			}
+		})
+	}
+}
+
+func TestSeasonAwardsWithoutQualifiers(t *testing.T) {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	// A squad that has not played: nobody has scored, assisted, been booked or played 10 matches
+	saved := players
+	defer func() { players = saved }()
+	players = map[int]*Player{1: {ID: 1, Name: "Unused"}}
+
+	tests := []struct {
+		name   string
+		winner *Player
+	}{
+		{"top scorer", findTopScorer()},
+		{"top assists", findTopAssists()},
+		{"most fouls", findMostFouls()},
+		{"player of the season", findPlayerOfSeason()},
+	}
+	for _, tt := range tests {
+		if tt.winner != nil {
+			t.Errorf("%s = %s, want nobody", tt.name, tt.winner.Name)
+		}
+	}
+	if got := playerOrEmpty(nil); got.ID != 0 || got.Name != "" {
+		t.Errorf("playerOrEmpty(nil) = %+v, want an empty player", got)
+	}
+}
+
+func TestPredictionLifecycle(t *testing.T) {
+	const league, fixtureID, matchID = "Prediction Test League", -1, -7
+	mutex.Lock()
+	fixture := &SeasonSchedule{ID: fixtureID, Matchday: 1, League: league, HomeTeam: teams[1], AwayTeam: teams[2]}
+	seasonSchedules[league] = []*SeasonSchedule{fixture}
+	mutex.Unlock()
+	defer func() {
+		mutex.Lock()
+		delete(seasonSchedules, league)
+		delete(predictions, fixtureID)
+		mutex.Unlock()
+	}()
+
+	submit := func(user string, home, away int) *httptest.ResponseRecorder {
+		body := fmt.Sprintf(`{"user":%q,"fixture_id":%d,"home_score":%d,"away_score":%d}`, user, fixtureID, home, away)
+		recorder := httptest.NewRecorder()
+		submitPrediction(recorder, httptest.NewRequest("POST", "/api/v1/predictions", strings.NewReader(body)))
+		return recorder
+	}
+
+	if code := submit("ann", 1, 1).Code; code != http.StatusCreated {
+		t.Fatalf("first prediction status = %d, want %d", code, http.StatusCreated)
+	}
+	// Predicting again before kickoff replaces the score
+	if code := submit("ann", 2, 1).Code; code != http.StatusOK {
+		t.Fatalf("updated prediction status = %d, want %d", code, http.StatusOK)
+	}
+	submit("bob", 1, 0)
+	submit("cat", 0, 2)
+	if code := submit("dan", MaxPredictionScore+1, 0).Code; code != http.StatusBadRequest {
+		t.Errorf("out of range score status = %d, want %d", code, http.StatusBadRequest)
+	}
+
+	mutex.Lock()
+	fixture.IsPlayed = true
+	lockPredictions(fixture, matchID)
+	for user, prediction := range predictions[fixtureID] {
+		if prediction.Status != PredictionLocked || prediction.MatchID != matchID {
+			t.Errorf("%s at kickoff = %s for match %d, want %s for match %d", user, prediction.Status, prediction.MatchID, PredictionLocked, matchID)
+		}
+	}
+	mutex.Unlock()
+
+	if code := submit("eve", 0, 0).Code; code != http.StatusConflict {
+		t.Errorf("prediction after kickoff status = %d, want %d", code, http.StatusConflict)
+	}
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	if _, ok := predictions[fixtureID]["eve"]; ok {
+		t.Error("a prediction made after kickoff was stored")
+	}
+	fixture.MatchID = matchID
+	scorePredictions(&Match{ID: matchID, Competition: league, HomeScore: 2, AwayScore: 1})
+
+	tests := []struct {
+		user    string
+		outcome string
+		points  int
+	}{
+		{"ann", "EXACT", PredictionExactPoints},
+		{"bob", "RESULT", PredictionResultPoints},
+		{"cat", "MISS", 0},
+	}
+	for _, tt := range tests {
+		prediction := predictions[fixtureID][tt.user]
+		if prediction.Status != PredictionScored || prediction.Outcome != tt.outcome || prediction.Points != tt.points {
+			t.Errorf("%s = %s %s for %d points, want %s %s for %d", tt.user, prediction.Status, prediction.Outcome,
+				prediction.Points, PredictionScored, tt.outcome, tt.points)
+		}
+	}
+}
+
+func TestCalculateDevelopmentChange(t *testing.T) {
+	tests := []struct {
+		name          string
+		age           int
+		playingTime   float64
+		averageRating float64
+		low, high     int
+	}{
+		{"youngster playing every minute", 19, 1, 0, 2, 5},
+		{"youngster left on the bench", 19, 0, 0, 1, 2},
+		{"prime age", 27, 1, 0, -1, 1},
+		{"prime age in form", 27, 1, 7.5, 0, 2},
+		{"prime age out of form", 27, 1, 5.5, -2, 0},
+		{"veteran", 34, 1, 0, -5, -2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			player := &Player{Age: tt.age}
+			if tt.averageRating > 0 {
+				player.SeasonStats = PlayerSeasonStats{MatchesPlayed: 30, AverageRating: tt.averageRating}
+			}
+			for i := 0; i < 200; i++ {
+				if change := calculateDevelopmentChange(player, tt.playingTime); change < tt.low || change > tt.high {
+					t.Fatalf("change = %d, want %d to %d", change, tt.low, tt.high)
+				}
+			}
+		})
+	}
+}
+
+func TestDevelopPlayers(t *testing.T) {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	savedPlayers, savedRetired, savedFantasy, savedCounter := players, retiredPlayers, fantasyTeams, playerCounter
+	defer func() {
+		players, retiredPlayers, fantasyTeams, playerCounter = savedPlayers, savedRetired, savedFantasy, savedCounter
+	}()
+
+	characteristics := PlayerCharacteristics{Speed: 70, Shooting: 70, Passing: 70, Defending: 70, Physicality: 70, Mentality: 70, Overall: 70}
+	youngster := &Player{ID: 1, Name: "Youngster", Position: "ST", Number: 9, Age: 20, TeamID: 1, Characteristics: characteristics}
+	veteran := &Player{ID: 2, Name: "Veteran", Position: "CB", Number: 4, Age: MaxPlayerAge - 1, TeamID: 1, Characteristics: characteristics}
+	players = map[int]*Player{youngster.ID: youngster, veteran.ID: veteran}
+	retiredPlayers = make(map[int]*Player)
+	fantasyTeams = make(map[int]*FantasyTeam)
+	playerCounter = 2
+
+	developPlayers()
+
+	if youngster.Age != 21 || len(youngster.Development) != 1 {
+		t.Fatalf("youngster is %d with %d development seasons, want 21 with 1", youngster.Age, len(youngster.Development))
+	}
+	if record := youngster.Development[0]; record.Season != currentSeason || record.Age != 21 ||
+		record.Overall != youngster.Characteristics.Overall || record.Change != record.Overall-70 {
+		t.Errorf("development record = %+v, want season %d at 21 with overall %d", record, currentSeason, youngster.Characteristics.Overall)
+	}
+	if record := youngster.Development[0]; record.MarketValue != youngster.MarketValue || youngster.MarketValue == 0 {
+		t.Errorf("recorded market value = %d, want the revalued %d", record.MarketValue, youngster.MarketValue)
+	}
+
+	// Reaching MaxPlayerAge always retires a player, and an academy graduate takes the shirt
+	if _, ok := players[veteran.ID]; ok || retiredPlayers[veteran.ID] != veteran {
+		t.Fatal("veteran at the maximum age was not retired")
+	}
+	if veteran.TeamID != 0 || veteran.RetiredSeason != currentSeason {
+		t.Errorf("retired veteran has team %d and season %d, want 0 and %d", veteran.TeamID, veteran.RetiredSeason, currentSeason)
+	}
+	youth := players[playerCounter]
+	if youth == nil || len(players) != 2 {
+		t.Fatalf("squad has %d players, want the veteran replaced by one youth player", len(players))
+	}
+	if youth.TeamID != 1 || youth.Position != "CB" || youth.Number != 4 || youth.Age < 17 || youth.Age > 19 {
+		t.Errorf("youth player = team %d, %s #%d aged %d, want team 1, CB #4 aged 17-19", youth.TeamID, youth.Position, youth.Number, youth.Age)
+	}
+}
+
+func TestFinishedMatchEndpoints(t *testing.T) {
+	const matchID = -8
+	mutex.Lock()
+	finishedMatches[matchID] = &Match{ID: matchID, HomeTeam: *teams[1], AwayTeam: *teams[2], HomeScore: 2, AwayScore: 1, Status: StatusFinished}
+	mutex.Unlock()
+	defer func() {
+		mutex.Lock()
+		delete(finishedMatches, matchID)
+		mutex.Unlock()
+	}()
+
+	handlers := []struct {
+		name    string
+		handler http.HandlerFunc
+	}{
+		{"match", getMatch},
+		{"commentary", getMatchCommentary},
+		{"locations", getMatchLocations},
+		{"probabilities", getMatchProbabilities},
+		{"availability", getMatchAvailability},
+	}
+	for _, h := range handlers {
+		t.Run(h.name, func(t *testing.T) {
+			for _, tt := range []struct {
+				id     int
+				status int
+			}{
+				{matchID, http.StatusOK},
+				{matchID - 1000, http.StatusNotFound},
+			} {
+				request := mux.SetURLVars(httptest.NewRequest("GET", "/api/v1/matches/"+strconv.Itoa(tt.id), nil), map[string]string{"id": strconv.Itoa(tt.id)})
+				recorder := httptest.NewRecorder()
+				h.handler(recorder, request)
+				if recorder.Code != tt.status {
+					t.Errorf("match %d status = %d, want %d", tt.id, recorder.Code, tt.status)
+					continue
+				}
+				if tt.status != http.StatusOK {
+					continue
+				}
+				var body map[string]interface{}
+				if err := json.Unmarshal(recorder.Body.Bytes(), &body); err != nil {
+					t.Fatal(err)
+				}
+				if status, ok := body["status"]; ok && status != StatusFinished {
+					t.Errorf("match status = %v, want %s", status, StatusFinished)
+				}
+			}
+		})
+	}
+}
+
+func TestCalculateMatchRating(t *testing.T) {
+	home, away := *teams[1], *teams[2]
+	tests := []struct {
+		name      string
+		line      MatchPlayerStats
+		homeScore int
+		awayScore int
+		want      float64
+	}{
+		{"quiet draw", MatchPlayerStats{TeamID: home.ID, Position: PosCM, Minutes: 90}, 0, 0, 6.0},
+		{"brace in a win", MatchPlayerStats{TeamID: home.ID, Position: PosST, Minutes: 90, Goals: 2, ShotsOnTarget: 3, Touches: 10}, 2, 1, 8.6},
+		{"accurate passer", MatchPlayerStats{TeamID: home.ID, Position: PosCM, Minutes: 90, Passes: 10, PassesCompleted: 9}, 0, 0, 6.3},
+		{"clean sheet", MatchPlayerStats{TeamID: home.ID, Position: PosGK, Minutes: 90}, 1, 0, 7.3},
+		{"clean sheet needs an hour", MatchPlayerStats{TeamID: home.ID, Position: PosGK, Minutes: 30}, 1, 0, 6.3},
+		{"beaten away defence", MatchPlayerStats{TeamID: away.ID, Position: PosCB, Minutes: 90}, 3, 0, 5.1},
+		{"sent off", MatchPlayerStats{TeamID: home.ID, Position: PosCM, Minutes: 40, Fouls: 1, RedCards: 1}, 0, 0, 4.4},
+		{"floor", MatchPlayerStats{TeamID: home.ID, Position: PosCM, RedCards: 4}, 0, 0, 3.0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			match := &Match{HomeTeam: home, AwayTeam: away, HomeScore: tt.homeScore, AwayScore: tt.awayScore}
+			if got := calculateMatchRating(&tt.line, match); got != tt.want {
+				t.Errorf("rating = %.1f, want %.1f", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetMatchPlayerStats(t *testing.T) {
+	const matchID = -9
+	mutex.Lock()
+	match, cleanup := sandboxVARMatch(t, matchID)
+	match.Minute = 70
+	matchPlayerStats[matchID] = map[int]*MatchPlayerStats{
+		-1: {PlayerID: -1, TeamID: 2, Position: PosST, Goals: 1, ShotsOnTarget: 1},
+		-2: {PlayerID: -2, TeamID: 1, Position: PosCM, Passes: 4, PassesCompleted: 4},
+		-3: {PlayerID: -3, TeamID: 1, Position: PosCM, SubbedOffMinute: 55},
+		-4: {PlayerID: -4, TeamID: 1, Position: PosCM, SentOffMinute: 30, RedCards: 1},
+		-5: {PlayerID: -5, TeamID: 2, Position: PosCM, SubbedOnMinute: 55},
+	}
+	refreshMatchPlayerStats(matchID, match)
+	mutex.Unlock()
+	defer func() {
+		mutex.Lock()
+		cleanup()
+		mutex.Unlock()
+	}()
+
+	tests := []struct {
+		query   string
+		players []int
+		minutes []int
+	}{
+		// Home side first, each side by rating
+		{"", []int{-2, -3, -4, -1, -5}, []int{70, 55, 30, 70, 15}},
+		{"?team_id=2", []int{-1, -5}, []int{70, 15}},
+	}
+	for _, tt := range tests {
+		request := mux.SetURLVars(httptest.NewRequest("GET", "/api/v1/matches/-9/players"+tt.query, nil), map[string]string{"id": strconv.Itoa(matchID)})
+		recorder := httptest.NewRecorder()
+		getMatchPlayerStats(recorder, request)
+
+		var body struct {
+			Minute  int                `json:"minute"`
+			Players []MatchPlayerStats `json:"players"`
+		}
+		if err := json.Unmarshal(recorder.Body.Bytes(), &body); err != nil {
+			t.Fatal(err)
+		}
+		var ids, minutes []int
+		for _, line := range body.Players {
+			ids = append(ids, line.PlayerID)
+			minutes = append(minutes, line.Minutes)
+		}
+		if !reflect.DeepEqual(ids, tt.players) || !reflect.DeepEqual(minutes, tt.minutes) {
+			t.Errorf("%q: players %v with minutes %v, want %v with %v", tt.query, ids, minutes, tt.players, tt.minutes)
+		}
+		if body.Minute != 70 {
+			t.Errorf("%q: minute = %d, want 70", tt.query, body.Minute)
+		}
+	}
+
+	mutex.RLock()
+	defer mutex.RUnlock()
+	if line := matchPlayerStats[matchID][-2]; line.PassAccuracy != 100 || line.Rating != 6.5 {
+		t.Errorf("passer accuracy %.1f%% rated %.1f, want 100%% rated 6.5", line.PassAccuracy, line.Rating)
+	}
+}
+
+func TestMatchTracking(t *testing.T) {
+	const matchID = -10
+	mutex.Lock()
+	matches[matchID] = &Match{ID: matchID, HomeTeam: *teams[1], AwayTeam: *teams[2], Status: StatusLive, Minute: 30}
+	matchLineups[matchID] = map[int][]int{1: {-1}, 2: {-2}}
+	matchTracking[matchID] = []*TrackingFrame{
+		{Minute: 5, Ball: PitchPosition{X: 10, Y: 10}, Players: map[int]PitchPosition{-1: {X: 5, Y: 5}, -2: {X: 95, Y: 60}}},
+		{Minute: 25, Ball: PitchPosition{X: 50, Y: 32}, Players: map[int]PitchPosition{-1: {X: 15, Y: 5}, -2: {X: 85, Y: 60}}},
+		// The far corner belongs to the last cell
+		{Minute: 29, Ball: PitchPosition{X: FieldWidth, Y: FieldHeight}, Players: map[int]PitchPosition{-1: {X: 25, Y: 5}, -2: {X: FieldWidth, Y: FieldHeight}}},
+	}
+	mutex.Unlock()
+	defer func() {
+		mutex.Lock()
+		delete(matches, matchID)
+		delete(matchLineups, matchID)
+		delete(matchTracking, matchID)
+		mutex.Unlock()
+	}()
+
+	get := func(t *testing.T, handler http.HandlerFunc, query string, body interface{}) {
+		t.Helper()
+		request := mux.SetURLVars(httptest.NewRequest("GET", "/api/v1/matches/-10/tracking"+query, nil), map[string]string{"id": strconv.Itoa(matchID)})
+		recorder := httptest.NewRecorder()
+		handler(recorder, request)
+		if recorder.Code != http.StatusOK {
+			t.Fatalf("%s: status = %d, want %d", query, recorder.Code, http.StatusOK)
+		}
+		if err := json.Unmarshal(recorder.Body.Bytes(), body); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	t.Run("heatmap", func(t *testing.T) {
+		type cell struct{ row, col, count int }
+		tests := []struct {
+			query   string
+			cells   []cell
+			samples int
+		}{
+			{"?team_id=1", []cell{{0, 0, 1}, {0, 1, 1}, {0, 2, 1}}, 3},
+			{"?player_id=-2", []cell{{7, 8, 1}, {7, 9, 2}}, 3},
+			{"?ball=true", []cell{{1, 1, 1}, {4, 5, 1}, {7, 9, 1}}, 3},
+			{"?player_id=-1&cols=2&rows=1", []cell{{0, 0, 3}}, 3},
+		}
+		for _, tt := range tests {
+			var body struct {
+				Grid    [][]int `json:"grid"`
+				Samples int     `json:"samples"`
+			}
+			get(t, getMatchHeatmap, tt.query, &body)
+			var cells []cell
+			for row, counts := range body.Grid {
+				for col, count := range counts {
+					if count > 0 {
+						cells = append(cells, cell{row, col, count})
+					}
+				}
+			}
+			if !reflect.DeepEqual(cells, tt.cells) || body.Samples != tt.samples {
+				t.Errorf("%s: cells %v from %d samples, want %v from %d", tt.query, cells, body.Samples, tt.cells, tt.samples)
+			}
+		}
+	})
+
+	t.Run("average positions", func(t *testing.T) {
+		var body struct {
+			Positions []AveragePosition `json:"positions"`
+		}
+		get(t, getMatchAveragePositions, "", &body)
+		want := []AveragePosition{
+			{PlayerID: -1, TeamID: 1, X: 15, Y: 5, Samples: 3},
+			{PlayerID: -2, TeamID: 2, X: 93.3, Y: 61.3, Samples: 3},
+		}
+		if !reflect.DeepEqual(body.Positions, want) {
+			t.Errorf("positions = %+v, want %+v", body.Positions, want)
+		}
+	})
+
+	t.Run("trails", func(t *testing.T) {
+		tests := []struct {
+			query   string
+			minutes map[string][]int
+			ball    int
+		}{
+			// The default window is the last DefaultTrailMinutes of play
+			{"", map[string][]int{"-1": {25, 29}, "-2": {25, 29}}, 2},
+			{"?from=0&to=10&player_id=-2", map[string][]int{"-2": {5}}, 1},
+			{"?from=0&team_id=1", map[string][]int{"-1": {5, 25, 29}}, 3},
+		}
+		for _, tt := range tests {
+			var body struct {
+				Players map[string][]struct {
+					Minute int `json:"minute"`
+				} `json:"players"`
+				Ball []interface{} `json:"ball"`
+			}
+			get(t, getMatchTrails, tt.query, &body)
+			minutes := make(map[string][]int)
+			for player, trail := range body.Players {
+				for _, point := range trail {
+					minutes[player] = append(minutes[player], point.Minute)
+				}
+			}
+			if !reflect.DeepEqual(minutes, tt.minutes) || len(body.Ball) != tt.ball {
+				t.Errorf("%q: trails %v with %d ball points, want %v with %d", tt.query, minutes, len(body.Ball), tt.minutes, tt.ball)
+			}
+		}
+	})
+}
+
+func TestManageTeam(t *testing.T) {
+	const matchID = -11
+	tests := []struct {
+		name      string
+		minute    int
+		goalDiff  int
+		sentOff   int
+		formation string
+		offensive string
+		defensive string
+		reason    string
+	}{
+		{"narrow lead late on", 80, 1, 0, Formation532, TacticCounterAttack, TacticLowBlock, "Protecting the lead"},
+		{"comfortable lead late on", 80, 2, 0, Formation442, TacticCounterAttack, TacticLowBlock, "Protecting the lead"},
+		{"one down with time left", 65, -1, 0, Formation4231, TacticPressing, TacticOffside, "Chasing the game"},
+		{"two down", 80, -2, 0, Formation433, TacticPressing, TacticOffside, "Chasing the game"},
+		{"red card before the first change", 10, 1, 1, Formation532, TacticCounterAttack, TacticLowBlock, "Down to 10 men and shoring up the lead"},
+		{"too early to react", ManagerFirstChangeMinute - 1, -1, 0, "", "", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mutex.Lock()
+			defer mutex.Unlock()
+			match := &Match{ID: matchID, HomeTeam: *teams[1], AwayTeam: *teams[2], Minute: tt.minute, HomeFormation: Formation442}
+			matchLineups[matchID] = make(map[int][]int)
+			defer func() {
+				delete(matchLineups, matchID)
+				delete(tacticsHistory, matchID)
+				delete(liveCommentary, matchID)
+			}()
+			tactics := &MatchTactics{HomeOffensive: TacticTikiTaka, HomeDefensive: TacticZonalMarking}
+
+			// Managers only act on some ticks, so give them plenty
+			for i := 0; i < 200; i++ {
+				manageTeam(match, tactics, &MatchMomentum{}, true, tt.goalDiff, 0, tt.sentOff, 0)
+			}
+
+			history := tacticsHistory[matchID]
+			if tt.reason == "" {
+				if len(history) != 0 {
+					t.Fatalf("manager made %d changes, want none", len(history))
+				}
+				return
+			}
+			if len(history) != 1 {
+				t.Fatalf("manager made %d changes, want 1", len(history))
+			}
+			change := history[0]
+			if match.HomeFormation != tt.formation || tactics.HomeOffensive != tt.offensive || tactics.HomeDefensive != tt.defensive {
+				t.Errorf("setup = %s %s/%s, want %s %s/%s", match.HomeFormation, tactics.HomeOffensive, tactics.HomeDefensive,
+					tt.formation, tt.offensive, tt.defensive)
+			}
+			if change.Reason != tt.reason || change.PreviousFormation != Formation442 || change.PreviousOffensive != TacticTikiTaka {
+				t.Errorf("change = %q from %s %s, want %q from %s %s", change.Reason, change.PreviousFormation, change.PreviousOffensive,
+					tt.reason, Formation442, TacticTikiTaka)
+			}
+		})
+	}
+}
+
+func TestCalculateAttendance(t *testing.T) {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	tests := []struct {
+		name      string
+		league    string
+		form      int
+		derby     string
+		low, high float64
+	}{
+		{"lower league without form", LeagueCommunityLeague, 0, "", 0.5, 0.6},
+		{"top flight in form", LeaguePremier, 15, "", 0.75, 0.85},
+		// Derbies sell out however the season is going
+		{"derby", LeaguePremier, 15, "Galactic Derby", 0.95, 1.0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Teams outside the tables have no standing to add
+			home := &TeamInfo{ID: -1, League: tt.league, Capacity: 40000, FormPoints: tt.form}
+			away := &TeamInfo{ID: -2, League: tt.league}
+			for i := 0; i < 100; i++ {
+				attendance, occupancy := calculateAttendance(home, away, tt.derby)
+				if occupancy < tt.low || occupancy > tt.high {
+					t.Fatalf("occupancy = %.3f, want %.2f to %.2f", occupancy, tt.low, tt.high)
+				}
+				if attendance != int(float64(home.Capacity)*occupancy) {
+					t.Fatalf("attendance = %d, want %.3f of %d", attendance, occupancy, home.Capacity)
+				}
+			}
+		})
+	}
+}
+
+func TestCrowdFactor(t *testing.T) {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	largest := 0
+	for _, team := range teams {
+		largest = max(largest, team.Capacity)
+	}
+	tests := []struct {
+		name      string
+		capacity  int
+		occupancy float64
+		derby     string
+		want      float64
+	}{
+		{"full largest stadium", largest, 1, "", 1},
+		{"half full largest stadium", largest, 0.5, "", 0.5},
+		{"derby", largest, 0.5, "Galactic Derby", 0.6},
+		{"derby capped", largest, 1, "Galactic Derby", 1},
+		{"no stands", 0, 0.8, "", 0.4},
+	}
+	for _, tt := range tests {
+		if got := crowdFactor(tt.capacity, tt.occupancy, tt.derby); got != tt.want {
+			t.Errorf("%s: crowdFactor = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	if got := homeAdvantage(0); got != BaseHomeAdvantage {
+		t.Errorf("empty stadium home advantage = %v, want %v", got, BaseHomeAdvantage)
+	}
+	if got := homeAdvantage(1); math.Abs(got-(BaseHomeAdvantage+CrowdHomeAdvantage)) > 1e-9 {
+		t.Errorf("full crowd home advantage = %v, want %v", got, BaseHomeAdvantage+CrowdHomeAdvantage)
+	}
+}
+
+func TestCrowdCardBias(t *testing.T) {
+	mutex.RLock()
+	match := &Match{HomeTeam: *teams[1], AwayTeam: *teams[2], CrowdFactor: 1}
+	mutex.RUnlock()
+
+	if got := crowdCardBias(match, 1); math.Abs(got-(1-CrowdCardBias)) > 1e-9 {
+		t.Errorf("home card bias = %v, want %v", got, 1-CrowdCardBias)
+	}
+	if got := crowdCardBias(match, 2); math.Abs(got-(1+CrowdCardBias)) > 1e-9 {
+		t.Errorf("away card bias = %v, want %v", got, 1+CrowdCardBias)
+	}
+	match.CrowdFactor = 0
+	if got := crowdCardBias(match, 1); got != 1 {
+		t.Errorf("behind closed doors card bias = %v, want 1", got)
+	}
+}
+
+func TestRecordHomeAttendance(t *testing.T) {
+	team := &TeamInfo{Capacity: 40000}
+	for _, attendance := range []int{30000, 35000, 34000} {
+		recordHomeAttendance(team, attendance)
+	}
+	if team.AverageAttendance != 33000 || team.AverageOccupancy != 82.5 {
+		t.Errorf("average = %d at %.1f%%, want 33000 at 82.5%%", team.AverageAttendance, team.AverageOccupancy)
+	}
+}